@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"vitals/internal/app"
+)
+
+// runExport implements the export subcommand, which writes a user's weight
+// and water events as CSV to stdout or a file.
+func runExport(args []string) (err error) {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	username := fs.String("user", "", "username whose events to export (required)")
+	from := fs.String("from", "", "first local day (YYYY-MM-DD) or RFC 3339 timestamp to export")
+	to := fs.String("to", "", "last local day (YYYY-MM-DD) or RFC 3339 timestamp to export")
+	unit := fs.String("unit", "", "convert weights to kg or lb instead of keeping the recorded unit")
+	out := fs.String("o", "-", "output file, or - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return errors.New("-user is required")
+	}
+	opts := app.ExportOptions{From: *from, To: *to, Unit: *unit}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	repos, closeRepos := openRepositories()
+	defer closeRepos()
+
+	ctx := context.Background()
+	user, err := repos.user.GetByUsername(ctx, *username)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user %q not found", *username)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = f
+	}
+	return app.NewExportService(repos.weight, repos.water).WriteCSV(ctx, user.ID, opts, w, user.Location())
+}
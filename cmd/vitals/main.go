@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 
 	adapthttp "vitals/internal/adapter/http"
 	"vitals/internal/adapter/memory"
@@ -15,69 +16,133 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(os.Args[2:]); err != nil {
+			log.Fatalf("export: %v", err)
+		}
+		return
+	}
+
 	addr := env("ADDR", ":8080")
 	webDir := env("WEB_DIR", "web")
+	outlierPercent, err := strconv.ParseFloat(env("WEIGHT_OUTLIER_PERCENT", strconv.Itoa(app.DefaultOutlierPercent)), 64)
+	if err != nil {
+		log.Fatalf("WEIGHT_OUTLIER_PERCENT: %v", err)
+	}
+	healthImportMB, err := strconv.ParseInt(env("HEALTH_IMPORT_MAX_MB", strconv.Itoa(adapthttp.DefaultHealthImportBytes>>20)), 10, 64)
+	if err != nil || healthImportMB <= 0 {
+		log.Fatalf("HEALTH_IMPORT_MAX_MB: must be a positive number of megabytes")
+	}
 
-	var (
-		weightRepo       domain.WeightRepository
-		waterRepo        domain.WaterRepository
-		chartsWeightRepo domain.WeightRepository
-		chartsWaterRepo  domain.WaterRepository
-		userRepo         domain.UserRepository
-		sessionRepo      domain.SessionRepository
-	)
+	repos, closeRepos := openRepositories()
+	defer closeRepos()
 
-	useMemory := os.Getenv("POSTGRES_URL") == ""
+	weightSvc := app.NewWeightService(repos.weight).WithOutlierThreshold(outlierPercent)
+	waterSvc := app.NewWaterService(repos.water)
+	bpSvc := app.NewBloodPressureService(repos.bp)
+	sleepSvc := app.NewSleepService(repos.sleep)
+	measurementSvc := app.NewMeasurementService(repos.measurement)
+	goalSvc := app.NewGoalService(repos.goal, repos.weight)
+	chartsSvc := app.NewChartsService(repos.chartsWeight, repos.chartsWater).
+		WithBloodPressure(repos.bp).
+		WithSleep(repos.sleep).
+		WithMeasurements(repos.measurement).
+		WithWeightGoal(repos.goal)
+	statsSvc := app.NewStatsService(repos.chartsWeight, repos.chartsWater)
+	analyticsSvc := app.NewAnalyticsService(repos.chartsWeight, repos.chartsWater)
+	exportSvc := app.NewExportService(repos.weight, repos.water)
+	importSvc := app.NewImportService(repos.imports, repos.weight, repos.water).WithOutlierThreshold(outlierPercent)
+	accountSvc := app.NewAccountService(repos.weight, repos.water, repos.imports)
+	authSvc := app.NewAuthService(repos.user, repos.session)
+	profileSvc := app.NewProfileService(repos.user)
 
-	// DB configuration
-	if useMemory {
-		log.Println("Using in-memory database")
-		mem := memory.New()
-		weightRepo = mem
-		waterRepo = mem
-		chartsWeightRepo = mem
-		chartsWaterRepo = mem
-		userRepo = mem
-		sessionRepo = mem.NewSessionRepo()
-	} else {
-		log.Println("Using PostgreSQL database")
-		connStr := os.Getenv("POSTGRES_URL")
+	srv := adapthttp.New(weightSvc, waterSvc, chartsSvc, authSvc, webDir).
+		WithProfile(profileSvc).
+		WithBloodPressure(bpSvc).
+		WithSleep(sleepSvc).
+		WithMeasurements(measurementSvc).
+		WithGoals(goalSvc).
+		WithStats(statsSvc).
+		WithAnalytics(analyticsSvc).
+		WithExport(exportSvc).
+		WithImport(importSvc).
+		WithAccount(accountSvc).
+		WithHealthImportLimit(healthImportMB << 20)
+	h := srv.Handler()
 
-		// Map custom env vars to lib/pq standard vars if provided
-		if v := os.Getenv("POSTGRES_USER"); v != "" {
-			_ = os.Setenv("PGUSER", v)
-		}
-		if v := os.Getenv("POSTGRES_PASSWORD"); v != "" {
-			_ = os.Setenv("PGPASSWORD", v)
-		}
+	log.Printf("listening on %s", addr)
+	//nolint:gosec // ignoring timeout constraint for simple server
+	if err := http.ListenAndServe(addr, h); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
 
-		db, err := postgres.Open(connStr)
-		if err != nil {
-			log.Fatalf("db open: %v", err)
-		}
-		defer func() { _ = db.Close() }()
+// repositories holds the adapters backing the application services.
+type repositories struct {
+	weight       domain.WeightRepository
+	water        domain.WaterRepository
+	chartsWeight domain.WeightRepository
+	chartsWater  domain.WaterRepository
+	bp           domain.BloodPressureRepository
+	sleep        domain.SleepRepository
+	measurement  domain.MeasurementRepository
+	goal         domain.WeightGoalRepository
+	imports      domain.ImportRepository
+	user         domain.UserRepository
+	session      domain.SessionRepository
+}
 
-		weightRepo = db
-		waterRepo = db
-		chartsWeightRepo = db
-		chartsWaterRepo = db
-		userRepo = db
-		sessionRepo = postgres.NewSessionRepo(db)
+// openRepositories connects to PostgreSQL if POSTGRES_URL is set and falls
+// back to an in-memory database otherwise. The returned function closes the
+// connection.
+func openRepositories() (*repositories, func()) {
+	// DB configuration
+	if os.Getenv("POSTGRES_URL") == "" {
+		log.Println("Using in-memory database")
+		mem := memory.New()
+		return &repositories{
+			weight:       mem,
+			water:        mem,
+			chartsWeight: mem,
+			chartsWater:  mem,
+			bp:           mem,
+			sleep:        mem,
+			measurement:  mem,
+			goal:         mem,
+			imports:      mem,
+			user:         mem,
+			session:      mem.NewSessionRepo(),
+		}, func() {}
 	}
 
-	weightSvc := app.NewWeightService(weightRepo)
-	waterSvc := app.NewWaterService(waterRepo)
-	chartsSvc := app.NewChartsService(chartsWeightRepo, chartsWaterRepo)
-	authSvc := app.NewAuthService(userRepo, sessionRepo)
+	log.Println("Using PostgreSQL database")
+	connStr := os.Getenv("POSTGRES_URL")
 
-	srv := adapthttp.New(weightSvc, waterSvc, chartsSvc, authSvc, webDir)
-	h := srv.Handler()
+	// Map custom env vars to lib/pq standard vars if provided
+	if v := os.Getenv("POSTGRES_USER"); v != "" {
+		_ = os.Setenv("PGUSER", v)
+	}
+	if v := os.Getenv("POSTGRES_PASSWORD"); v != "" {
+		_ = os.Setenv("PGPASSWORD", v)
+	}
 
-	log.Printf("listening on %s", addr)
-	//nolint:gosec // ignoring timeout constraint for simple server
-	if err := http.ListenAndServe(addr, h); err != nil && !errors.Is(err, http.ErrServerClosed) {
-		log.Fatal(err)
+	db, err := postgres.Open(connStr)
+	if err != nil {
+		log.Fatalf("db open: %v", err)
 	}
+	return &repositories{
+		weight:       db,
+		water:        db,
+		chartsWeight: db,
+		chartsWater:  db,
+		bp:           db,
+		sleep:        db,
+		measurement:  db,
+		goal:         db,
+		imports:      db,
+		user:         db,
+		session:      postgres.NewSessionRepo(db),
+	}, func() { _ = db.Close() }
 }
 
 func env(key, fallback string) string {
@@ -0,0 +1,51 @@
+package adapthttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"vitals/internal/app"
+)
+
+// maxTakeoutBytes caps the size of an uploaded account archive.
+const maxTakeoutBytes = 64 << 20
+
+func (s *Server) handleAccountExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := userFromContext(r)
+	t, err := s.account.Export(r.Context(), user)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	filename := fmt.Sprintf("vitals-takeout-%s.json", localDayString(t.ExportedAt, user.Location()))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) handleAccountImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := userFromContext(r)
+	// Unknown fields are tolerated so that an archive from a newer server is
+	// rejected for its version rather than for its first new field.
+	var t app.Takeout
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTakeoutBytes)).Decode(&t); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+		return
+	}
+	res, err := s.account.Restore(r.Context(), user.ID, &t, r.URL.Query().Get("mode"))
+	if err != nil {
+		writeImportError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
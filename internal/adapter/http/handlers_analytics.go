@@ -0,0 +1,55 @@
+package adapthttp
+
+import (
+	"net/http"
+
+	"vitals/internal/app"
+)
+
+func (s *Server) handleWaterHeatmap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := userFromContext(r)
+	q := r.URL.Query()
+	heatmap, err := s.analytics.GetWaterHeatmap(r.Context(), user.ID, app.HeatmapQuery{
+		Days: intQuery(r, "days", 90),
+		From: q.Get("from"),
+		To:   q.Get("to"),
+	}, user.Location())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, heatmap)
+}
+
+func (s *Server) handleWaterWeightCorrelation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := userFromContext(r)
+	q := r.URL.Query()
+	unit := q.Get("unit")
+	if unit == "" {
+		unit = "lb"
+	}
+
+	corr, err := s.analytics.GetWaterWeightCorrelation(r.Context(), user.ID, app.CorrelationQuery{
+		Days:         intQuery(r, "days", 90),
+		From:         q.Get("from"),
+		To:           q.Get("to"),
+		Lag:          intQuery(r, "lag", 0),
+		Unit:         unit,
+		WeightPolicy: weightPolicy(r, user),
+	}, user.Location())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, corr)
+}
@@ -0,0 +1,149 @@
+package adapthttp
+
+import (
+	"net/http"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+func (s *Server) handleBloodPressureToday(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := userFromContext(r)
+	loc := user.Location()
+	today := localDayString(time.Now(), loc)
+
+	switch r.Method {
+	case http.MethodGet:
+		readings, err := s.bp.GetToday(ctx, user.ID, today, loc)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		var latest *domain.BloodPressureReading
+		if len(readings) > 0 {
+			latest = &readings[0]
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"today": today, "entry": latest, "readings": readings})
+
+	case http.MethodPut:
+		var body struct {
+			Systolic  int        `json:"systolic"`
+			Diastolic int        `json:"diastolic"`
+			Pulse     *int       `json:"pulse"`
+			Arm       string     `json:"arm"`
+			Position  string     `json:"position"`
+			At        *time.Time `json:"at"`
+			Day       string     `json:"day"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		reading := domain.BloodPressureReading{
+			Systolic:  body.Systolic,
+			Diastolic: body.Diastolic,
+			Pulse:     body.Pulse,
+			Arm:       body.Arm,
+			Position:  body.Position,
+		}
+		at := app.EntryTime{At: body.At, Day: body.Day}
+		entry, err := s.bp.RecordReading(ctx, user.ID, reading, at, loc)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"today": today, "day": entry.Day, "entry": entry})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleBloodPressureRecent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user := userFromContext(r)
+	items, next, err := s.bp.ListRecent(r.Context(), user.ID, listOptions(r, 14), user.Location())
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pageJSON(items, next))
+}
+
+func (s *Server) handleBloodPressureUndoLast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user := userFromContext(r)
+	deleted, id, err := s.bp.UndoLast(r.Context(), user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "deleted": deleted, "id": id})
+}
+
+func (s *Server) handleBloodPressureEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := userFromContext(r)
+	loc := user.Location()
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		entry, err := s.bp.GetReading(ctx, user.ID, id, loc)
+		if err != nil {
+			writeEntryError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"entry": entry})
+
+	case http.MethodPatch:
+		var body struct {
+			Systolic  *int       `json:"systolic"`
+			Diastolic *int       `json:"diastolic"`
+			Pulse     *int       `json:"pulse"`
+			Arm       *string    `json:"arm"`
+			Position  *string    `json:"position"`
+			At        *time.Time `json:"at"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		patch := app.BloodPressurePatch{
+			Systolic:  body.Systolic,
+			Diastolic: body.Diastolic,
+			Pulse:     body.Pulse,
+			Arm:       body.Arm,
+			Position:  body.Position,
+			At:        body.At,
+		}
+		entry, err := s.bp.UpdateReading(ctx, user.ID, id, patch, loc)
+		if err != nil {
+			writeEntryError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"entry": entry})
+
+	case http.MethodDelete:
+		if err := s.bp.DeleteReading(ctx, user.ID, id); err != nil {
+			writeEntryError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "deleted": true, "id": id})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -3,8 +3,43 @@ package adapthttp
 import (
 	"net/http"
 	"time"
+
+	"vitals/internal/app"
 )
 
+// trendOptions reads the trend, alpha and window query parameters. The trend
+// defaults to an exponential moving average; "none" disables it.
+func trendOptions(r *http.Request) (app.TrendOptions, error) {
+	method := r.URL.Query().Get("trend")
+	switch method {
+	case "":
+		method = app.TrendEMA
+	case "none":
+		return app.TrendOptions{}, nil
+	}
+	alpha, err := floatQuery(r, "alpha", app.DefaultTrendAlpha)
+	if err != nil {
+		return app.TrendOptions{}, err
+	}
+	return app.TrendOptions{
+		Method: method,
+		Alpha:  alpha,
+		Window: intQuery(r, "window", app.DefaultTrendWindow),
+	}, nil
+}
+
+// trendJSON describes the trend settings used for a chart response.
+func trendJSON(o app.TrendOptions) map[string]any {
+	switch o.Method {
+	case app.TrendEMA:
+		return map[string]any{"method": o.Method, "alpha": o.Alpha}
+	case app.TrendSMA:
+		return map[string]any{"method": o.Method, "window": o.Window}
+	default:
+		return nil
+	}
+}
+
 func (s *Server) handleChartsDaily(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -18,7 +53,117 @@ func (s *Server) handleChartsDaily(w http.ResponseWriter, r *http.Request) {
 		unit = "lb"
 	}
 
-	points, err := s.charts.GetDaily(r.Context(), user.ID, days, unit)
+	trend, err := trendOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	loc := user.Location()
+	goal := user.WaterGoal()
+	query := app.ChartQuery{
+		Days:         days,
+		From:         r.URL.Query().Get("from"),
+		To:           r.URL.Query().Get("to"),
+		Unit:         unit,
+		WaterGoal:    goal,
+		WeightPolicy: weightPolicy(r, user),
+		Trend:        trend,
+		Bucket:       r.URL.Query().Get("bucket"),
+		WeightAgg:    r.URL.Query().Get("weightAgg"),
+		WaterAgg:     r.URL.Query().Get("waterAgg"),
+	}
+	if query.Bucket != "" && query.Bucket != app.BucketDay {
+		s.writeChartBuckets(w, r, query, loc)
+		return
+	}
+
+	points, err := s.charts.GetDaily(r.Context(), user.ID, query, loc)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var from, to string
+	if len(points) > 0 {
+		from, to = points[0].Day, points[len(points)-1].Day
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"days":            len(points),
+		"from":            from,
+		"to":              to,
+		"unit":            unit,
+		"waterGoalLiters": goal,
+		"trend":           trendJSON(trend),
+		"today":           localDayString(time.Now(), loc),
+		"items":           points,
+	})
+}
+
+// writeChartBuckets responds to a daily chart request grouped into weeks,
+// months or years.
+func (s *Server) writeChartBuckets(w http.ResponseWriter, r *http.Request, query app.ChartQuery, loc *time.Location) {
+	user := userFromContext(r)
+	points, err := s.charts.GetBuckets(r.Context(), user.ID, query, loc)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var from, to string
+	if len(points) > 0 {
+		from, to = points[0].Start, points[len(points)-1].End
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"from":   from,
+		"to":     to,
+		"unit":   query.Unit,
+		"bucket": query.Bucket,
+		"today":  localDayString(time.Now(), loc),
+		"items":  points,
+	})
+}
+
+func (s *Server) handleChartsCalendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := userFromContext(r)
+	loc := user.Location()
+	year := intQuery(r, "year", time.Now().In(loc).Year())
+	goal := user.WaterGoal()
+
+	cells, err := s.charts.GetCalendar(r.Context(), user.ID, year, goal, loc)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"year":            year,
+		"waterGoalLiters": goal,
+		"maxWaterLevel":   app.MaxWaterLevel,
+		"today":           localDayString(time.Now(), loc),
+		"items":           cells,
+	})
+}
+
+func (s *Server) handleChartsMeasurements(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := userFromContext(r)
+	days := intQuery(r, "days", 90)
+	unit := r.URL.Query().Get("unit")
+	if unit == "" {
+		unit = "in"
+	}
+
+	loc := user.Location()
+	points, err := s.charts.GetMeasurements(r.Context(), user.ID, days, unit, loc)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err)
 		return
@@ -27,7 +172,7 @@ func (s *Server) handleChartsDaily(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{
 		"days":  days,
 		"unit":  unit,
-		"today": localDayString(time.Now()),
+		"today": localDayString(time.Now(), loc),
 		"items": points,
 	})
 }
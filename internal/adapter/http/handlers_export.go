@@ -0,0 +1,30 @@
+package adapthttp
+
+import (
+	"log"
+	"net/http"
+
+	"vitals/internal/app"
+)
+
+func (s *Server) handleExportCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := userFromContext(r)
+	q := r.URL.Query()
+	opts := app.ExportOptions{From: q.Get("from"), To: q.Get("to"), Unit: q.Get("unit")}
+	if err := opts.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="vitals-export.csv"`)
+	if err := s.export.WriteCSV(r.Context(), user.ID, opts, w, user.Location()); err != nil {
+		// The status line has already been sent, so the export is truncated.
+		log.Printf("export user %d: %v", user.ID, err)
+	}
+}
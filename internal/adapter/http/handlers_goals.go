@@ -0,0 +1,79 @@
+package adapthttp
+
+import (
+	"errors"
+	"net/http"
+
+	"vitals/internal/app"
+)
+
+func (s *Server) handleGoals(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user := userFromContext(r)
+	weight, err := s.goals.GetWeightGoalProgress(r.Context(), user.ID, r.URL.Query().Get("unit"), user.Location())
+	if err != nil && !errors.Is(err, app.ErrNoGoal) {
+		writeGoalError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"weight": weight})
+}
+
+func (s *Server) handleWeightGoal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := userFromContext(r)
+	loc := user.Location()
+
+	switch r.Method {
+	case http.MethodGet:
+		progress, err := s.goals.GetWeightGoalProgress(ctx, user.ID, r.URL.Query().Get("unit"), loc)
+		if err != nil {
+			writeGoalError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, progress)
+
+	case http.MethodPut:
+		var body struct {
+			TargetValue float64 `json:"targetValue"`
+			Unit        string  `json:"unit"`
+			TargetDate  string  `json:"targetDate"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if _, err := s.goals.SetWeightGoal(ctx, user.ID, body.TargetValue, body.Unit, body.TargetDate, loc); err != nil {
+			writeGoalError(w, err)
+			return
+		}
+		progress, err := s.goals.GetWeightGoalProgress(ctx, user.ID, body.Unit, loc)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, progress)
+
+	case http.MethodDelete:
+		if err := s.goals.DeleteWeightGoal(ctx, user.ID); err != nil {
+			writeEntryError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "deleted": true})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// writeGoalError maps an invalid goal or unit to 400, app.ErrNoGoal to 404 and
+// anything else to 500.
+func writeGoalError(w http.ResponseWriter, err error) {
+	if errors.Is(err, app.ErrInvalidGoal) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeEntryError(w, http.StatusInternalServerError, err)
+}
@@ -0,0 +1,163 @@
+package adapthttp
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"vitals/internal/app"
+)
+
+// Size limits of uploaded import files. Apple Health exports are streamed and
+// may be larger than spreadsheets, up to the server's health import limit;
+// Google exports are decoded in memory.
+const (
+	maxImportBytes       = 20 << 20
+	maxGoogleImportBytes = 64 << 20
+)
+
+// DefaultHealthImportBytes is the largest Apple Health export accepted unless
+// the server is configured with WithHealthImportLimit.
+const DefaultHealthImportBytes = 64 << 20
+
+// importFile returns the uploaded file, of at most limit bytes: the "file"
+// part of a multipart form, or else the request body itself. Either is read
+// as a stream.
+func importFile(w http.ResponseWriter, r *http.Request, limit int64) (io.Reader, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			return nil, errors.New("multipart upload must have a \"file\" part")
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+	}
+}
+
+func (s *Server) handleImportCSV(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := userFromContext(r)
+	q := r.URL.Query()
+	opts := app.CSVImportOptions{
+		Mapping: app.CSVMapping{
+			Date:  q.Get("date"),
+			Time:  q.Get("time"),
+			Value: q.Get("value"),
+			Unit:  q.Get("unit"),
+			Water: q.Get("water"),
+		},
+		DefaultUnit: q.Get("defaultUnit"),
+	}
+	var err error
+	for _, flag := range []struct {
+		key string
+		dst *bool
+	}{
+		{"dayFirst", &opts.DayFirst},
+		{"dryRun", &opts.DryRun},
+		{"skipInvalid", &opts.SkipInvalid},
+		{"confirm", &opts.Confirm},
+	} {
+		if *flag.dst, err = boolQuery(r, flag.key); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	f, err := importFile(w, r, maxImportBytes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := s.imports.ImportCSV(r.Context(), user.ID, f, opts, user.Location())
+	if err != nil {
+		writeImportError(w, err)
+		return
+	}
+	writeImportResult(w, res)
+}
+
+func (s *Server) handleImportAppleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := userFromContext(r)
+	dryRun, err := boolQuery(r, "dryRun")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	f, err := importFile(w, r, s.healthImportBytes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := s.imports.ImportAppleHealth(r.Context(), user.ID, f, app.HealthImportOptions{DryRun: dryRun})
+	if err != nil {
+		writeImportError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleImportGoogleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := userFromContext(r)
+	dryRun, err := boolQuery(r, "dryRun")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	f, err := importFile(w, r, maxGoogleImportBytes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := s.imports.ImportGoogleHealth(r.Context(), user.ID, f, app.HealthImportOptions{DryRun: dryRun})
+	if err != nil {
+		writeImportError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// writeImportError maps errors for an invalid upload to 400 and failures to
+// read or store the user's data to 500.
+func writeImportError(w http.ResponseWriter, err error) {
+	if errors.Is(err, app.ErrInvalidImport) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+// writeImportResult responds with res, using 422 if rows were rejected and
+// nothing was stored as a result.
+func writeImportResult(w http.ResponseWriter, res *app.ImportResult) {
+	status := http.StatusOK
+	if !res.DryRun && !res.Committed && res.Invalid > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, status, res)
+}
@@ -0,0 +1,127 @@
+package adapthttp
+
+import (
+	"net/http"
+	"time"
+
+	"vitals/internal/app"
+)
+
+func (s *Server) handleMeasurementsToday(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := userFromContext(r)
+	loc := user.Location()
+	today := localDayString(time.Now(), loc)
+
+	switch r.Method {
+	case http.MethodGet:
+		items, err := s.measurements.GetToday(ctx, user.ID, today, loc)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"today": today, "items": items})
+
+	case http.MethodPut:
+		var body struct {
+			Site  string     `json:"site"`
+			Value float64    `json:"value"`
+			Unit  string     `json:"unit"`
+			At    *time.Time `json:"at"`
+			Day   string     `json:"day"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		at := app.EntryTime{At: body.At, Day: body.Day}
+		entry, err := s.measurements.RecordMeasurement(ctx, user.ID, body.Site, body.Value, body.Unit, at, loc)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"today": today, "day": entry.Day, "entry": entry})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleMeasurementsRecent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user := userFromContext(r)
+	site := r.URL.Query().Get("site")
+	items, next, err := s.measurements.ListRecent(r.Context(), user.ID, site, listOptions(r, 14), user.Location())
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pageJSON(items, next))
+}
+
+func (s *Server) handleMeasurementsUndoLast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user := userFromContext(r)
+	deleted, id, err := s.measurements.UndoLast(r.Context(), user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "deleted": deleted, "id": id})
+}
+
+func (s *Server) handleMeasurementEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := userFromContext(r)
+	loc := user.Location()
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		entry, err := s.measurements.GetMeasurement(ctx, user.ID, id, loc)
+		if err != nil {
+			writeEntryError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"entry": entry})
+
+	case http.MethodPatch:
+		var body struct {
+			Site  *string    `json:"site"`
+			Value *float64   `json:"value"`
+			Unit  *string    `json:"unit"`
+			At    *time.Time `json:"at"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		patch := app.MeasurementPatch{Site: body.Site, Value: body.Value, Unit: body.Unit, At: body.At}
+		entry, err := s.measurements.UpdateMeasurement(ctx, user.ID, id, patch, loc)
+		if err != nil {
+			writeEntryError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"entry": entry})
+
+	case http.MethodDelete:
+		if err := s.measurements.DeleteMeasurement(ctx, user.ID, id); err != nil {
+			writeEntryError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "deleted": true, "id": id})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,53 @@
+package adapthttp
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+func (s *Server) handleProfile(w http.ResponseWriter, r *http.Request) {
+	user := userFromContext(r)
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, profileJSON(user))
+
+	case http.MethodPut:
+		var body domain.ProfileUpdate
+		if err := parseJSON(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		updated, err := s.profile.UpdateProfile(r.Context(), user.ID, body)
+		switch {
+		case errors.Is(err, app.ErrInvalidProfile):
+			writeError(w, http.StatusBadRequest, err)
+			return
+		case errors.Is(err, app.ErrUserNotFound):
+			writeError(w, http.StatusNotFound, err)
+			return
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, profileJSON(updated))
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// profileJSON renders the user's preferences.
+func profileJSON(user *domain.User) map[string]any {
+	return map[string]any{
+		"username":        user.Username,
+		"timezone":        user.Timezone,
+		"waterGoalLiters": user.WaterGoal(),
+		"weightPolicy":    user.WeightPolicy(),
+		"today":           localDayString(time.Now(), user.Location()),
+	}
+}
@@ -0,0 +1,142 @@
+package adapthttp
+
+import (
+	"net/http"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+func (s *Server) handleSleepToday(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user := userFromContext(r)
+	loc := user.Location()
+	today := localDayString(time.Now(), loc)
+	sessions, err := s.sleep.GetToday(r.Context(), user.ID, today, loc)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	var total float64
+	for _, session := range sessions {
+		total += session.DurationMinutes
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"today": today, "sessions": sessions, "totalMinutes": total})
+}
+
+func (s *Server) handleSleepSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user := userFromContext(r)
+	var body struct {
+		Bedtime       time.Time `json:"bedtime"`
+		WakeTime      time.Time `json:"wakeTime"`
+		Quality       *int      `json:"quality"`
+		Interruptions int       `json:"interruptions"`
+	}
+	if err := parseJSON(r, &body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	session := domain.SleepSession{
+		Bedtime:       body.Bedtime,
+		WakeTime:      body.WakeTime,
+		Quality:       body.Quality,
+		Interruptions: body.Interruptions,
+	}
+	entry, err := s.sleep.RecordSession(r.Context(), user.ID, session, user.Location())
+	if err != nil {
+		writeEntryError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"day": entry.Day, "entry": entry})
+}
+
+func (s *Server) handleSleepRecent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user := userFromContext(r)
+	items, next, err := s.sleep.ListRecent(r.Context(), user.ID, listOptions(r, 14), user.Location())
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pageJSON(items, next))
+}
+
+func (s *Server) handleSleepUndoLast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user := userFromContext(r)
+	deleted, id, err := s.sleep.UndoLast(r.Context(), user.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "deleted": deleted, "id": id})
+}
+
+func (s *Server) handleSleepEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := userFromContext(r)
+	loc := user.Location()
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		entry, err := s.sleep.GetSession(ctx, user.ID, id, loc)
+		if err != nil {
+			writeEntryError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"entry": entry})
+
+	case http.MethodPatch:
+		var body struct {
+			Bedtime       *time.Time `json:"bedtime"`
+			WakeTime      *time.Time `json:"wakeTime"`
+			Quality       *int       `json:"quality"`
+			Interruptions *int       `json:"interruptions"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		patch := app.SleepPatch{
+			Bedtime:       body.Bedtime,
+			WakeTime:      body.WakeTime,
+			Quality:       body.Quality,
+			Interruptions: body.Interruptions,
+		}
+		entry, err := s.sleep.UpdateSession(ctx, user.ID, id, patch, loc)
+		if err != nil {
+			writeEntryError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"entry": entry})
+
+	case http.MethodDelete:
+		if err := s.sleep.DeleteSession(ctx, user.ID, id); err != nil {
+			writeEntryError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "deleted": true, "id": id})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
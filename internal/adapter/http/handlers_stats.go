@@ -0,0 +1,34 @@
+package adapthttp
+
+import (
+	"net/http"
+
+	"vitals/internal/app"
+)
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user := userFromContext(r)
+	q := r.URL.Query()
+	unit := q.Get("unit")
+	if unit == "" {
+		unit = "lb"
+	}
+
+	summary, err := s.stats.GetSummary(r.Context(), user.ID, app.StatsQuery{
+		Days:         intQuery(r, "days", 30),
+		From:         q.Get("from"),
+		To:           q.Get("to"),
+		Unit:         unit,
+		WeightPolicy: weightPolicy(r, user),
+	}, user.Location())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
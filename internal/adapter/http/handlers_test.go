@@ -4,14 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	adapthttp "vitals/internal/adapter/http"
+	"vitals/internal/adapter/memory"
 	"vitals/internal/app"
 	"vitals/internal/domain"
 )
@@ -21,15 +26,23 @@ import (
 // ---------------------------------------------------------------------------
 
 type mockWeightRepo struct {
-	addFn    func(ctx context.Context, userID int64, value float64, unit string, createdAt time.Time) (int64, error)
+	addFn    func(ctx context.Context, userID int64, value float64, unit string, comp domain.BodyComposition, createdAt time.Time) (int64, error)
 	deleteFn func(ctx context.Context, userID int64) (bool, error)
-	latestFn func(ctx context.Context, userID int64, localDay string) (*domain.WeightEntry, error)
-	listFn   func(ctx context.Context, userID int64, limit int) ([]domain.WeightEntry, error)
+	getFn    func(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.WeightEntry, error)
+	updateFn func(ctx context.Context, userID int64, id int64, value float64, unit string, comp domain.BodyComposition, createdAt time.Time) (bool, error)
+	delIDFn  func(ctx context.Context, userID int64, id int64) (bool, error)
+	latestFn func(ctx context.Context, userID int64, localDay string, loc *time.Location) (*domain.WeightEntry, error)
+	listFn   func(ctx context.Context, userID int64, limit int, loc *time.Location) ([]domain.WeightEntry, error)
+	queryFn  func(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.WeightEntry, error)
+	rangeFn  func(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]domain.WeightEntry, error)
+
+	// gotPolicy records the policy of the last daily weight query.
+	gotPolicy domain.WeightPolicy
 }
 
-func (m *mockWeightRepo) AddWeightEvent(ctx context.Context, userID int64, value float64, unit string, createdAt time.Time) (int64, error) {
+func (m *mockWeightRepo) AddWeightEvent(ctx context.Context, userID int64, value float64, unit string, comp domain.BodyComposition, createdAt time.Time) (int64, error) {
 	if m.addFn != nil {
-		return m.addFn(ctx, userID, value, unit, createdAt)
+		return m.addFn(ctx, userID, value, unit, comp, createdAt)
 	}
 	return 1, nil
 }
@@ -41,9 +54,31 @@ func (m *mockWeightRepo) DeleteLatestWeightEvent(ctx context.Context, userID int
 	return true, nil
 }
 
-func (m *mockWeightRepo) LatestWeightForLocalDay(ctx context.Context, userID int64, localDay string) (*domain.WeightEntry, error) {
+func (m *mockWeightRepo) GetWeightEvent(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.WeightEntry, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID, id, loc)
+	}
+	return &domain.WeightEntry{ID: id, Day: "2026-02-08", Value: 80.0, Unit: "kg", CreatedAt: time.Now()}, nil
+}
+
+func (m *mockWeightRepo) UpdateWeightEvent(ctx context.Context, userID int64, id int64, value float64, unit string, comp domain.BodyComposition, createdAt time.Time) (bool, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, userID, id, value, unit, comp, createdAt)
+	}
+	return true, nil
+}
+
+func (m *mockWeightRepo) DeleteWeightEvent(ctx context.Context, userID int64, id int64) (bool, error) {
+	if m.delIDFn != nil {
+		return m.delIDFn(ctx, userID, id)
+	}
+	return true, nil
+}
+
+func (m *mockWeightRepo) WeightForLocalDay(ctx context.Context, userID int64, localDay string, policy domain.WeightPolicy, loc *time.Location) (*domain.WeightEntry, error) {
+	m.gotPolicy = policy
 	if m.latestFn != nil {
-		return m.latestFn(ctx, userID, localDay)
+		return m.latestFn(ctx, userID, localDay, loc)
 	}
 	return &domain.WeightEntry{
 		ID: 1, Day: localDay, Value: 80.0, Unit: "kg",
@@ -51,20 +86,42 @@ func (m *mockWeightRepo) LatestWeightForLocalDay(ctx context.Context, userID int
 	}, nil
 }
 
-func (m *mockWeightRepo) ListRecentWeightEvents(ctx context.Context, userID int64, limit int) ([]domain.WeightEntry, error) {
+func (m *mockWeightRepo) ListRecentWeightEvents(ctx context.Context, userID int64, limit int, loc *time.Location) ([]domain.WeightEntry, error) {
 	if m.listFn != nil {
-		return m.listFn(ctx, userID, limit)
+		return m.listFn(ctx, userID, limit, loc)
 	}
 	return []domain.WeightEntry{
 		{ID: 1, Day: "2026-02-08", Value: 80.0, Unit: "kg", CreatedAt: time.Now()},
 	}, nil
 }
 
+func (m *mockWeightRepo) ListWeightEvents(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.WeightEntry, error) {
+	if m.queryFn != nil {
+		return m.queryFn(ctx, userID, q, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockWeightRepo) WeightsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, policy domain.WeightPolicy, loc *time.Location) (map[string]domain.WeightEntry, error) {
+	m.gotPolicy = policy
+	if m.rangeFn != nil {
+		return m.rangeFn(ctx, userID, fromDay, toDay, loc)
+	}
+	return map[string]domain.WeightEntry{
+		toDay: {ID: 1, Day: toDay, Value: 80.0, Unit: "kg", CreatedAt: time.Now()},
+	}, nil
+}
+
 type mockWaterRepo struct {
-	addFn   func(ctx context.Context, userID int64, deltaLiters float64, createdAt time.Time) (int64, error)
-	delFn   func(ctx context.Context, userID int64, id int64) error
-	listFn  func(ctx context.Context, userID int64, limit int) ([]domain.WaterEvent, error)
-	totalFn func(ctx context.Context, userID int64, localDay string) (float64, error)
+	addFn    func(ctx context.Context, userID int64, deltaLiters float64, createdAt time.Time) (int64, error)
+	delFn    func(ctx context.Context, userID int64, id int64) (bool, error)
+	getFn    func(ctx context.Context, userID int64, id int64) (*domain.WaterEvent, error)
+	updateFn func(ctx context.Context, userID int64, id int64, deltaLiters float64, createdAt time.Time) (bool, error)
+	listFn   func(ctx context.Context, userID int64, limit int) ([]domain.WaterEvent, error)
+	queryFn  func(ctx context.Context, userID int64, q domain.EventQuery) ([]domain.WaterEvent, error)
+	totalFn  func(ctx context.Context, userID int64, localDay string, loc *time.Location) (float64, error)
+	totalsFn func(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error)
+	hourlyFn func(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (domain.WeekdayHourTotals, error)
 }
 
 func (m *mockWaterRepo) AddWaterEvent(ctx context.Context, userID int64, deltaLiters float64, createdAt time.Time) (int64, error) {
@@ -74,11 +131,25 @@ func (m *mockWaterRepo) AddWaterEvent(ctx context.Context, userID int64, deltaLi
 	return 42, nil
 }
 
-func (m *mockWaterRepo) DeleteWaterEvent(ctx context.Context, userID int64, id int64) error {
+func (m *mockWaterRepo) DeleteWaterEvent(ctx context.Context, userID int64, id int64) (bool, error) {
 	if m.delFn != nil {
 		return m.delFn(ctx, userID, id)
 	}
-	return nil
+	return true, nil
+}
+
+func (m *mockWaterRepo) GetWaterEvent(ctx context.Context, userID int64, id int64) (*domain.WaterEvent, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID, id)
+	}
+	return &domain.WaterEvent{ID: id, UserID: userID, DeltaLiters: 0.5, CreatedAt: time.Now()}, nil
+}
+
+func (m *mockWaterRepo) UpdateWaterEvent(ctx context.Context, userID int64, id int64, deltaLiters float64, createdAt time.Time) (bool, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, userID, id, deltaLiters, createdAt)
+	}
+	return true, nil
 }
 
 func (m *mockWaterRepo) ListRecentWaterEvents(ctx context.Context, userID int64, limit int) ([]domain.WaterEvent, error) {
@@ -90,14 +161,152 @@ func (m *mockWaterRepo) ListRecentWaterEvents(ctx context.Context, userID int64,
 	}, nil
 }
 
-func (m *mockWaterRepo) WaterTotalForLocalDay(ctx context.Context, userID int64, localDay string) (float64, error) {
+func (m *mockWaterRepo) ListWaterEvents(ctx context.Context, userID int64, q domain.EventQuery) ([]domain.WaterEvent, error) {
+	if m.queryFn != nil {
+		return m.queryFn(ctx, userID, q)
+	}
+	return nil, nil
+}
+
+func (m *mockWaterRepo) WaterTotalForLocalDay(ctx context.Context, userID int64, localDay string, loc *time.Location) (float64, error) {
 	if m.totalFn != nil {
-		return m.totalFn(ctx, userID, localDay)
+		return m.totalFn(ctx, userID, localDay, loc)
 	}
 	return 2.5, nil
 }
 
-type mockUserRepo struct{}
+func (m *mockWaterRepo) WaterTotalsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error) {
+	if m.totalsFn != nil {
+		return m.totalsFn(ctx, userID, fromDay, toDay, loc)
+	}
+	return map[string]float64{toDay: 2.5}, nil
+}
+
+func (m *mockWaterRepo) WaterTotalsByWeekdayHour(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (domain.WeekdayHourTotals, error) {
+	if m.hourlyFn != nil {
+		return m.hourlyFn(ctx, userID, fromDay, toDay, loc)
+	}
+	var totals domain.WeekdayHourTotals
+	totals[time.Monday][8] = 0.5
+	return totals, nil
+}
+
+type mockBloodPressureRepo struct {
+	addFn    func(ctx context.Context, userID int64, r domain.BloodPressureReading) (int64, error)
+	getFn    func(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.BloodPressureReading, error)
+	updateFn func(ctx context.Context, userID int64, id int64, r domain.BloodPressureReading) (bool, error)
+	delFn    func(ctx context.Context, userID int64, id int64) (bool, error)
+	listFn   func(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.BloodPressureReading, error)
+	avgFn    func(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]domain.BloodPressureAverage, error)
+}
+
+func (m *mockBloodPressureRepo) AddBloodPressureReading(ctx context.Context, userID int64, r domain.BloodPressureReading) (int64, error) {
+	if m.addFn != nil {
+		return m.addFn(ctx, userID, r)
+	}
+	return 1, nil
+}
+
+func (m *mockBloodPressureRepo) GetBloodPressureReading(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.BloodPressureReading, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID, id, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockBloodPressureRepo) UpdateBloodPressureReading(ctx context.Context, userID int64, id int64, r domain.BloodPressureReading) (bool, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, userID, id, r)
+	}
+	return true, nil
+}
+
+func (m *mockBloodPressureRepo) DeleteBloodPressureReading(ctx context.Context, userID int64, id int64) (bool, error) {
+	if m.delFn != nil {
+		return m.delFn(ctx, userID, id)
+	}
+	return true, nil
+}
+
+func (m *mockBloodPressureRepo) ListBloodPressureReadings(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.BloodPressureReading, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, q, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockBloodPressureRepo) BloodPressureAveragesForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]domain.BloodPressureAverage, error) {
+	if m.avgFn != nil {
+		return m.avgFn(ctx, userID, fromDay, toDay, loc)
+	}
+	return nil, nil
+}
+
+type mockSleepRepo struct {
+	listFn func(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.SleepSession, error)
+}
+
+func (m *mockSleepRepo) AddSleepSession(ctx context.Context, userID int64, s domain.SleepSession) (int64, error) {
+	return 1, nil
+}
+
+func (m *mockSleepRepo) GetSleepSession(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.SleepSession, error) {
+	return nil, nil
+}
+
+func (m *mockSleepRepo) UpdateSleepSession(ctx context.Context, userID int64, id int64, s domain.SleepSession) (bool, error) {
+	return true, nil
+}
+
+func (m *mockSleepRepo) DeleteSleepSession(ctx context.Context, userID int64, id int64) (bool, error) {
+	return true, nil
+}
+
+func (m *mockSleepRepo) ListSleepSessions(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.SleepSession, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, q, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockSleepRepo) SleepMinutesForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error) {
+	return nil, nil
+}
+
+type mockMeasurementRepo struct {
+	latestFn func(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]map[string]domain.Measurement, error)
+}
+
+func (m *mockMeasurementRepo) AddMeasurement(ctx context.Context, userID int64, site string, value float64, unit string, createdAt time.Time) (int64, error) {
+	return 1, nil
+}
+
+func (m *mockMeasurementRepo) GetMeasurement(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.Measurement, error) {
+	return nil, nil
+}
+
+func (m *mockMeasurementRepo) UpdateMeasurement(ctx context.Context, userID int64, id int64, site string, value float64, unit string, createdAt time.Time) (bool, error) {
+	return true, nil
+}
+
+func (m *mockMeasurementRepo) DeleteMeasurement(ctx context.Context, userID int64, id int64) (bool, error) {
+	return true, nil
+}
+
+func (m *mockMeasurementRepo) ListMeasurements(ctx context.Context, userID int64, site string, q domain.EventQuery, loc *time.Location) ([]domain.Measurement, error) {
+	return nil, nil
+}
+
+func (m *mockMeasurementRepo) LatestMeasurementsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]map[string]domain.Measurement, error) {
+	if m.latestFn != nil {
+		return m.latestFn(ctx, userID, fromDay, toDay, loc)
+	}
+	return nil, nil
+}
+
+type mockUserRepo struct {
+	updateErr error
+}
 
 func (m *mockUserRepo) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
 	return nil, nil
@@ -115,6 +324,37 @@ func (m *mockUserRepo) Count(ctx context.Context) (int, error) {
 	return 0, nil
 }
 
+// UpdateProfile returns the development user with the preferences of p.
+func (m *mockUserRepo) UpdateProfile(ctx context.Context, id int64, p domain.ProfileUpdate) (*domain.User, error) {
+	if m.updateErr != nil {
+		return nil, m.updateErr
+	}
+	u := &domain.User{ID: id, Username: "dev"}
+	if p.Timezone != nil {
+		u.Timezone = *p.Timezone
+	}
+	if p.WaterGoalLiters != nil {
+		u.WaterGoalLiters = *p.WaterGoalLiters
+	}
+	if p.WeightPolicy != nil {
+		u.DailyWeightPolicy = *p.WeightPolicy
+	}
+	return u, nil
+}
+
+// mockImportRepo is an ImportRepository whose writes fail with err.
+type mockImportRepo struct {
+	err error
+}
+
+func (m *mockImportRepo) ImportEvents(ctx context.Context, userID int64, weights []domain.WeightEntry, water []domain.WaterEvent) error {
+	return m.err
+}
+
+func (m *mockImportRepo) RestoreAccount(ctx context.Context, userID int64, weights []domain.WeightEntry, water []domain.WaterEvent, p domain.ProfileUpdate) (bool, error) {
+	return false, m.err
+}
+
 type mockSessionRepo struct{}
 
 func (m *mockSessionRepo) Create(ctx context.Context, userID int64, token, userAgent, ip string, expiresAt time.Time) error {
@@ -139,6 +379,13 @@ func (m *mockSessionRepo) DeleteExpired(ctx context.Context) error {
 
 func newTestServer(t *testing.T, wr *mockWeightRepo, wa *mockWaterRepo) *httptest.Server {
 	t.Helper()
+	return httptest.NewServer(newTestAPI(t, wr, wa).Handler())
+}
+
+// newTestAPI builds an unauthenticated Server backed by the given mocks so
+// tests can enable optional services before starting it.
+func newTestAPI(t *testing.T, wr *mockWeightRepo, wa *mockWaterRepo) *adapthttp.Server {
+	t.Helper()
 
 	if wr == nil {
 		wr = &mockWeightRepo{}
@@ -159,8 +406,9 @@ func newTestServer(t *testing.T, wr *mockWeightRepo, wa *mockWaterRepo) *httptes
 		t.Fatal(err)
 	}
 
-	srv := adapthttp.New(ws, was, cs, authSvc, webDir).WithoutAuth()
-	return httptest.NewServer(srv.Handler())
+	return adapthttp.New(ws, was, cs, authSvc, webDir).
+		WithProfile(app.NewProfileService(&mockUserRepo{})).
+		WithoutAuth()
 }
 
 func decodeBody(t *testing.T, resp *http.Response) map[string]any {
@@ -197,14 +445,15 @@ func TestHealthEndpoint(t *testing.T) {
 }
 
 func TestWeightTodayGet(t *testing.T) {
-	ts := newTestServer(t, &mockWeightRepo{
-		latestFn: func(_ context.Context, _ int64, localDay string) (*domain.WeightEntry, error) {
+	wr := &mockWeightRepo{
+		latestFn: func(_ context.Context, _ int64, localDay string, _ *time.Location) (*domain.WeightEntry, error) {
 			return &domain.WeightEntry{
 				ID: 1, Day: localDay, Value: 82.3, Unit: "kg",
 				CreatedAt: time.Date(2026, 2, 8, 7, 0, 0, 0, time.UTC),
 			}, nil
 		},
-	}, nil)
+	}
+	ts := newTestServer(t, wr, nil)
 	defer ts.Close()
 
 	resp, err := http.Get(ts.URL + "/api/weight/today")
@@ -224,6 +473,39 @@ func TestWeightTodayGet(t *testing.T) {
 	if _, ok := body["entry"]; !ok {
 		t.Fatal("response missing 'entry' field")
 	}
+	if body["policy"] != "last" || wr.gotPolicy != domain.WeightPolicyLast {
+		t.Fatalf("expected the default policy, got %v", body["policy"])
+	}
+
+	resp, err = http.Get(ts.URL + "/api/weight/today?policy=min")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || wr.gotPolicy != domain.WeightPolicyMin {
+		t.Fatalf("expected the min policy, got %d %q", resp.StatusCode, wr.gotPolicy)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/weight/today?policy=median")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown policy, got %d", resp.StatusCode)
+	}
+
+	wr.latestFn = func(_ context.Context, _ int64, _ string, _ *time.Location) (*domain.WeightEntry, error) {
+		return nil, errors.New("connection refused")
+	}
+	resp, err = http.Get(ts.URL + "/api/weight/today")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected 500 for a repository failure, got %d", resp.StatusCode)
+	}
 }
 
 func TestWeightTodayPut(t *testing.T) {
@@ -257,6 +539,26 @@ func TestWeightTodayPut(t *testing.T) {
 			payload:    map[string]any{"value": 80.0, "unit": "stone"},
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "backdated day",
+			payload:    map[string]any{"value": 80.0, "unit": "kg", "day": time.Now().AddDate(0, 0, -2).Format("2006-01-02")},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "future timestamp",
+			payload:    map[string]any{"value": 80.0, "unit": "kg", "at": time.Now().Add(24 * time.Hour).Format(time.RFC3339)},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "with composition",
+			payload:    map[string]any{"value": 80.0, "unit": "kg", "bodyFatPercent": 21.5, "muscleMass": 58.2, "visceralFat": 8},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "body fat out of range",
+			payload:    map[string]any{"value": 80.0, "unit": "kg", "bodyFatPercent": 120},
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	ts := newTestServer(t, nil, nil)
@@ -292,15 +594,51 @@ func TestWeightTodayPut(t *testing.T) {
 	}
 }
 
+func TestWeightTodayPut_Composition(t *testing.T) {
+	var stored domain.BodyComposition
+	ts := newTestServer(t, &mockWeightRepo{
+		addFn: func(_ context.Context, _ int64, _ float64, _ string, comp domain.BodyComposition, _ time.Time) (int64, error) {
+			stored = comp
+			return 1, nil
+		},
+		latestFn: func(_ context.Context, _ int64, day string, _ *time.Location) (*domain.WeightEntry, error) {
+			return &domain.WeightEntry{ID: 1, Day: day, Value: 80, Unit: "kg", BodyComposition: stored}, nil
+		},
+	}, nil)
+	defer ts.Close()
+
+	b, _ := json.Marshal(map[string]any{"value": 80.0, "unit": "kg", "bodyFatPercent": 20, "boneMass": 3.1})
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/api/weight/today", bytes.NewReader(b))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body := decodeBody(t, resp)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+	}
+	if stored.BodyFatPercent == nil || *stored.BodyFatPercent != 20 || stored.BoneMass == nil || stored.MuscleMass != nil {
+		t.Fatalf("unexpected stored composition: %+v", stored)
+	}
+	entry, _ := body["entry"].(map[string]any)
+	if entry["bodyFatPercent"] != 20.0 || entry["boneMass"] != 3.1 {
+		t.Errorf("expected composition in response, got %v", entry)
+	}
+	if _, ok := entry["muscleMass"]; ok {
+		t.Error("expected unset muscleMass to be omitted")
+	}
+}
+
 func TestWeightRecent(t *testing.T) {
 	items := []domain.WeightEntry{
 		{ID: 1, Day: "2026-02-08", Value: 80.0, Unit: "kg", CreatedAt: time.Now()},
 		{ID: 2, Day: "2026-02-07", Value: 81.0, Unit: "kg", CreatedAt: time.Now()},
 	}
 	ts := newTestServer(t, &mockWeightRepo{
-		listFn: func(_ context.Context, _ int64, limit int) ([]domain.WeightEntry, error) {
-			if limit < len(items) {
-				return items[:limit], nil
+		queryFn: func(_ context.Context, _ int64, q domain.EventQuery, _ *time.Location) ([]domain.WeightEntry, error) {
+			if q.Limit < len(items) {
+				return items[:q.Limit], nil
 			}
 			return items, nil
 		},
@@ -356,7 +694,7 @@ func TestWeightUndoLast(t *testing.T) {
 
 func TestWaterTodayGet(t *testing.T) {
 	ts := newTestServer(t, nil, &mockWaterRepo{
-		totalFn: func(_ context.Context, _ int64, _ string) (float64, error) {
+		totalFn: func(_ context.Context, _ int64, _ string, _ *time.Location) (float64, error) {
 			return 3.0, nil
 		},
 	})
@@ -383,6 +721,13 @@ func TestWaterTodayGet(t *testing.T) {
 	if total != 3.0 {
 		t.Fatalf("expected totalLiters=3.0, got %v", total)
 	}
+	if body["goalLiters"] != domain.DefaultWaterGoalLiters || body["percent"] != 150.0 || body["remainingLiters"] != 0.0 {
+		t.Errorf("unexpected progress: %v", body)
+	}
+	streak, _ := body["streak"].(map[string]any)
+	if streak["current"] != 1.0 || streak["longest"] != 1.0 {
+		t.Errorf("expected a one-day streak, got %v", body["streak"])
+	}
 }
 
 func TestWaterEvent(t *testing.T) {
@@ -411,6 +756,16 @@ func TestWaterEvent(t *testing.T) {
 			payload:    map[string]any{"deltaLiters": 11.0},
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name:       "backdated timestamp",
+			payload:    map[string]any{"deltaLiters": 0.5, "at": time.Now().Add(-6 * time.Hour).Format(time.RFC3339)},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "beyond lookback",
+			payload:    map[string]any{"deltaLiters": 0.5, "day": "2000-01-01"},
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	ts := newTestServer(t, nil, nil)
@@ -446,9 +801,9 @@ func TestWaterRecent(t *testing.T) {
 		{ID: 11, DeltaLiters: 0.3, CreatedAt: time.Now()},
 	}
 	ts := newTestServer(t, nil, &mockWaterRepo{
-		listFn: func(_ context.Context, _ int64, limit int) ([]domain.WaterEvent, error) {
-			if limit < len(events) {
-				return events[:limit], nil
+		queryFn: func(_ context.Context, _ int64, q domain.EventQuery) ([]domain.WaterEvent, error) {
+			if q.Limit < len(events) {
+				return events[:q.Limit], nil
 			}
 			return events, nil
 		},
@@ -475,6 +830,98 @@ func TestWaterRecent(t *testing.T) {
 	}
 }
 
+func TestWaterRecent_Paged(t *testing.T) {
+	events := []domain.WaterEvent{
+		{ID: 12, DeltaLiters: 0.5, CreatedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)},
+		{ID: 11, DeltaLiters: 0.3, CreatedAt: time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)},
+	}
+	var got domain.EventQuery
+	ts := newTestServer(t, nil, &mockWaterRepo{
+		queryFn: func(_ context.Context, _ int64, q domain.EventQuery) ([]domain.WaterEvent, error) {
+			got = q
+			if q.Limit < len(events) {
+				return events[:q.Limit], nil
+			}
+			return events, nil
+		},
+	})
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/water/recent?limit=1&from=2026-03-01&to=2026-03-02")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body := decodeBody(t, resp)
+	resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if arr, _ := body["items"].([]any); len(arr) != 1 {
+		t.Fatalf("expected 1 item, got %v", body["items"])
+	}
+	next, ok := body["nextCursor"].(string)
+	if !ok || next == "" {
+		t.Fatalf("expected nextCursor, got %v", body["nextCursor"])
+	}
+	if got.From.IsZero() || !got.To.After(got.From) {
+		t.Errorf("expected from/to bounds, got %v..%v", got.From, got.To)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/water/recent?cursor=" + next)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body = decodeBody(t, resp)
+	resp.Body.Close() //nolint:errcheck
+	if got.After == nil || got.After.ID != 12 {
+		t.Fatalf("expected cursor after id 12, got %+v", got.After)
+	}
+	if body["nextCursor"] != nil {
+		t.Errorf("expected null nextCursor on last page, got %v", body["nextCursor"])
+	}
+
+	resp, err = http.Get(ts.URL + "/api/water/recent?cursor=bogus!")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad cursor, got %d", resp.StatusCode)
+	}
+}
+
+func TestRecent_RepositoryError(t *testing.T) {
+	failing := errors.New("connection refused")
+	ts := newTestServer(t, &mockWeightRepo{
+		queryFn: func(context.Context, int64, domain.EventQuery, *time.Location) ([]domain.WeightEntry, error) {
+			return nil, failing
+		},
+	}, &mockWaterRepo{
+		queryFn: func(context.Context, int64, domain.EventQuery) ([]domain.WaterEvent, error) {
+			return nil, failing
+		},
+	})
+	defer ts.Close()
+
+	for _, path := range []string{"/api/weight/recent", "/api/water/recent"} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close() //nolint:errcheck
+		if resp.StatusCode != http.StatusInternalServerError {
+			t.Errorf("%s: expected 500 for a repository error, got %d", path, resp.StatusCode)
+		}
+		if resp, err = http.Get(ts.URL + path + "?from=someday"); err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close() //nolint:errcheck
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400 for a bad bound, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
 func TestWaterUndoLast(t *testing.T) {
 	ts := newTestServer(t, nil, &mockWaterRepo{
 		listFn: func(_ context.Context, _ int64, limit int) ([]domain.WaterEvent, error) {
@@ -504,39 +951,1077 @@ func TestWaterUndoLast(t *testing.T) {
 	}
 }
 
-func TestMethodNotAllowed(t *testing.T) {
+func TestChartsDaily(t *testing.T) {
 	ts := newTestServer(t, nil, nil)
 	defer ts.Close()
 
-	tests := []struct {
-		name   string
-		method string
-		path   string
-	}{
-		{"DELETE weight/today", http.MethodDelete, "/api/weight/today"},
-		{"POST weight/recent", http.MethodPost, "/api/weight/recent"},
-		{"GET weight/undo-last", http.MethodGet, "/api/weight/undo-last"},
-		{"PUT water/today", http.MethodPut, "/api/water/today"},
-		{"GET water/event", http.MethodGet, "/api/water/event"},
-		{"POST water/recent", http.MethodPost, "/api/water/recent"},
-		{"GET water/undo-last", http.MethodGet, "/api/water/undo-last"},
+	resp, err := http.Get(ts.URL + "/api/charts/daily?days=7&unit=kg")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
 	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	body := decodeBody(t, resp)
+	arr, ok := body["items"].([]any)
+	if !ok {
+		t.Fatal("response missing 'items' array")
+	}
+	if len(arr) != 7 {
+		t.Fatalf("expected 7 items, got %d", len(arr))
+	}
+	last, _ := arr[6].(map[string]any)
+	if last["waterLiters"] != 2.5 {
+		t.Fatalf("expected waterLiters=2.5 on last day, got %v", last["waterLiters"])
+	}
+	if last["weight"] == nil {
+		t.Fatal("expected weight on last day")
+	}
+	if last["weightTrend"] == nil {
+		t.Fatal("expected weightTrend on last day")
+	}
+	trend, _ := body["trend"].(map[string]any)
+	if trend["method"] != "ema" || trend["alpha"] != 0.1 {
+		t.Fatalf("expected the default ema trend, got %v", body["trend"])
+	}
+
+	resp, err = http.Get(ts.URL + "/api/charts/daily?unit=kg&from=2024-06-01&to=2024-06-30")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body = decodeBody(t, resp)
+	resp.Body.Close() //nolint:errcheck
+	if body["from"] != "2024-06-01" || body["to"] != "2024-06-30" || body["days"] != 30.0 {
+		t.Fatalf("expected June 2024, got %v..%v (%v days)", body["from"], body["to"], body["days"])
+	}
+
+	for _, query := range []string{"from=2024-06-30&to=2024-06-01", "trend=sma&window=91", "trend=ema&alpha=2", "alpha=x", "trend=wma"} {
+		resp, err := http.Get(ts.URL + "/api/charts/daily?days=7&unit=kg&" + query)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close() //nolint:errcheck
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestChartsDaily_Buckets(t *testing.T) {
+	ts := newTestServer(t, nil, nil)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/charts/daily?days=60&unit=kg&bucket=month&weightAgg=max&waterAgg=mean")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	body := decodeBody(t, resp)
+	if body["bucket"] != "month" {
+		t.Fatalf("expected bucket=month, got %v", body["bucket"])
+	}
+	arr, ok := body["items"].([]any)
+	if !ok || len(arr) < 2 {
+		t.Fatalf("expected monthly items, got %v", body["items"])
+	}
+	last, _ := arr[len(arr)-1].(map[string]any)
+	if last["end"] != body["today"] || last["weight"] == nil {
+		t.Fatalf("expected the current month to end today with a weight, got %v", last)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/charts/daily?bucket=fortnight")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown bucket, got %d", resp.StatusCode)
+	}
+}
+
+func TestChartsCalendar(t *testing.T) {
+	ts := newTestServer(t, nil, nil)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/charts/calendar?year=2024")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body := decodeBody(t, resp)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+	}
+	arr, _ := body["items"].([]any)
+	if body["year"] != 2024.0 || len(arr) != 366 {
+		t.Fatalf("expected 366 days of 2024, got %v with %d items", body["year"], len(arr))
+	}
+	last, _ := arr[365].(map[string]any)
+	if last["day"] != "2024-12-31" || last["waterLevel"] != body["maxWaterLevel"] || last["weightLogged"] != true {
+		t.Errorf("expected the goal met and a weigh-in on the last day, got %v", last)
+	}
+	first, _ := arr[0].(map[string]any)
+	if first["waterLevel"] != 0.0 || first["weightLogged"] != false {
+		t.Errorf("expected an empty first day, got %v", first)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/charts/calendar?year=10000")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an out-of-range year, got %d", resp.StatusCode)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	ts := newTestServer(t, nil, nil)
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		timezone   string
+		waterGoal  float64
+		policy     string
+		wantStatus int
+	}{
+		{"valid zone", "America/Chicago", 3, "mean", http.StatusOK},
+		{"unknown zone", "Atlantis/Capital", 3, "mean", http.StatusBadRequest},
+		{"water goal too large", "America/Chicago", 40, "mean", http.StatusBadRequest},
+		{"unknown weight policy", "America/Chicago", 3, "median", http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b, _ := json.Marshal(map[string]any{"timezone": tc.timezone, "waterGoalLiters": tc.waterGoal, "weightPolicy": tc.policy})
+			req, err := http.NewRequest(http.MethodPut, ts.URL+"/api/profile", bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("new request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close() //nolint:errcheck
+
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("expected %d, got %d", tc.wantStatus, resp.StatusCode)
+			}
+			if tc.wantStatus == http.StatusOK {
+				body := decodeBody(t, resp)
+				if body["timezone"] != tc.timezone || body["waterGoalLiters"] != tc.waterGoal || body["weightPolicy"] != tc.policy {
+					t.Fatalf("expected timezone %q, water goal %v and policy %q, got %v", tc.timezone, tc.waterGoal, tc.policy, body)
+				}
+			}
+		})
+	}
+}
+
+func TestProfileStorageError(t *testing.T) {
+	users := &mockUserRepo{updateErr: errors.New("connection refused")}
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithProfile(app.NewProfileService(users)).
+		Handler())
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL+"/api/profile", strings.NewReader(`{"timezone": "America/Chicago"}`))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected 500 for a storage failure, got %d", resp.StatusCode)
+	}
+}
+
+func TestWeightEntryByID(t *testing.T) {
+	ts := newTestServer(t, &mockWeightRepo{
+		getFn: func(_ context.Context, _ int64, id int64, _ *time.Location) (*domain.WeightEntry, error) {
+			if id != 5 {
+				return nil, nil
+			}
+			return &domain.WeightEntry{ID: 5, Value: 80, Unit: "kg", CreatedAt: time.Now().Add(-time.Hour)}, nil
+		},
+		delIDFn: func(_ context.Context, _ int64, id int64) (bool, error) {
+			return id == 5, nil
+		},
+	}, nil)
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		payload    map[string]any
+		wantStatus int
+	}{
+		{"get", http.MethodGet, "/api/weight/5", nil, http.StatusOK},
+		{"patch value", http.MethodPatch, "/api/weight/5", map[string]any{"value": 79.4}, http.StatusOK},
+		{"patch invalid unit", http.MethodPatch, "/api/weight/5", map[string]any{"unit": "st"}, http.StatusBadRequest},
+		{"patch missing", http.MethodPatch, "/api/weight/6", map[string]any{"value": 79.4}, http.StatusNotFound},
+		{"delete", http.MethodDelete, "/api/weight/5", nil, http.StatusOK},
+		{"delete missing", http.MethodDelete, "/api/weight/6", nil, http.StatusNotFound},
+		{"bad id", http.MethodDelete, "/api/weight/abc", nil, http.StatusBadRequest},
+		{"method not allowed", http.MethodPost, "/api/weight/5", nil, http.StatusMethodNotAllowed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var body *bytes.Reader
+			if tc.payload != nil {
+				b, _ := json.Marshal(tc.payload)
+				body = bytes.NewReader(b)
+			} else {
+				body = bytes.NewReader(nil)
+			}
+			req, err := http.NewRequest(tc.method, ts.URL+tc.path, body)
+			if err != nil {
+				t.Fatalf("new request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close() //nolint:errcheck
+
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("expected %d, got %d", tc.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestWaterEntryByID(t *testing.T) {
+	ts := newTestServer(t, nil, &mockWaterRepo{
+		delFn: func(_ context.Context, _ int64, id int64) (bool, error) {
+			return id == 10, nil
+		},
+	})
+	defer ts.Close()
+
+	b, _ := json.Marshal(map[string]any{"deltaLiters": 0.75})
+	req, _ := http.NewRequest(http.MethodPatch, ts.URL+"/api/water/10", bytes.NewReader(b))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body := decodeBody(t, resp)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	event, _ := body["event"].(map[string]any)
+	if event["deltaLiters"] != 0.75 {
+		t.Fatalf("expected deltaLiters=0.75, got %v", event["deltaLiters"])
+	}
+
+	req, _ = http.NewRequest(http.MethodDelete, ts.URL+"/api/water/11", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestBloodPressure(t *testing.T) {
+	var added domain.BloodPressureReading
+	stored := domain.BloodPressureReading{ID: 3, Systolic: 128, Diastolic: 82, CreatedAt: time.Now()}
+	br := &mockBloodPressureRepo{
+		addFn: func(_ context.Context, _ int64, r domain.BloodPressureReading) (int64, error) {
+			added = r
+			return 3, nil
+		},
+		getFn: func(_ context.Context, _ int64, id int64, _ *time.Location) (*domain.BloodPressureReading, error) {
+			if id != stored.ID {
+				return nil, nil
+			}
+			r := stored
+			return &r, nil
+		},
+		listFn: func(_ context.Context, _ int64, _ domain.EventQuery, _ *time.Location) ([]domain.BloodPressureReading, error) {
+			return []domain.BloodPressureReading{stored}, nil
+		},
+	}
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithBloodPressure(app.NewBloodPressureService(br)).
+		Handler())
+	defer ts.Close()
+
+	b, _ := json.Marshal(map[string]any{"systolic": 142, "diastolic": 88, "pulse": 70, "arm": "left", "position": "sitting"})
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+"/api/bp/today", bytes.NewReader(b))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body := decodeBody(t, resp)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+	}
+	entry, _ := body["entry"].(map[string]any)
+	if entry["category"] != string(domain.BPStage2) {
+		t.Errorf("expected stage 2 category, got %v", entry["category"])
+	}
+	if added.Systolic != 142 || added.Pulse == nil || *added.Pulse != 70 || added.Arm != "left" {
+		t.Errorf("unexpected stored reading: %+v", added)
+	}
+
+	b, _ = json.Marshal(map[string]any{"systolic": 80, "diastolic": 90})
+	req, _ = http.NewRequest(http.MethodPut, ts.URL+"/api/bp/today", bytes.NewReader(b))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for diastolic above systolic, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/bp/today")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body = decodeBody(t, resp)
+	_ = resp.Body.Close()
+	if readings, _ := body["readings"].([]any); len(readings) != 1 {
+		t.Fatalf("expected 1 reading today, got %v", body["readings"])
+	}
+	entry, _ = body["entry"].(map[string]any)
+	if entry["category"] != string(domain.BPStage1) {
+		t.Errorf("expected stage 1 category, got %v", entry["category"])
+	}
+
+	resp, err = http.Get(ts.URL + "/api/bp/4")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestBloodPressureDisabled(t *testing.T) {
+	ts := newTestServer(t, nil, nil)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/bp/today")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 without a blood pressure service, got %d", resp.StatusCode)
+	}
+}
+
+func TestSleepSession(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	existing := domain.SleepSession{ID: 2, Bedtime: now.Add(-20 * time.Hour), WakeTime: now.Add(-14 * time.Hour)}
+	sr := &mockSleepRepo{
+		listFn: func(_ context.Context, _ int64, _ domain.EventQuery, _ *time.Location) ([]domain.SleepSession, error) {
+			return []domain.SleepSession{existing}, nil
+		},
+	}
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithSleep(app.NewSleepService(sr)).
+		Handler())
+	defer ts.Close()
+
+	post := func(bed, wake time.Time) (*http.Response, map[string]any) {
+		t.Helper()
+		b, _ := json.Marshal(map[string]any{"bedtime": bed, "wakeTime": wake, "quality": 4, "interruptions": 1})
+		resp, err := http.Post(ts.URL+"/api/sleep/session", "application/json", bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		body := decodeBody(t, resp)
+		_ = resp.Body.Close()
+		return resp, body
+	}
+
+	resp, body := post(now.Add(-8*time.Hour), now.Add(-time.Hour))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+	}
+	entry, _ := body["entry"].(map[string]any)
+	if entry["durationMinutes"] != 420.0 || entry["quality"] != 4.0 {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+
+	resp, _ = post(now.Add(-16*time.Hour), now.Add(-10*time.Hour))
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 for overlapping session, got %d", resp.StatusCode)
+	}
+
+	resp, _ = post(now.Add(-time.Hour), now.Add(-2*time.Hour))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for wake before bedtime, got %d", resp.StatusCode)
+	}
+}
+
+func TestMeasurements(t *testing.T) {
+	mr := &mockMeasurementRepo{
+		latestFn: func(_ context.Context, _ int64, _, to string, _ *time.Location) (map[string]map[string]domain.Measurement, error) {
+			return map[string]map[string]domain.Measurement{to: {"waist": {Site: "waist", Value: 33, Unit: "in"}}}, nil
+		},
+	}
+	cs := app.NewChartsService(&mockWeightRepo{}, &mockWaterRepo{}).WithMeasurements(mr)
+	srv := adapthttp.New(app.NewWeightService(&mockWeightRepo{}), app.NewWaterService(&mockWaterRepo{}), cs,
+		app.NewAuthService(&mockUserRepo{}, &mockSessionRepo{}), t.TempDir()).
+		WithMeasurements(app.NewMeasurementService(mr)).
+		WithoutAuth()
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	put := func(payload map[string]any) (*http.Response, map[string]any) {
+		t.Helper()
+		b, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(http.MethodPut, ts.URL+"/api/measurements/today", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		body := decodeBody(t, resp)
+		_ = resp.Body.Close()
+		return resp, body
+	}
+
+	resp, body := put(map[string]any{"site": "waist", "value": 84.5, "unit": "cm"})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+	}
+	entry, _ := body["entry"].(map[string]any)
+	if entry["site"] != "waist" || entry["value"] != 84.5 {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	if resp, _ := put(map[string]any{"site": "ankle", "value": 22, "unit": "cm"}); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown site, got %d", resp.StatusCode)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/charts/measurements?days=3&unit=cm")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body = decodeBody(t, resp)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || body["unit"] != "cm" {
+		t.Fatalf("unexpected chart response %d: %v", resp.StatusCode, body)
+	}
+	items, _ := body["items"].([]any)
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+	last, _ := items[2].(map[string]any)
+	sites, _ := last["sites"].(map[string]any)
+	if waist, _ := sites["waist"].(float64); waist < 83.81 || waist > 83.83 {
+		t.Errorf("expected waist ~83.82 cm on last day, got %v", sites["waist"])
+	}
+}
+
+func TestWeightGoal(t *testing.T) {
+	mem := memory.New()
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithGoals(app.NewGoalService(mem, &mockWeightRepo{})).
+		Handler())
+	defer ts.Close()
+
+	do := func(method, path string, payload any) (*http.Response, map[string]any) {
+		t.Helper()
+		var buf bytes.Buffer
+		if payload != nil {
+			_ = json.NewEncoder(&buf).Encode(payload)
+		}
+		req, _ := http.NewRequest(method, ts.URL+path, &buf)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		body := decodeBody(t, resp)
+		_ = resp.Body.Close()
+		return resp, body
+	}
+
+	if resp, _ := do(http.MethodGet, "/api/goals/weight", nil); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 without a goal, got %d", resp.StatusCode)
+	}
+	if _, body := do(http.MethodGet, "/api/goals", nil); body["weight"] != nil {
+		t.Fatalf("expected null weight goal, got %v", body["weight"])
+	}
+
+	deadline := time.Now().AddDate(0, 3, 0).Format("2006-01-02")
+	resp, body := do(http.MethodPut, "/api/goals/weight", map[string]any{"targetValue": 165, "unit": "lb", "targetDate": deadline})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+	}
+	if body["target"] != 165.0 || body["unit"] != "lb" {
+		t.Errorf("unexpected progress: %v", body)
+	}
+
+	if resp, _ := do(http.MethodPut, "/api/goals/weight", map[string]any{"targetValue": 165, "unit": "st"}); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for bad unit, got %d", resp.StatusCode)
+	}
+
+	_, body = do(http.MethodGet, "/api/goals?unit=kg", nil)
+	weight, _ := body["weight"].(map[string]any)
+	if target, _ := weight["target"].(float64); target < 74.8 || target > 74.9 {
+		t.Errorf("expected target ~74.84 kg, got %v", weight["target"])
+	}
+
+	if resp, _ := do(http.MethodDelete, "/api/goals/weight", nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 on delete, got %d", resp.StatusCode)
+	}
+}
+
+// failingGoalRepo is a domain.WeightGoalRepository whose storage is down.
+type failingGoalRepo struct{ err error }
+
+func (r failingGoalRepo) GetWeightGoal(context.Context, int64) (*domain.WeightGoal, error) {
+	return nil, r.err
+}
+
+func (r failingGoalRepo) SetWeightGoal(context.Context, int64, domain.WeightGoal) error {
+	return r.err
+}
+
+func (r failingGoalRepo) DeleteWeightGoal(context.Context, int64) (bool, error) {
+	return false, r.err
+}
+
+func TestGoals_RepositoryError(t *testing.T) {
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithGoals(app.NewGoalService(failingGoalRepo{err: errors.New("connection refused")}, &mockWeightRepo{})).
+		Handler())
+	defer ts.Close()
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{http.MethodGet, "/api/goals", "", http.StatusInternalServerError},
+		{http.MethodGet, "/api/goals/weight", "", http.StatusInternalServerError},
+		{http.MethodPut, "/api/goals/weight", `{"targetValue":165,"unit":"lb"}`, http.StatusInternalServerError},
+		{http.MethodPut, "/api/goals/weight", `{"targetValue":165,"unit":"st"}`, http.StatusBadRequest},
+		{http.MethodGet, "/api/goals/weight?unit=st", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader(tt.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close() //nolint:errcheck
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s %s: expected %d, got %d", tt.method, tt.path, tt.body, tt.want, resp.StatusCode)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	ts := newTestServer(t, nil, nil)
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{"DELETE weight/today", http.MethodDelete, "/api/weight/today"},
+		{"POST weight/recent", http.MethodPost, "/api/weight/recent"},
+		{"GET weight/undo-last", http.MethodGet, "/api/weight/undo-last"},
+		{"PUT water/today", http.MethodPut, "/api/water/today"},
+		{"GET water/event", http.MethodGet, "/api/water/event"},
+		{"POST water/recent", http.MethodPost, "/api/water/recent"},
+		{"GET water/undo-last", http.MethodGet, "/api/water/undo-last"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, ts.URL+tc.path, nil)
+			if err != nil {
+				t.Fatalf("new request: %v", err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close() //nolint:errcheck
 
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			req, err := http.NewRequest(tc.method, ts.URL+tc.path, nil)
-			if err != nil {
-				t.Fatalf("new request: %v", err)
-			}
-			resp, err := http.DefaultClient.Do(req)
-			if err != nil {
-				t.Fatalf("request failed: %v", err)
-			}
-			defer resp.Body.Close() //nolint:errcheck
-
 			if resp.StatusCode != http.StatusMethodNotAllowed {
 				t.Fatalf("expected 405, got %d", resp.StatusCode)
 			}
 		})
 	}
 }
+
+func TestStats(t *testing.T) {
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithStats(app.NewStatsService(&mockWeightRepo{}, &mockWaterRepo{})).
+		Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/stats?days=10&unit=kg")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body := decodeBody(t, resp)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+	}
+	if body["days"] != 10.0 || body["unit"] != "kg" {
+		t.Fatalf("unexpected range: %v", body)
+	}
+	weight, _ := body["weight"].(map[string]any)
+	if weight["count"] != 1.0 || weight["mean"] != 80.0 {
+		t.Errorf("expected a single 80 kg weigh-in, got %v", weight)
+	}
+	water, _ := body["water"].(map[string]any)
+	if water["count"] != 10.0 || water["max"] != 2.5 || water["median"] != 0.0 {
+		t.Errorf("expected ten days of water with 2.5 L today, got %v", water)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/stats?from=2024-02-01&to=2024-01-01")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a reversed range, got %d", resp.StatusCode)
+	}
+}
+
+func TestWaterHeatmap(t *testing.T) {
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithAnalytics(app.NewAnalyticsService(&mockWeightRepo{}, &mockWaterRepo{})).
+		Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/analytics/water-heatmap?days=28")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body := decodeBody(t, resp)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+	}
+	if body["days"] != 28.0 {
+		t.Fatalf("unexpected range: %v", body)
+	}
+	mean, _ := body["mean"].([]any)
+	monday, _ := mean[0].([]any)
+	if len(mean) != 7 || len(monday) != 24 || monday[8] != 0.125 {
+		t.Errorf("expected a 7x24 matrix with 0.125 L on Monday at 8, got %v", mean)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/analytics/water-heatmap?from=bad")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad date, got %d", resp.StatusCode)
+	}
+}
+
+func TestWaterWeightCorrelation(t *testing.T) {
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithAnalytics(app.NewAnalyticsService(&mockWeightRepo{}, &mockWaterRepo{})).
+		Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/analytics/water-weight?days=30&lag=1&unit=kg")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body := decodeBody(t, resp)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+	}
+	if body["lag"] != 1.0 || body["samples"] != 0.0 || body["coefficient"] != nil {
+		t.Errorf("expected no samples, got %v", body)
+	}
+	if points, ok := body["points"].([]any); !ok || len(points) != 0 {
+		t.Errorf("expected an empty points array, got %v", body["points"])
+	}
+
+	resp, err = http.Get(ts.URL + "/api/analytics/water-weight?lag=4")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a lag over 3 days, got %d", resp.StatusCode)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	mem := memory.New()
+	ctx := context.Background()
+	at := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	_, _ = mem.AddWeightEvent(ctx, 0, 80, "kg", domain.BodyComposition{}, at)
+	_, _ = mem.AddWaterEvent(ctx, 0, 0.5, at.Add(time.Hour))
+	_, _ = mem.AddWeightEvent(ctx, 1, 90, "kg", domain.BodyComposition{}, at)
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithExport(app.NewExportService(mem, mem)).
+		Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/export.csv?unit=kg&from=2024-03-01")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, b)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected a CSV content type, got %q", ct)
+	}
+	want := "type,id,timestamp,day,value,unit\n" +
+		"weight,1,2024-03-01T08:00:00Z,2024-03-01,80,kg\n" +
+		"water,1,2024-03-01T09:00:00Z,2024-03-01,0.5,L\n"
+	if string(b) != want {
+		t.Errorf("unexpected export:\n%s", b)
+	}
+
+	resp, err = http.Get(ts.URL + "/api/export.csv?unit=stone")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown unit, got %d", resp.StatusCode)
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	mem := memory.New()
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithImport(app.NewImportService(mem, mem, mem)).
+		Handler())
+	defer ts.Close()
+
+	const file = "When,Weight\n2024-03-01 07:30,180\n2024-03-02 07:30,179.5\n"
+	post := func(query string, body io.Reader, contentType string) (*http.Response, map[string]any) {
+		t.Helper()
+		resp, err := http.Post(ts.URL+"/api/import/csv?"+query, contentType, body)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		return resp, decodeBody(t, resp)
+	}
+
+	resp, body := post("date=When&defaultUnit=lb&dryRun=true", strings.NewReader(file), "text/csv")
+	if resp.StatusCode != http.StatusOK || body["weights"] != 2.0 || body["committed"] != false {
+		t.Fatalf("expected a dry run of two weigh-ins, got %d: %v", resp.StatusCode, body)
+	}
+	if events, _ := mem.ListWeightEvents(context.Background(), 0, domain.EventQuery{Limit: 10}, time.UTC); len(events) != 0 {
+		t.Fatalf("expected nothing stored by a dry run, got %d events", len(events))
+	}
+
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	part, _ := mw.CreateFormFile("file", "weights.csv")
+	_, _ = part.Write([]byte(file))
+	_ = mw.Close()
+	resp, body = post("date=When&defaultUnit=lb", &form, mw.FormDataContentType())
+	if resp.StatusCode != http.StatusOK || body["committed"] != true {
+		t.Fatalf("expected the upload to be stored, got %d: %v", resp.StatusCode, body)
+	}
+	if events, _ := mem.ListWeightEvents(context.Background(), 0, domain.EventQuery{Limit: 10}, time.UTC); len(events) != 2 {
+		t.Fatalf("expected two stored events, got %d", len(events))
+	}
+
+	resp, body = post("date=When", strings.NewReader(file), "text/csv")
+	if resp.StatusCode != http.StatusUnprocessableEntity || body["invalid"] != 2.0 {
+		t.Errorf("expected 422 for rows without a unit, got %d: %v", resp.StatusCode, body)
+	}
+	resp, _ = post("date=Datum", strings.NewReader(file), "text/csv")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown column, got %d", resp.StatusCode)
+	}
+	resp, _ = post("dryRun=maybe", strings.NewReader(file), "text/csv")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad flag, got %d", resp.StatusCode)
+	}
+
+	// 1795 lb is a mistyped 179.5 lb.
+	const typo = "When,Weight\n2024-03-03 07:30,1795\n"
+	resp, body = post("date=When&defaultUnit=lb", strings.NewReader(typo), "text/csv")
+	if resp.StatusCode != http.StatusUnprocessableEntity || body["invalid"] != 1.0 {
+		t.Errorf("expected 422 for an implausible weigh-in, got %d: %v", resp.StatusCode, body)
+	}
+	resp, body = post("date=When&defaultUnit=lb&confirm=true", strings.NewReader(typo), "text/csv")
+	if resp.StatusCode != http.StatusOK || body["committed"] != true {
+		t.Errorf("expected a confirmed weigh-in to be stored, got %d: %v", resp.StatusCode, body)
+	}
+
+	failing := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithImport(app.NewImportService(&mockImportRepo{err: errors.New("connection refused")}, mem, mem)).
+		Handler())
+	defer failing.Close()
+	resp, err := http.Post(failing.URL+"/api/import/csv?date=When&defaultUnit=lb", "text/csv", strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected 500 for a storage error, got %d", resp.StatusCode)
+	}
+}
+
+func TestImportAppleHealth(t *testing.T) {
+	mem := memory.New()
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithImport(app.NewImportService(mem, mem, mem)).
+		Handler())
+	defer ts.Close()
+
+	const export = `<?xml version="1.0" encoding="UTF-8"?>
+<HealthData locale="en_US">
+ <Record type="HKQuantityTypeIdentifierBodyMass" unit="kg" startDate="2024-03-01 07:30:00 +0100" value="80.2"/>
+ <Record type="HKQuantityTypeIdentifierDietaryWater" unit="mL" startDate="2024-03-01 09:00:00 +0100" value="330"/>
+</HealthData>`
+	post := func() map[string]any {
+		t.Helper()
+		var form bytes.Buffer
+		mw := multipart.NewWriter(&form)
+		_ = mw.WriteField("note", "ignored")
+		part, _ := mw.CreateFormFile("file", "export.xml")
+		_, _ = part.Write([]byte(export))
+		_ = mw.Close()
+		resp, err := http.Post(ts.URL+"/api/import/apple-health", mw.FormDataContentType(), &form)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		body := decodeBody(t, resp)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+		}
+		return body
+	}
+
+	if body := post(); body["weights"] != 1.0 || body["water"] != 1.0 || body["committed"] != true {
+		t.Fatalf("expected both records imported, got %v", body)
+	}
+	if body := post(); body["duplicates"] != 2.0 || body["committed"] != false {
+		t.Fatalf("expected a repeated import to find only duplicates, got %v", body)
+	}
+	if total, _ := mem.WaterTotalForLocalDay(context.Background(), 0, "2024-03-01", time.UTC); total != 0.33 {
+		t.Errorf("expected 0.33 L stored once, got %v", total)
+	}
+
+	resp, err := http.Post(ts.URL+"/api/import/apple-health", "application/xml", strings.NewReader("<nope/>"))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a file that is not a Health export, got %d", resp.StatusCode)
+	}
+
+	limited := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithImport(app.NewImportService(mem, mem, mem)).
+		WithHealthImportLimit(int64(len(export) - 1)).
+		Handler())
+	defer limited.Close()
+	if resp, err = http.Post(limited.URL+"/api/import/apple-health", "application/xml", strings.NewReader(export)); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body := decodeBody(t, resp)
+	_ = resp.Body.Close()
+	if msg, _ := body["error"].(string); resp.StatusCode != http.StatusBadRequest || !strings.Contains(msg, "too large") {
+		t.Errorf("expected 400 for an export over the limit, got %d: %v", resp.StatusCode, body)
+	}
+}
+
+func TestImportGoogleHealth(t *testing.T) {
+	mem := memory.New()
+	ts := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithImport(app.NewImportService(mem, mem, mem)).
+		Handler())
+	defer ts.Close()
+
+	post := func(path, body string) (*http.Response, map[string]any) {
+		t.Helper()
+		resp, err := http.Post(ts.URL+path, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		return resp, decodeBody(t, resp)
+	}
+
+	const fit = `{"Data Points": [
+		{"fitValue": [{"value": {"fpVal": 0.5}}], "dataTypeName": "com.google.hydration", "startTimeNanos": 1709290800000000000}
+	]}`
+	const healthConnect = `{"WeightRecord": [{"time": "2024-03-01T07:30:00Z", "weight": {"inKilograms": 80.5}}]}`
+
+	resp, body := post("/api/import/google-health?dryRun=true", fit)
+	if resp.StatusCode != http.StatusOK || body["water"] != 1.0 || body["committed"] != false {
+		t.Fatalf("expected a dry run to find one water event, got %d: %v", resp.StatusCode, body)
+	}
+	if _, body = post("/api/import/google-health", fit); body["committed"] != true {
+		t.Fatalf("expected the Fit file to be stored, got %v", body)
+	}
+	if _, body = post("/api/import/google-health", healthConnect); body["weights"] != 1.0 || body["committed"] != true {
+		t.Fatalf("expected the Health Connect weight to be stored, got %v", body)
+	}
+	if _, body = post("/api/import/google-health", fit); body["duplicates"] != 1.0 || body["committed"] != false {
+		t.Fatalf("expected a repeated import to find only duplicates, got %v", body)
+	}
+	if total, _ := mem.WaterTotalForLocalDay(context.Background(), 0, "2024-03-01", time.UTC); total != 0.5 {
+		t.Errorf("expected 0.5 L stored once, got %v", total)
+	}
+
+	if resp, _ := post("/api/import/google-health", `{"steps": []}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for a file that is not a Google export, got %d", resp.StatusCode)
+	}
+}
+
+func TestAccountTakeout(t *testing.T) {
+	// The archive of one instance is restored into another.
+	source, target := memory.New(), memory.New()
+	ctx := context.Background()
+	at := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	_, _ = source.AddWeightEvent(ctx, 0, 80, "kg", domain.BodyComposition{}, at)
+	_, _ = source.AddWeightEvent(ctx, 0, 79.5, "kg", domain.BodyComposition{}, at.AddDate(0, 0, 1))
+	_, _ = source.AddWaterEvent(ctx, 0, 0.5, at)
+	_, _ = target.AddWeightEvent(ctx, 0, 80, "kg", domain.BodyComposition{}, at)
+
+	newServer := func(mem *memory.DB) *httptest.Server {
+		return httptest.NewServer(newTestAPI(t, nil, nil).
+			WithAccount(app.NewAccountService(mem, mem, mem)).
+			Handler())
+	}
+	src, dst := newServer(source), newServer(target)
+	defer src.Close()
+	defer dst.Close()
+
+	resp, err := http.Get(src.URL + "/api/account/export")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	archive, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, archive)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); !strings.Contains(cd, "vitals-takeout-") {
+		t.Errorf("expected an attachment, got %q", cd)
+	}
+
+	restore := func(mode string, body []byte) (*http.Response, map[string]any) {
+		t.Helper()
+		resp, err := http.Post(dst.URL+"/api/account/import?mode="+mode, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close() //nolint:errcheck
+		return resp, decodeBody(t, resp)
+	}
+
+	resp, body := restore("merge", archive)
+	if resp.StatusCode != http.StatusOK || body["weights"] != 1.0 || body["water"] != 1.0 || body["duplicates"] != 1.0 {
+		t.Fatalf("expected the new events merged, got %d: %v", resp.StatusCode, body)
+	}
+	if events, _ := target.ListWeightEvents(ctx, 0, domain.EventQuery{Limit: 10}, time.UTC); len(events) != 2 {
+		t.Errorf("expected two weigh-ins after the merge, got %d", len(events))
+	}
+
+	// The development user has no stored profile to restore.
+	resp, body = restore("replace", archive)
+	if resp.StatusCode != http.StatusOK || body["weights"] != 2.0 || body["profileRestored"] != false {
+		t.Fatalf("expected the events replaced, got %d: %v", resp.StatusCode, body)
+	}
+	if events, _ := target.ListWeightEvents(ctx, 0, domain.EventQuery{Limit: 10}, time.UTC); len(events) != 2 {
+		t.Errorf("expected two weigh-ins after the replace, got %d", len(events))
+	}
+
+	resp, body = restore("merge", []byte(`{"format":"vitals-takeout","version":99,"sleepSessions":[]}`))
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(body["error"].(string), "version 99") {
+		t.Errorf("expected 400 for a newer archive, got %d: %v", resp.StatusCode, body)
+	}
+
+	failing := httptest.NewServer(newTestAPI(t, nil, nil).
+		WithAccount(app.NewAccountService(target, target, &mockImportRepo{err: errors.New("connection refused")})).
+		Handler())
+	defer failing.Close()
+	resp, err = http.Post(failing.URL+"/api/account/import?mode=replace", "application/json", bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected 500 for a storage error, got %d", resp.StatusCode)
+	}
+}
+
+func TestWeightOutliers(t *testing.T) {
+	mem := memory.New()
+	ts := httptest.NewServer(adapthttp.New(app.NewWeightService(mem), app.NewWaterService(mem),
+		app.NewChartsService(mem, mem), app.NewAuthService(&mockUserRepo{}, &mockSessionRepo{}), t.TempDir()).
+		WithoutAuth().
+		Handler())
+	defer ts.Close()
+
+	put := func(payload map[string]any) *http.Response {
+		t.Helper()
+		b, _ := json.Marshal(payload)
+		req, _ := http.NewRequest(http.MethodPut, ts.URL+"/api/weight/today", bytes.NewReader(b))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	if resp := put(map[string]any{"value": 180.0, "unit": "lb", "at": time.Now().Add(-2 * time.Hour)}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if resp := put(map[string]any{"value": 1800.0, "unit": "lb"}); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected 409 for a typo, got %d", resp.StatusCode)
+	}
+	if resp := put(map[string]any{"value": 1800.0, "unit": "lb", "confirm": true}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 when confirmed, got %d", resp.StatusCode)
+	}
+
+	resp, err := http.Get(ts.URL + "/api/weight/outliers")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body := decodeBody(t, resp)
+	_ = resp.Body.Close()
+	items, _ := body["items"].([]any)
+	if len(items) != 1 {
+		t.Fatalf("expected one outlier, got %v", body["items"])
+	}
+	item, _ := items[0].(map[string]any)
+	entry, _ := item["entry"].(map[string]any)
+	if entry["value"] != 1800.0 || item["reference"] != 180.0 {
+		t.Fatalf("unexpected outlier %v", item)
+	}
+}
@@ -3,6 +3,8 @@ package adapthttp
 import (
 	"net/http"
 	"time"
+
+	"vitals/internal/app"
 )
 
 func (s *Server) handleWaterToday(w http.ResponseWriter, r *http.Request) {
@@ -11,13 +13,27 @@ func (s *Server) handleWaterToday(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := userFromContext(r)
-	today := localDayString(time.Now())
-	total, err := s.water.GetTodayTotal(r.Context(), user.ID, today)
+	loc := user.Location()
+	today := localDayString(time.Now(), loc)
+	goal := user.WaterGoal()
+	progress, err := s.water.GetTodayProgress(r.Context(), user.ID, today, goal, loc)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	streak, err := s.water.GetStreaks(r.Context(), user.ID, today, goal, loc)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"today": today, "totalLiters": total})
+	writeJSON(w, http.StatusOK, map[string]any{
+		"today":           today,
+		"totalLiters":     progress.TotalLiters,
+		"goalLiters":      progress.GoalLiters,
+		"percent":         progress.Percent,
+		"remainingLiters": progress.RemainingLiters,
+		"streak":          streak,
+	})
 }
 
 func (s *Server) handleWaterEvent(w http.ResponseWriter, r *http.Request) {
@@ -27,13 +43,16 @@ func (s *Server) handleWaterEvent(w http.ResponseWriter, r *http.Request) {
 	}
 	user := userFromContext(r)
 	var body struct {
-		DeltaLiters float64 `json:"deltaLiters"`
+		DeltaLiters float64    `json:"deltaLiters"`
+		At          *time.Time `json:"at"`
+		Day         string     `json:"day"`
 	}
 	if err := parseJSON(r, &body); err != nil {
 		writeError(w, http.StatusBadRequest, err)
 		return
 	}
-	id, err := s.water.RecordEvent(r.Context(), user.ID, body.DeltaLiters)
+	at := app.EntryTime{At: body.At, Day: body.Day}
+	id, err := s.water.RecordEvent(r.Context(), user.ID, body.DeltaLiters, at, user.Location())
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err)
 		return
@@ -47,13 +66,12 @@ func (s *Server) handleWaterRecent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := userFromContext(r)
-	limit := intQuery(r, "limit", 20)
-	items, err := s.water.ListRecent(r.Context(), user.ID, limit)
+	items, next, err := s.water.ListRecent(r.Context(), user.ID, listOptions(r, 20), user.Location())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
+		writeListError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+	writeJSON(w, http.StatusOK, pageJSON(items, next))
 }
 
 func (s *Server) handleWaterUndoLast(w http.ResponseWriter, r *http.Request) {
@@ -69,3 +87,50 @@ func (s *Server) handleWaterUndoLast(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"undone": undone, "id": id})
 }
+
+func (s *Server) handleWaterEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := userFromContext(r)
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		event, err := s.water.GetEvent(ctx, user.ID, id)
+		if err != nil {
+			writeEntryError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"event": event})
+
+	case http.MethodPatch:
+		var body struct {
+			DeltaLiters *float64   `json:"deltaLiters"`
+			At          *time.Time `json:"at"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		patch := app.WaterPatch{DeltaLiters: body.DeltaLiters, At: body.At}
+		event, err := s.water.UpdateEvent(ctx, user.ID, id, patch)
+		if err != nil {
+			writeEntryError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"event": event})
+
+	case http.MethodDelete:
+		if err := s.water.DeleteEvent(ctx, user.ID, id); err != nil {
+			writeEntryError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "deleted": true, "id": id})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -1,39 +1,54 @@
 package adapthttp
 
 import (
+	"errors"
 	"net/http"
 	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
 )
 
 func (s *Server) handleWeightToday(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	user := userFromContext(r)
-	today := localDayString(time.Now())
+	loc := user.Location()
+	today := localDayString(time.Now(), loc)
 
 	switch r.Method {
 	case http.MethodGet:
-		entry, err := s.weight.GetTodayWeight(ctx, user.ID, today)
+		policy := weightPolicy(r, user)
+		entry, err := s.weight.GetTodayWeight(ctx, user.ID, today, policy, loc)
+		if errors.Is(err, app.ErrInvalidWeightPolicy) {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"today": today, "entry": entry})
+		writeJSON(w, http.StatusOK, map[string]any{"today": today, "policy": policy, "entry": entry})
 
 	case http.MethodPut:
 		var body struct {
 			Value float64 `json:"value"`
 			Unit  string  `json:"unit"`
+			domain.BodyComposition
+			At      *time.Time `json:"at"`
+			Day     string     `json:"day"`
+			Confirm bool       `json:"confirm"`
 		}
 		if err := parseJSON(r, &body); err != nil {
 			writeError(w, http.StatusBadRequest, err)
 			return
 		}
-		entry, _, err := s.weight.RecordWeight(ctx, user.ID, body.Value, body.Unit)
+		at := app.EntryTime{At: body.At, Day: body.Day}
+		entry, day, err := s.weight.RecordWeight(ctx, user.ID, body.Value, body.Unit, body.BodyComposition, at, body.Confirm, loc)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, err)
+			writeEntryError(w, http.StatusBadRequest, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"today": today, "entry": entry})
+		writeJSON(w, http.StatusOK, map[string]any{"today": today, "day": day, "entry": entry})
 
 	default:
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -46,13 +61,12 @@ func (s *Server) handleWeightRecent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := userFromContext(r)
-	limit := intQuery(r, "limit", 14)
-	items, err := s.weight.ListRecent(r.Context(), user.ID, limit)
+	items, next, err := s.weight.ListRecent(r.Context(), user.ID, listOptions(r, 14), user.Location())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
+		writeListError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+	writeJSON(w, http.StatusOK, pageJSON(items, next))
 }
 
 func (s *Server) handleWeightUndoLast(w http.ResponseWriter, r *http.Request) {
@@ -61,10 +75,84 @@ func (s *Server) handleWeightUndoLast(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	user := userFromContext(r)
-	deleted, entry, today, err := s.weight.UndoLast(r.Context(), user.ID)
+	deleted, entry, today, err := s.weight.UndoLast(r.Context(), user.ID, user.WeightPolicy(), user.Location())
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "deleted": deleted, "today": today, "entry": entry})
 }
+
+func (s *Server) handleWeightOutliers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	user := userFromContext(r)
+	outliers, err := s.weight.FindOutliers(r.Context(), user.ID, user.Location())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"items": outliers})
+}
+
+func (s *Server) handleWeightEntry(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := userFromContext(r)
+	loc := user.Location()
+	id, err := pathID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		entry, err := s.weight.GetEntry(ctx, user.ID, id, loc)
+		if err != nil {
+			writeEntryError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"entry": entry})
+
+	case http.MethodPatch:
+		var body struct {
+			Value *float64 `json:"value"`
+			Unit  *string  `json:"unit"`
+			domain.BodyComposition
+			At      *time.Time `json:"at"`
+			Confirm bool       `json:"confirm"`
+		}
+		if err := parseJSON(r, &body); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		patch := app.WeightPatch{Value: body.Value, Unit: body.Unit, At: body.At, Composition: body.BodyComposition, Confirm: body.Confirm}
+		entry, err := s.weight.UpdateEntry(ctx, user.ID, id, patch, loc)
+		if err != nil {
+			writeEntryError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"entry": entry})
+
+	case http.MethodDelete:
+		if err := s.weight.DeleteEntry(ctx, user.ID, id); err != nil {
+			writeEntryError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "deleted": true, "id": id})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// weightPolicy returns the daily weight policy selected by the policy query
+// parameter, falling back to the user's preference.
+func weightPolicy(r *http.Request, user *domain.User) domain.WeightPolicy {
+	if p := r.URL.Query().Get("policy"); p != "" {
+		return domain.WeightPolicy(p)
+	}
+	return user.WeightPolicy()
+}
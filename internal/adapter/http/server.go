@@ -23,18 +23,31 @@ type OIDCConfig struct {
 // Server is the driving HTTP adapter that routes requests to application
 // services.
 type Server struct {
-	weight      *app.WeightService
-	water       *app.WaterService
-	charts      *app.ChartsService
-	authSvc     *app.AuthService
-	webDir      string
-	disableAuth bool
-	oidcConfig  OIDCConfig
+	weight       *app.WeightService
+	water        *app.WaterService
+	charts       *app.ChartsService
+	authSvc      *app.AuthService
+	profile      *app.ProfileService
+	bp           *app.BloodPressureService
+	sleep        *app.SleepService
+	measurements *app.MeasurementService
+	goals        *app.GoalService
+	stats        *app.StatsService
+	analytics    *app.AnalyticsService
+	export       *app.ExportService
+	imports      *app.ImportService
+	account      *app.AccountService
+	webDir       string
+	disableAuth  bool
+	oidcConfig   OIDCConfig
+
+	// healthImportBytes limits the size of uploaded Apple Health exports.
+	healthImportBytes int64
 }
 
 // New creates a Server wired to the given application services.
 func New(ws *app.WeightService, wa *app.WaterService, cs *app.ChartsService, as *app.AuthService, webDir string) *Server {
-	s := &Server{weight: ws, water: wa, charts: cs, authSvc: as, webDir: webDir, disableAuth: false}
+	s := &Server{weight: ws, water: wa, charts: cs, authSvc: as, webDir: webDir, disableAuth: false, healthImportBytes: DefaultHealthImportBytes}
 
 	// Initialize OIDC (SSO) if configured
 	if issuer := os.Getenv("SSO_ISSUER_URL"); issuer != "" {
@@ -72,6 +85,74 @@ func (s *Server) WithoutAuth() *Server {
 	return s
 }
 
+// WithProfile enables the profile endpoints backed by ps.
+func (s *Server) WithProfile(ps *app.ProfileService) *Server {
+	s.profile = ps
+	return s
+}
+
+// WithBloodPressure enables the blood pressure endpoints backed by bs.
+func (s *Server) WithBloodPressure(bs *app.BloodPressureService) *Server {
+	s.bp = bs
+	return s
+}
+
+// WithSleep enables the sleep endpoints backed by ss.
+func (s *Server) WithSleep(ss *app.SleepService) *Server {
+	s.sleep = ss
+	return s
+}
+
+// WithMeasurements enables the body measurement endpoints backed by ms. The
+// charts service must have measurements enabled as well.
+func (s *Server) WithMeasurements(ms *app.MeasurementService) *Server {
+	s.measurements = ms
+	return s
+}
+
+// WithGoals enables the goal endpoints backed by gs.
+func (s *Server) WithGoals(gs *app.GoalService) *Server {
+	s.goals = gs
+	return s
+}
+
+// WithStats enables the statistics endpoint backed by ss.
+func (s *Server) WithStats(ss *app.StatsService) *Server {
+	s.stats = ss
+	return s
+}
+
+// WithAnalytics enables the analytics endpoints backed by as.
+func (s *Server) WithAnalytics(as *app.AnalyticsService) *Server {
+	s.analytics = as
+	return s
+}
+
+// WithExport enables the data export endpoint backed by es.
+func (s *Server) WithExport(es *app.ExportService) *Server {
+	s.export = es
+	return s
+}
+
+// WithImport enables the data import endpoints backed by is.
+func (s *Server) WithImport(is *app.ImportService) *Server {
+	s.imports = is
+	return s
+}
+
+// WithHealthImportLimit sets the largest Apple Health export accepted, in
+// bytes, in place of DefaultHealthImportBytes.
+func (s *Server) WithHealthImportLimit(n int64) *Server {
+	s.healthImportBytes = n
+	return s
+}
+
+// WithAccount enables the account export and restore endpoints backed by as.
+func (s *Server) WithAccount(as *app.AccountService) *Server {
+	s.account = as
+	return s
+}
+
 // Handler returns the root http.Handler for the application.
 func (s *Server) Handler() http.Handler {
 	api := http.NewServeMux()
@@ -91,13 +172,73 @@ func (s *Server) Handler() http.Handler {
 	api.Handle("/weight/today", s.authMiddleware(http.HandlerFunc(s.handleWeightToday)))
 	api.Handle("/weight/recent", s.authMiddleware(http.HandlerFunc(s.handleWeightRecent)))
 	api.Handle("/weight/undo-last", s.authMiddleware(http.HandlerFunc(s.handleWeightUndoLast)))
+	api.Handle("/weight/outliers", s.authMiddleware(http.HandlerFunc(s.handleWeightOutliers)))
+	api.Handle("/weight/{id}", s.authMiddleware(http.HandlerFunc(s.handleWeightEntry)))
 
 	api.Handle("/water/today", s.authMiddleware(http.HandlerFunc(s.handleWaterToday)))
 	api.Handle("/water/event", s.authMiddleware(http.HandlerFunc(s.handleWaterEvent)))
 	api.Handle("/water/recent", s.authMiddleware(http.HandlerFunc(s.handleWaterRecent)))
 	api.Handle("/water/undo-last", s.authMiddleware(http.HandlerFunc(s.handleWaterUndoLast)))
+	api.Handle("/water/{id}", s.authMiddleware(http.HandlerFunc(s.handleWaterEntry)))
+
+	if s.bp != nil {
+		api.Handle("/bp/today", s.authMiddleware(http.HandlerFunc(s.handleBloodPressureToday)))
+		api.Handle("/bp/recent", s.authMiddleware(http.HandlerFunc(s.handleBloodPressureRecent)))
+		api.Handle("/bp/undo-last", s.authMiddleware(http.HandlerFunc(s.handleBloodPressureUndoLast)))
+		api.Handle("/bp/{id}", s.authMiddleware(http.HandlerFunc(s.handleBloodPressureEntry)))
+	}
+
+	if s.sleep != nil {
+		api.Handle("/sleep/today", s.authMiddleware(http.HandlerFunc(s.handleSleepToday)))
+		api.Handle("/sleep/session", s.authMiddleware(http.HandlerFunc(s.handleSleepSession)))
+		api.Handle("/sleep/recent", s.authMiddleware(http.HandlerFunc(s.handleSleepRecent)))
+		api.Handle("/sleep/undo-last", s.authMiddleware(http.HandlerFunc(s.handleSleepUndoLast)))
+		api.Handle("/sleep/{id}", s.authMiddleware(http.HandlerFunc(s.handleSleepEntry)))
+	}
+
+	if s.measurements != nil {
+		api.Handle("/measurements/today", s.authMiddleware(http.HandlerFunc(s.handleMeasurementsToday)))
+		api.Handle("/measurements/recent", s.authMiddleware(http.HandlerFunc(s.handleMeasurementsRecent)))
+		api.Handle("/measurements/undo-last", s.authMiddleware(http.HandlerFunc(s.handleMeasurementsUndoLast)))
+		api.Handle("/measurements/{id}", s.authMiddleware(http.HandlerFunc(s.handleMeasurementEntry)))
+		api.Handle("/charts/measurements", s.authMiddleware(http.HandlerFunc(s.handleChartsMeasurements)))
+	}
+
+	if s.goals != nil {
+		api.Handle("/goals", s.authMiddleware(http.HandlerFunc(s.handleGoals)))
+		api.Handle("/goals/weight", s.authMiddleware(http.HandlerFunc(s.handleWeightGoal)))
+	}
 
 	api.Handle("/charts/daily", s.authMiddleware(http.HandlerFunc(s.handleChartsDaily)))
+	api.Handle("/charts/calendar", s.authMiddleware(http.HandlerFunc(s.handleChartsCalendar)))
+
+	if s.stats != nil {
+		api.Handle("/stats", s.authMiddleware(http.HandlerFunc(s.handleStats)))
+	}
+
+	if s.analytics != nil {
+		api.Handle("/analytics/water-heatmap", s.authMiddleware(http.HandlerFunc(s.handleWaterHeatmap)))
+		api.Handle("/analytics/water-weight", s.authMiddleware(http.HandlerFunc(s.handleWaterWeightCorrelation)))
+	}
+
+	if s.export != nil {
+		api.Handle("/export.csv", s.authMiddleware(http.HandlerFunc(s.handleExportCSV)))
+	}
+
+	if s.imports != nil {
+		api.Handle("/import/csv", s.authMiddleware(http.HandlerFunc(s.handleImportCSV)))
+		api.Handle("/import/apple-health", s.authMiddleware(http.HandlerFunc(s.handleImportAppleHealth)))
+		api.Handle("/import/google-health", s.authMiddleware(http.HandlerFunc(s.handleImportGoogleHealth)))
+	}
+
+	if s.account != nil {
+		api.Handle("/account/export", s.authMiddleware(http.HandlerFunc(s.handleAccountExport)))
+		api.Handle("/account/import", s.authMiddleware(http.HandlerFunc(s.handleAccountImport)))
+	}
+
+	if s.profile != nil {
+		api.Handle("/profile", s.authMiddleware(http.HandlerFunc(s.handleProfile)))
+	}
 
 	root := http.NewServeMux()
 	root.Handle("/api/", http.StripPrefix("/api", api))
@@ -2,12 +2,15 @@ package adapthttp
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path"
 	"strconv"
 	"time"
+
+	"vitals/internal/app"
 )
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
@@ -41,8 +44,88 @@ func intQuery(r *http.Request, key string, fallback int) int {
 	return n
 }
 
-func localDayString(t time.Time) string {
-	return t.In(time.Local).Format("2006-01-02")
+// floatQuery reads a float query parameter, returning fallback if it is
+// absent.
+func floatQuery(r *http.Request, key string, fallback float64) (float64, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return fallback, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", key)
+	}
+	return f, nil
+}
+
+// boolQuery reads a boolean query parameter, returning false if it is
+// absent.
+func boolQuery(r *http.Request, key string) (bool, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", key)
+	}
+	return b, nil
+}
+
+// writeListError maps invalid list options to 400 and failures to read the
+// list to 500.
+func writeListError(w http.ResponseWriter, err error) {
+	if errors.Is(err, app.ErrInvalidListOptions) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+// listOptions reads the from, to, cursor and limit query parameters.
+func listOptions(r *http.Request, defaultLimit int) app.ListOptions {
+	q := r.URL.Query()
+	return app.ListOptions{
+		From:   q.Get("from"),
+		To:     q.Get("to"),
+		Cursor: q.Get("cursor"),
+		Limit:  intQuery(r, "limit", defaultLimit),
+	}
+}
+
+// pageJSON renders a page of items with its next cursor, which is null on
+// the last page.
+func pageJSON(items any, next string) map[string]any {
+	var cursor any
+	if next != "" {
+		cursor = next
+	}
+	return map[string]any{"items": items, "nextCursor": cursor}
+}
+
+func pathID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid id")
+	}
+	return id, nil
+}
+
+// writeEntryError maps app.ErrEntryNotFound and app.ErrNoGoal to 404,
+// app.ErrSleepOverlap and app.ErrImplausibleWeight to 409 and anything else to fallback.
+func writeEntryError(w http.ResponseWriter, fallback int, err error) {
+	switch {
+	case errors.Is(err, app.ErrEntryNotFound), errors.Is(err, app.ErrNoGoal):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, app.ErrSleepOverlap), errors.Is(err, app.ErrImplausibleWeight):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, fallback, err)
+	}
+}
+
+func localDayString(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02")
 }
 
 func withNoCache(next http.Handler) http.Handler {
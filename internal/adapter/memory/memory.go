@@ -4,6 +4,8 @@ package memory
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -16,31 +18,44 @@ type DB struct {
 	mu          sync.Mutex
 	weights     []domain.WeightEntry
 	waterEvents []domain.WaterEvent
+	bpReadings  []domain.BloodPressureReading
+	sleeps      []domain.SleepSession
+	measures    []domain.Measurement
 	users       []*domain.User
 	sessions    map[string]*domain.Session
+	goals       map[int64]domain.WeightGoal
 
-	weightIDCounter int64
-	waterIDCounter  int64
-	userIDCounter   int64
+	weightIDCounter  int64
+	waterIDCounter   int64
+	bpIDCounter      int64
+	sleepIDCounter   int64
+	measureIDCounter int64
+	userIDCounter    int64
 }
 
 // New creates a new in-memory database.
 func New() *DB {
 	return &DB{
 		sessions: make(map[string]*domain.Session),
+		goals:    make(map[int64]domain.WeightGoal),
 	}
 }
 
 // Ensure interfaces are met.
 var _ domain.WeightRepository = (*DB)(nil)
 var _ domain.WaterRepository = (*DB)(nil)
+var _ domain.BloodPressureRepository = (*DB)(nil)
+var _ domain.SleepRepository = (*DB)(nil)
+var _ domain.MeasurementRepository = (*DB)(nil)
+var _ domain.WeightGoalRepository = (*DB)(nil)
 var _ domain.UserRepository = (*DB)(nil)
+var _ domain.ImportRepository = (*DB)(nil)
 var _ domain.SessionRepository = (*SessionRepo)(nil)
 
 // --- WeightRepository ---
 
 // AddWeightEvent adds a weight event.
-func (db *DB) AddWeightEvent(ctx context.Context, userID int64, value float64, unit string, createdAt time.Time) (int64, error) {
+func (db *DB) AddWeightEvent(ctx context.Context, userID int64, value float64, unit string, comp domain.BodyComposition, createdAt time.Time) (int64, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
@@ -48,17 +63,19 @@ func (db *DB) AddWeightEvent(ctx context.Context, userID int64, value float64, u
 	id := db.weightIDCounter
 
 	entry := domain.WeightEntry{
-		ID:        id,
-		UserID:    userID,
-		Value:     value,
-		Unit:      unit,
-		CreatedAt: createdAt.UTC(),
+		ID:              id,
+		UserID:          userID,
+		Value:           value,
+		Unit:            unit,
+		BodyComposition: comp,
+		CreatedAt:       createdAt.UTC(),
 	}
 	db.weights = append(db.weights, entry)
 	return id, nil
 }
 
-// DeleteLatestWeightEvent deletes the most recent weight event for a user.
+// DeleteLatestWeightEvent deletes the most recently logged weight event for a
+// user.
 func (db *DB) DeleteLatestWeightEvent(ctx context.Context, userID int64) (bool, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
@@ -67,17 +84,14 @@ func (db *DB) DeleteLatestWeightEvent(ctx context.Context, userID int64) (bool,
 		return false, nil
 	}
 
-	// Find index of latest created_at for this user
+	// Find index of the highest ID for this user
 	lastIdx := -1
-	var lastTime time.Time
-
 	for i, w := range db.weights {
 		if w.UserID != userID {
 			continue
 		}
-		if lastIdx == -1 || w.CreatedAt.After(lastTime) {
+		if lastIdx == -1 || w.ID > db.weights[lastIdx].ID {
 			lastIdx = i
-			lastTime = w.CreatedAt
 		}
 	}
 
@@ -89,43 +103,69 @@ func (db *DB) DeleteLatestWeightEvent(ctx context.Context, userID int64) (bool,
 	return false, nil
 }
 
-// LatestWeightForLocalDay returns the latest weight for the given day for a user.
-func (db *DB) LatestWeightForLocalDay(ctx context.Context, userID int64, localDay string) (*domain.WeightEntry, error) {
+// GetWeightEvent returns a weight event by ID, scoped to a user.
+func (db *DB) GetWeightEvent(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.WeightEntry, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	dayStart, err := time.ParseInLocation("2006-01-02", localDay, time.Local)
-	if err != nil {
-		return nil, err
+	for _, w := range db.weights {
+		if w.ID == id && w.UserID == userID {
+			w.Day = w.CreatedAt.In(loc).Format("2006-01-02")
+			return &w, nil
+		}
 	}
-	dayEnd := dayStart.Add(24 * time.Hour)
+	return nil, nil
+}
 
-	var latest *domain.WeightEntry
+// UpdateWeightEvent replaces a weight event's value, unit, composition and
+// timestamp, scoped to a user.
+func (db *DB) UpdateWeightEvent(ctx context.Context, userID int64, id int64, value float64, unit string, comp domain.BodyComposition, createdAt time.Time) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
 	for i := range db.weights {
 		w := &db.weights[i]
-		if w.UserID != userID {
-			continue
+		if w.ID == id && w.UserID == userID {
+			w.Value = value
+			w.Unit = unit
+			w.BodyComposition = comp
+			w.CreatedAt = createdAt.UTC()
+			return true, nil
 		}
-		// Compare using UTC as that's how it's stored and Postgres does comparison
-		if !w.CreatedAt.Before(dayStart.UTC()) && w.CreatedAt.Before(dayEnd.UTC()) {
-			if latest == nil || w.CreatedAt.After(latest.CreatedAt) {
-				latest = w
-			}
+	}
+	return false, nil
+}
+
+// DeleteWeightEvent deletes a weight event by ID, scoped to a user.
+func (db *DB) DeleteWeightEvent(ctx context.Context, userID int64, id int64) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i, w := range db.weights {
+		if w.ID == id && w.UserID == userID {
+			db.weights = append(db.weights[:i], db.weights[i+1:]...)
+			return true, nil
 		}
 	}
+	return false, nil
+}
 
-	if latest != nil {
-		// we return a copy with Day set
-		ret := *latest
-		ret.Day = localDay
-		return &ret, nil
+// WeightForLocalDay returns the weight representing the given day for a user
+// under policy.
+func (db *DB) WeightForLocalDay(ctx context.Context, userID int64, localDay string, policy domain.WeightPolicy, loc *time.Location) (*domain.WeightEntry, error) {
+	days, err := db.WeightsForLocalDays(ctx, userID, localDay, localDay, policy, loc)
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	e, ok := days[localDay]
+	if !ok {
+		return nil, nil
+	}
+	return &e, nil
 }
 
 // ListRecentWeightEvents lists the most recent weight events for a user.
-func (db *DB) ListRecentWeightEvents(ctx context.Context, userID int64, limit int) ([]domain.WeightEntry, error) {
+func (db *DB) ListRecentWeightEvents(ctx context.Context, userID int64, limit int, loc *time.Location) ([]domain.WeightEntry, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
@@ -146,14 +186,67 @@ func (db *DB) ListRecentWeightEvents(ctx context.Context, userID int64, limit in
 		filtered = filtered[:limit]
 	}
 
-	// Populate Day field based on CreatedAt in Local time
+	// Populate Day field based on CreatedAt in the caller's time zone
 	for i := range filtered {
-		filtered[i].Day = filtered[i].CreatedAt.In(time.Local).Format("2006-01-02")
+		filtered[i].Day = filtered[i].CreatedAt.In(loc).Format("2006-01-02")
 	}
 
 	return filtered, nil
 }
 
+// ListWeightEvents lists the weight events matching q for a user, newest first.
+func (db *DB) ListWeightEvents(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.WeightEntry, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var filtered []domain.WeightEntry
+	for _, w := range db.weights {
+		if w.UserID == userID && matchesEventQuery(q, w.CreatedAt, w.ID) {
+			filtered = append(filtered, w)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return newerEvent(filtered[i].CreatedAt, filtered[i].ID, filtered[j].CreatedAt, filtered[j].ID)
+	})
+	if len(filtered) > q.Limit {
+		filtered = filtered[:q.Limit]
+	}
+	for i := range filtered {
+		filtered[i].Day = filtered[i].CreatedAt.In(loc).Format("2006-01-02")
+	}
+	return filtered, nil
+}
+
+// WeightsForLocalDays returns the weight representing each local day under
+// policy in the inclusive range [fromDay, toDay] for a user.
+func (db *DB) WeightsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, policy domain.WeightPolicy, loc *time.Location) (map[string]domain.WeightEntry, error) {
+	if !policy.Valid() {
+		return nil, fmt.Errorf("unknown weight policy %q", policy)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	start, end, err := localDayRange(fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[string][]domain.WeightEntry)
+	for _, w := range db.weights {
+		if w.UserID != userID || w.CreatedAt.Before(start) || !w.CreatedAt.Before(end) {
+			continue
+		}
+		w.Day = w.CreatedAt.In(loc).Format("2006-01-02")
+		byDay[w.Day] = append(byDay[w.Day], w)
+	}
+	out := make(map[string]domain.WeightEntry, len(byDay))
+	for day, entries := range byDay {
+		out[day] = policy.Reduce(entries)
+	}
+	return out, nil
+}
+
 // --- WaterRepository ---
 
 // AddWaterEvent adds a water event.
@@ -175,20 +268,50 @@ func (db *DB) AddWaterEvent(ctx context.Context, userID int64, deltaLiters float
 }
 
 // DeleteWaterEvent deletes a water event by ID, scoped to a user.
-func (db *DB) DeleteWaterEvent(ctx context.Context, userID int64, id int64) error {
+func (db *DB) DeleteWaterEvent(ctx context.Context, userID int64, id int64) (bool, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
 	for i, w := range db.waterEvents {
 		if w.ID == id && w.UserID == userID {
 			db.waterEvents = append(db.waterEvents[:i], db.waterEvents[i+1:]...)
-			return nil
+			return true, nil
 		}
 	}
-	return nil
+	return false, nil
+}
+
+// GetWaterEvent returns a water event by ID, scoped to a user.
+func (db *DB) GetWaterEvent(ctx context.Context, userID int64, id int64) (*domain.WaterEvent, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, w := range db.waterEvents {
+		if w.ID == id && w.UserID == userID {
+			return &w, nil
+		}
+	}
+	return nil, nil
 }
 
-// ListRecentWaterEvents lists the most recent water events for a user.
+// UpdateWaterEvent replaces a water event's delta and timestamp, scoped to a user.
+func (db *DB) UpdateWaterEvent(ctx context.Context, userID int64, id int64, deltaLiters float64, createdAt time.Time) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i := range db.waterEvents {
+		w := &db.waterEvents[i]
+		if w.ID == id && w.UserID == userID {
+			w.DeltaLiters = deltaLiters
+			w.CreatedAt = createdAt.UTC()
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListRecentWaterEvents lists the most recently logged water events for a
+// user.
 func (db *DB) ListRecentWaterEvents(ctx context.Context, userID int64, limit int) ([]domain.WaterEvent, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
@@ -201,7 +324,7 @@ func (db *DB) ListRecentWaterEvents(ctx context.Context, userID int64, limit int
 	}
 
 	sort.Slice(filtered, func(i, j int) bool {
-		return filtered[i].CreatedAt.After(filtered[j].CreatedAt)
+		return filtered[i].ID > filtered[j].ID
 	})
 
 	if len(filtered) > limit {
@@ -210,16 +333,36 @@ func (db *DB) ListRecentWaterEvents(ctx context.Context, userID int64, limit int
 	return filtered, nil
 }
 
+// ListWaterEvents lists the water events matching q for a user, newest first.
+func (db *DB) ListWaterEvents(ctx context.Context, userID int64, q domain.EventQuery) ([]domain.WaterEvent, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var filtered []domain.WaterEvent
+	for _, w := range db.waterEvents {
+		if w.UserID == userID && matchesEventQuery(q, w.CreatedAt, w.ID) {
+			filtered = append(filtered, w)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return newerEvent(filtered[i].CreatedAt, filtered[i].ID, filtered[j].CreatedAt, filtered[j].ID)
+	})
+	if len(filtered) > q.Limit {
+		filtered = filtered[:q.Limit]
+	}
+	return filtered, nil
+}
+
 // WaterTotalForLocalDay returns the total water intake for the given day for a user.
-func (db *DB) WaterTotalForLocalDay(ctx context.Context, userID int64, localDay string) (float64, error) {
+func (db *DB) WaterTotalForLocalDay(ctx context.Context, userID int64, localDay string, loc *time.Location) (float64, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	dayStart, err := time.ParseInLocation("2006-01-02", localDay, time.Local)
+	dayStart, err := time.ParseInLocation("2006-01-02", localDay, loc)
 	if err != nil {
 		return 0, err
 	}
-	dayEnd := dayStart.Add(24 * time.Hour)
+	dayEnd := dayStart.AddDate(0, 0, 1)
 
 	var total float64
 	for _, w := range db.waterEvents {
@@ -233,6 +376,543 @@ func (db *DB) WaterTotalForLocalDay(ctx context.Context, userID int64, localDay
 	return total, nil
 }
 
+// WaterTotalsForLocalDays returns the total water intake per local day in the
+// inclusive range [fromDay, toDay] for a user.
+func (db *DB) WaterTotalsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	start, end, err := localDayRange(fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]float64)
+	for _, w := range db.waterEvents {
+		if w.UserID != userID || w.CreatedAt.Before(start) || !w.CreatedAt.Before(end) {
+			continue
+		}
+		out[w.CreatedAt.In(loc).Format("2006-01-02")] += w.DeltaLiters
+	}
+	return out, nil
+}
+
+// WaterTotalsByWeekdayHour returns the total water intake per local day of
+// the week and hour of the day in the inclusive range [fromDay, toDay] for a
+// user.
+func (db *DB) WaterTotalsByWeekdayHour(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (domain.WeekdayHourTotals, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var out domain.WeekdayHourTotals
+	start, end, err := localDayRange(fromDay, toDay, loc)
+	if err != nil {
+		return out, err
+	}
+
+	for _, w := range db.waterEvents {
+		if w.UserID != userID || w.CreatedAt.Before(start) || !w.CreatedAt.Before(end) {
+			continue
+		}
+		local := w.CreatedAt.In(loc)
+		out[local.Weekday()][local.Hour()] += w.DeltaLiters
+	}
+	return out, nil
+}
+
+// matchesEventQuery reports whether an event at createdAt with the given ID
+// falls within q's time bounds and after its cursor.
+func matchesEventQuery(q domain.EventQuery, createdAt time.Time, id int64) bool {
+	if !q.From.IsZero() && createdAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && (createdAt.After(q.To) || !q.ToInclusive && createdAt.Equal(q.To)) {
+		return false
+	}
+	if q.After != nil && !newerEvent(q.After.CreatedAt, q.After.ID, createdAt, id) {
+		return false
+	}
+	return true
+}
+
+// newerEvent reports whether event a sorts before event b in a newest-first
+// listing ordered by created_at, then ID.
+func newerEvent(aAt time.Time, aID int64, bAt time.Time, bID int64) bool {
+	if !aAt.Equal(bAt) {
+		return aAt.After(bAt)
+	}
+	return aID > bID
+}
+
+// localDayRange returns the UTC instants bounding the inclusive range of local
+// days [fromDay, toDay] in loc, which spans at most domain.MaxDayRange days
+// as in the postgres adapter.
+func localDayRange(fromDay, toDay string, loc *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01-02", fromDay, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	last, err := time.ParseInLocation("2006-01-02", toDay, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if last.Before(start) {
+		return time.Time{}, time.Time{}, errors.New("toDay must not be before fromDay")
+	}
+	// Days are 23 to 25 hours long around DST changes.
+	if days := int(last.Sub(start).Round(24*time.Hour)/(24*time.Hour)) + 1; days > domain.MaxDayRange {
+		return time.Time{}, time.Time{}, errors.New("day range too large")
+	}
+	return start.UTC(), last.AddDate(0, 0, 1).UTC(), nil
+}
+
+// --- BloodPressureRepository ---
+
+// AddBloodPressureReading adds a blood pressure reading.
+func (db *DB) AddBloodPressureReading(ctx context.Context, userID int64, r domain.BloodPressureReading) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.bpIDCounter++
+	r.ID = db.bpIDCounter
+	r.UserID = userID
+	r.Day = ""
+	r.Category = ""
+	r.CreatedAt = r.CreatedAt.UTC()
+	db.bpReadings = append(db.bpReadings, r)
+	return r.ID, nil
+}
+
+// GetBloodPressureReading returns a blood pressure reading by ID, scoped to a user.
+func (db *DB) GetBloodPressureReading(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.BloodPressureReading, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, r := range db.bpReadings {
+		if r.ID == id && r.UserID == userID {
+			r.Day = r.CreatedAt.In(loc).Format("2006-01-02")
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateBloodPressureReading replaces a blood pressure reading, scoped to a user.
+func (db *DB) UpdateBloodPressureReading(ctx context.Context, userID int64, id int64, r domain.BloodPressureReading) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i := range db.bpReadings {
+		b := &db.bpReadings[i]
+		if b.ID == id && b.UserID == userID {
+			b.Systolic = r.Systolic
+			b.Diastolic = r.Diastolic
+			b.Pulse = r.Pulse
+			b.Arm = r.Arm
+			b.Position = r.Position
+			b.CreatedAt = r.CreatedAt.UTC()
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteBloodPressureReading removes a blood pressure reading by ID, scoped to a user.
+func (db *DB) DeleteBloodPressureReading(ctx context.Context, userID int64, id int64) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i, r := range db.bpReadings {
+		if r.ID == id && r.UserID == userID {
+			db.bpReadings = append(db.bpReadings[:i], db.bpReadings[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListBloodPressureReadings lists the readings matching q for a user, newest first.
+func (db *DB) ListBloodPressureReadings(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.BloodPressureReading, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var filtered []domain.BloodPressureReading
+	for _, r := range db.bpReadings {
+		if r.UserID == userID && matchesEventQuery(q, r.CreatedAt, r.ID) {
+			filtered = append(filtered, r)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return newerEvent(filtered[i].CreatedAt, filtered[i].ID, filtered[j].CreatedAt, filtered[j].ID)
+	})
+	if len(filtered) > q.Limit {
+		filtered = filtered[:q.Limit]
+	}
+	for i := range filtered {
+		filtered[i].Day = filtered[i].CreatedAt.In(loc).Format("2006-01-02")
+	}
+	return filtered, nil
+}
+
+// BloodPressureAveragesForLocalDays returns the mean reading per local day in
+// the inclusive range [fromDay, toDay] for a user.
+func (db *DB) BloodPressureAveragesForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]domain.BloodPressureAverage, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	start, end, err := localDayRange(fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	type sums struct {
+		sys, dia, pulse float64
+		n, pulses       int
+	}
+	byDay := make(map[string]*sums)
+	for _, r := range db.bpReadings {
+		if r.UserID != userID || r.CreatedAt.Before(start) || !r.CreatedAt.Before(end) {
+			continue
+		}
+		day := r.CreatedAt.In(loc).Format("2006-01-02")
+		s := byDay[day]
+		if s == nil {
+			s = &sums{}
+			byDay[day] = s
+		}
+		s.sys += float64(r.Systolic)
+		s.dia += float64(r.Diastolic)
+		s.n++
+		if r.Pulse != nil {
+			s.pulse += float64(*r.Pulse)
+			s.pulses++
+		}
+	}
+
+	out := make(map[string]domain.BloodPressureAverage, len(byDay))
+	for day, s := range byDay {
+		avg := domain.BloodPressureAverage{
+			Systolic:  s.sys / float64(s.n),
+			Diastolic: s.dia / float64(s.n),
+			Readings:  s.n,
+		}
+		if s.pulses > 0 {
+			p := s.pulse / float64(s.pulses)
+			avg.Pulse = &p
+		}
+		out[day] = avg
+	}
+	return out, nil
+}
+
+// --- SleepRepository ---
+
+// AddSleepSession adds a sleep session.
+func (db *DB) AddSleepSession(ctx context.Context, userID int64, s domain.SleepSession) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.sleepIDCounter++
+	s.ID = db.sleepIDCounter
+	s.UserID = userID
+	s.Day = ""
+	s.DurationMinutes = 0
+	s.Bedtime = s.Bedtime.UTC()
+	s.WakeTime = s.WakeTime.UTC()
+	db.sleeps = append(db.sleeps, s)
+	return s.ID, nil
+}
+
+// GetSleepSession returns a sleep session by ID, scoped to a user.
+func (db *DB) GetSleepSession(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.SleepSession, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, s := range db.sleeps {
+		if s.ID == id && s.UserID == userID {
+			s.Day = s.WakeDay(loc)
+			return &s, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateSleepSession replaces a sleep session, scoped to a user.
+func (db *DB) UpdateSleepSession(ctx context.Context, userID int64, id int64, s domain.SleepSession) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i := range db.sleeps {
+		e := &db.sleeps[i]
+		if e.ID == id && e.UserID == userID {
+			e.Bedtime = s.Bedtime.UTC()
+			e.WakeTime = s.WakeTime.UTC()
+			e.Quality = s.Quality
+			e.Interruptions = s.Interruptions
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteSleepSession removes a sleep session by ID, scoped to a user.
+func (db *DB) DeleteSleepSession(ctx context.Context, userID int64, id int64) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i, s := range db.sleeps {
+		if s.ID == id && s.UserID == userID {
+			db.sleeps = append(db.sleeps[:i], db.sleeps[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListSleepSessions lists the sessions matching q by wake time for a user,
+// newest first.
+func (db *DB) ListSleepSessions(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.SleepSession, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var filtered []domain.SleepSession
+	for _, s := range db.sleeps {
+		if s.UserID == userID && matchesEventQuery(q, s.WakeTime, s.ID) {
+			filtered = append(filtered, s)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return newerEvent(filtered[i].WakeTime, filtered[i].ID, filtered[j].WakeTime, filtered[j].ID)
+	})
+	if len(filtered) > q.Limit {
+		filtered = filtered[:q.Limit]
+	}
+	for i := range filtered {
+		filtered[i].Day = filtered[i].WakeDay(loc)
+	}
+	return filtered, nil
+}
+
+// SleepMinutesForLocalDays returns the total minutes slept per wake day in
+// the inclusive range [fromDay, toDay] for a user.
+func (db *DB) SleepMinutesForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	start, end, err := localDayRange(fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]float64)
+	for _, s := range db.sleeps {
+		if s.UserID != userID || s.WakeTime.Before(start) || !s.WakeTime.Before(end) {
+			continue
+		}
+		out[s.WakeDay(loc)] += s.Duration().Minutes()
+	}
+	return out, nil
+}
+
+// --- MeasurementRepository ---
+
+// AddMeasurement adds a body measurement.
+func (db *DB) AddMeasurement(ctx context.Context, userID int64, site string, value float64, unit string, createdAt time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.measureIDCounter++
+	m := domain.Measurement{
+		ID:        db.measureIDCounter,
+		UserID:    userID,
+		Site:      site,
+		Value:     value,
+		Unit:      unit,
+		CreatedAt: createdAt.UTC(),
+	}
+	db.measures = append(db.measures, m)
+	return m.ID, nil
+}
+
+// GetMeasurement returns a body measurement by ID, scoped to a user.
+func (db *DB) GetMeasurement(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.Measurement, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, m := range db.measures {
+		if m.ID == id && m.UserID == userID {
+			m.Day = m.CreatedAt.In(loc).Format("2006-01-02")
+			return &m, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateMeasurement replaces a body measurement's site, value, unit and
+// timestamp, scoped to a user.
+func (db *DB) UpdateMeasurement(ctx context.Context, userID int64, id int64, site string, value float64, unit string, createdAt time.Time) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i := range db.measures {
+		m := &db.measures[i]
+		if m.ID == id && m.UserID == userID {
+			m.Site = site
+			m.Value = value
+			m.Unit = unit
+			m.CreatedAt = createdAt.UTC()
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteMeasurement removes a body measurement by ID, scoped to a user.
+func (db *DB) DeleteMeasurement(ctx context.Context, userID int64, id int64) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for i, m := range db.measures {
+		if m.ID == id && m.UserID == userID {
+			db.measures = append(db.measures[:i], db.measures[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListMeasurements lists the body measurements matching q for a user, newest
+// first, optionally restricted to one site.
+func (db *DB) ListMeasurements(ctx context.Context, userID int64, site string, q domain.EventQuery, loc *time.Location) ([]domain.Measurement, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var filtered []domain.Measurement
+	for _, m := range db.measures {
+		if m.UserID == userID && (site == "" || m.Site == site) && matchesEventQuery(q, m.CreatedAt, m.ID) {
+			filtered = append(filtered, m)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return newerEvent(filtered[i].CreatedAt, filtered[i].ID, filtered[j].CreatedAt, filtered[j].ID)
+	})
+	if len(filtered) > q.Limit {
+		filtered = filtered[:q.Limit]
+	}
+	for i := range filtered {
+		filtered[i].Day = filtered[i].CreatedAt.In(loc).Format("2006-01-02")
+	}
+	return filtered, nil
+}
+
+// LatestMeasurementsForLocalDays returns the latest measurement per site and
+// local day in the inclusive range [fromDay, toDay] for a user.
+func (db *DB) LatestMeasurementsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]map[string]domain.Measurement, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	start, end, err := localDayRange(fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]map[string]domain.Measurement)
+	for _, m := range db.measures {
+		if m.UserID != userID || m.CreatedAt.Before(start) || !m.CreatedAt.Before(end) {
+			continue
+		}
+		day := m.CreatedAt.In(loc).Format("2006-01-02")
+		if out[day] == nil {
+			out[day] = make(map[string]domain.Measurement)
+		}
+		if cur, ok := out[day][m.Site]; ok && newerEvent(cur.CreatedAt, cur.ID, m.CreatedAt, m.ID) {
+			continue
+		}
+		m.Day = day
+		out[day][m.Site] = m
+	}
+	return out, nil
+}
+
+// --- WeightGoalRepository ---
+
+// GetWeightGoal returns the weight goal of a user.
+func (db *DB) GetWeightGoal(ctx context.Context, userID int64) (*domain.WeightGoal, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	g, ok := db.goals[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &g, nil
+}
+
+// SetWeightGoal stores the weight goal of a user, replacing any existing one.
+func (db *DB) SetWeightGoal(ctx context.Context, userID int64, g domain.WeightGoal) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	g.UserID = userID
+	g.CreatedAt = g.CreatedAt.UTC()
+	db.goals[userID] = g
+	return nil
+}
+
+// DeleteWeightGoal removes the weight goal of a user.
+func (db *DB) DeleteWeightGoal(ctx context.Context, userID int64) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	_, ok := db.goals[userID]
+	delete(db.goals, userID)
+	return ok, nil
+}
+
+// --- ImportRepository ---
+
+// ImportEvents adds the weight and water events to a user's history at once.
+func (db *DB) ImportEvents(ctx context.Context, userID int64, weights []domain.WeightEntry, water []domain.WaterEvent) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.insertEvents(userID, weights, water)
+	return nil
+}
+
+// RestoreAccount replaces a user's weight and water events with the given
+// ones and updates their preferences at once.
+func (db *DB) RestoreAccount(ctx context.Context, userID int64, weights []domain.WeightEntry, water []domain.WaterEvent, p domain.ProfileUpdate) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	restored := db.updateProfile(userID, p) != nil
+	db.weights = slices.DeleteFunc(db.weights, func(e domain.WeightEntry) bool { return e.UserID == userID })
+	db.waterEvents = slices.DeleteFunc(db.waterEvents, func(e domain.WaterEvent) bool { return e.UserID == userID })
+	db.insertEvents(userID, weights, water)
+	return restored, nil
+}
+
+// insertEvents adds the events to a user's history. The caller must hold
+// db.mu.
+func (db *DB) insertEvents(userID int64, weights []domain.WeightEntry, water []domain.WaterEvent) {
+	for _, e := range weights {
+		db.weightIDCounter++
+		e.ID = db.weightIDCounter
+		e.UserID = userID
+		e.Day = ""
+		e.CreatedAt = e.CreatedAt.UTC()
+		db.weights = append(db.weights, e)
+	}
+	for _, e := range water {
+		db.waterIDCounter++
+		e.ID = db.waterIDCounter
+		e.UserID = userID
+		e.CreatedAt = e.CreatedAt.UTC()
+		db.waterEvents = append(db.waterEvents, e)
+	}
+}
+
 // --- UserRepository ---
 
 // GetByUsername retrieves a user by username.
@@ -291,6 +971,38 @@ func (db *DB) Count(ctx context.Context) (int, error) {
 	return len(db.users), nil
 }
 
+// UpdateProfile sets the time zone, water goal and weight policy of a user,
+// as far as they are given, and returns the updated user.
+func (db *DB) UpdateProfile(ctx context.Context, id int64, p domain.ProfileUpdate) (*domain.User, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.updateProfile(id, p), nil
+}
+
+// updateProfile applies p to the user with the ID and returns the updated
+// user, or nil if there is none. The caller must hold db.mu.
+func (db *DB) updateProfile(id int64, p domain.ProfileUpdate) *domain.User {
+	for i, u := range db.users {
+		if u.ID == id {
+			// Copy so callers holding the previous pointer never race with us.
+			updated := *u
+			if p.Timezone != nil {
+				updated.Timezone = *p.Timezone
+			}
+			if p.WaterGoalLiters != nil {
+				updated.WaterGoalLiters = *p.WaterGoalLiters
+			}
+			if p.WeightPolicy != nil {
+				updated.DailyWeightPolicy = *p.WeightPolicy
+			}
+			db.users[i] = &updated
+			return &updated
+		}
+	}
+	return nil
+}
+
 // --- SessionRepository ---
 
 // SessionRepo implements session persistence.
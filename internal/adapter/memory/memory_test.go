@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"vitals/internal/domain"
 )
 
 func TestWeightRepository(t *testing.T) {
@@ -13,7 +15,7 @@ func TestWeightRepository(t *testing.T) {
 
 	// Add event
 	now := time.Now()
-	id, err := db.AddWeightEvent(ctx, userID, 70.0, "kg", now)
+	id, err := db.AddWeightEvent(ctx, userID, 70.0, "kg", domain.BodyComposition{}, now)
 	if err != nil {
 		t.Fatalf("AddWeightEvent: %v", err)
 	}
@@ -22,7 +24,7 @@ func TestWeightRepository(t *testing.T) {
 	}
 
 	// List events
-	events, err := db.ListRecentWeightEvents(ctx, userID, 10)
+	events, err := db.ListRecentWeightEvents(ctx, userID, 10, time.Local)
 	if err != nil {
 		t.Fatalf("ListRecentWeightEvents: %v", err)
 	}
@@ -37,16 +39,16 @@ func TestWeightRepository(t *testing.T) {
 	}
 
 	// Other user sees nothing
-	events2, _ := db.ListRecentWeightEvents(ctx, 999, 10)
+	events2, _ := db.ListRecentWeightEvents(ctx, 999, 10, time.Local)
 	if len(events2) != 0 {
 		t.Error("expected 0 events for other user")
 	}
 
 	// Latest for day
 	localDay := now.Format("2006-01-02")
-	latest, err := db.LatestWeightForLocalDay(ctx, userID, localDay)
+	latest, err := db.WeightForLocalDay(ctx, userID, localDay, domain.WeightPolicyLast, time.Local)
 	if err != nil {
-		t.Fatalf("LatestWeightForLocalDay: %v", err)
+		t.Fatalf("WeightForLocalDay: %v", err)
 	}
 	if latest == nil {
 		t.Error("expected latest weight, got nil")
@@ -63,7 +65,7 @@ func TestWeightRepository(t *testing.T) {
 		t.Error("expected true")
 	}
 
-	events, _ = db.ListRecentWeightEvents(ctx, userID, 10)
+	events, _ = db.ListRecentWeightEvents(ctx, userID, 10, time.Local)
 	if len(events) != 0 {
 		t.Error("expected 0 events")
 	}
@@ -98,7 +100,7 @@ func TestWaterRepository(t *testing.T) {
 
 	// Total for day
 	localDay := now.Format("2006-01-02")
-	total, err := db.WaterTotalForLocalDay(ctx, userID, localDay)
+	total, err := db.WaterTotalForLocalDay(ctx, userID, localDay, time.Local)
 	if err != nil {
 		t.Fatalf("WaterTotalForLocalDay: %v", err)
 	}
@@ -107,6 +109,466 @@ func TestWaterRepository(t *testing.T) {
 	}
 }
 
+func TestUndoBackdatedEntry(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	userID := int64(1)
+
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	_, _ = db.AddWeightEvent(ctx, userID, 80, "kg", domain.BodyComposition{}, now)
+	_, _ = db.AddWeightEvent(ctx, userID, 81, "kg", domain.BodyComposition{}, yesterday)
+	_, _ = db.AddWaterEvent(ctx, userID, 0.25, now)
+	backdated, _ := db.AddWaterEvent(ctx, userID, 0.5, yesterday)
+
+	// Undo removes the entry logged last, not the one with the latest time.
+	if ok, err := db.DeleteLatestWeightEvent(ctx, userID); err != nil || !ok {
+		t.Fatalf("DeleteLatestWeightEvent: ok=%v err=%v", ok, err)
+	}
+	weights, _ := db.ListRecentWeightEvents(ctx, userID, 10, time.Local)
+	if len(weights) != 1 || weights[0].Value != 80 {
+		t.Errorf("expected only today's weight to remain, got %+v", weights)
+	}
+
+	water, err := db.ListRecentWaterEvents(ctx, userID, 1)
+	if err != nil {
+		t.Fatalf("ListRecentWaterEvents: %v", err)
+	}
+	if len(water) != 1 || water[0].ID != backdated {
+		t.Errorf("expected the backdated water event first, got %+v", water)
+	}
+}
+
+func TestEditByID(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	now := time.Now()
+
+	wid, _ := db.AddWeightEvent(ctx, 1, 80.0, "kg", domain.BodyComposition{}, now)
+	waid, _ := db.AddWaterEvent(ctx, 1, 0.5, now)
+
+	// Another user cannot see, edit or delete the entries.
+	if e, _ := db.GetWeightEvent(ctx, 2, wid, time.UTC); e != nil {
+		t.Error("expected nil for other user's weight")
+	}
+	if ok, _ := db.UpdateWeightEvent(ctx, 2, wid, 1, "kg", domain.BodyComposition{}, now); ok {
+		t.Error("expected update by other user to fail")
+	}
+	if ok, _ := db.DeleteWaterEvent(ctx, 2, waid); ok {
+		t.Error("expected delete by other user to fail")
+	}
+
+	earlier := now.Add(-time.Hour)
+	if ok, err := db.UpdateWeightEvent(ctx, 1, wid, 79.1, "lb", domain.BodyComposition{}, earlier); err != nil || !ok {
+		t.Fatalf("UpdateWeightEvent: ok=%v err=%v", ok, err)
+	}
+	e, _ := db.GetWeightEvent(ctx, 1, wid, time.UTC)
+	if e == nil || e.Value != 79.1 || e.Unit != "lb" || !e.CreatedAt.Equal(earlier) {
+		t.Errorf("unexpected weight after update: %+v", e)
+	}
+
+	if ok, err := db.UpdateWaterEvent(ctx, 1, waid, 1.25, earlier); err != nil || !ok {
+		t.Fatalf("UpdateWaterEvent: ok=%v err=%v", ok, err)
+	}
+	we, _ := db.GetWaterEvent(ctx, 1, waid)
+	if we == nil || we.DeltaLiters != 1.25 {
+		t.Errorf("unexpected water after update: %+v", we)
+	}
+
+	if ok, _ := db.DeleteWeightEvent(ctx, 1, wid); !ok {
+		t.Error("expected weight delete to succeed")
+	}
+	if ok, _ := db.DeleteWeightEvent(ctx, 1, wid); ok {
+		t.Error("expected second delete to report not found")
+	}
+}
+
+func TestRangeQueries(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	userID := int64(1)
+
+	day1 := time.Date(2026, 3, 1, 8, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	_, _ = db.AddWeightEvent(ctx, userID, 80.0, "kg", domain.BodyComposition{}, day1)
+	_, _ = db.AddWeightEvent(ctx, userID, 79.5, "kg", domain.BodyComposition{}, day1.Add(2*time.Hour))
+	_, _ = db.AddWeightEvent(ctx, userID, 79.0, "kg", domain.BodyComposition{}, day2)
+	_, _ = db.AddWeightEvent(ctx, 999, 60.0, "kg", domain.BodyComposition{}, day2)
+	_, _ = db.AddWaterEvent(ctx, userID, 0.5, day1)
+	_, _ = db.AddWaterEvent(ctx, userID, 0.25, day1.Add(time.Hour))
+	_, _ = db.AddWaterEvent(ctx, userID, 1.0, day2.AddDate(0, 0, 5))
+
+	weights, err := db.WeightsForLocalDays(ctx, userID, "2026-03-01", "2026-03-02", domain.WeightPolicyLast, time.Local)
+	if err != nil {
+		t.Fatalf("WeightsForLocalDays: %v", err)
+	}
+	if len(weights) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(weights))
+	}
+	if weights["2026-03-01"].Value != 79.5 {
+		t.Errorf("expected latest 79.5 on day 1, got %f", weights["2026-03-01"].Value)
+	}
+	if weights["2026-03-02"].Value != 79.0 || weights["2026-03-02"].Day != "2026-03-02" {
+		t.Errorf("unexpected day 2 entry: %+v", weights["2026-03-02"])
+	}
+
+	totals, err := db.WaterTotalsForLocalDays(ctx, userID, "2026-03-01", "2026-03-02", time.Local)
+	if err != nil {
+		t.Fatalf("WaterTotalsForLocalDays: %v", err)
+	}
+	if len(totals) != 1 || totals["2026-03-01"] != 0.75 {
+		t.Errorf("unexpected totals: %v", totals)
+	}
+
+	if _, err := db.WaterTotalsForLocalDays(ctx, userID, "2026-03-02", "2026-03-01", time.Local); err == nil {
+		t.Error("expected error for inverted range")
+	}
+}
+
+func TestLocalDayRange_Limit(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	from := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, domain.MaxDayRange-1)
+	if _, err := db.WeightsForLocalDays(ctx, 1, from.Format("2006-01-02"), to.Format("2006-01-02"), domain.WeightPolicyLast, ny); err != nil {
+		t.Fatalf("unexpected error at the limit: %v", err)
+	}
+	if _, err := db.WaterTotalsForLocalDays(ctx, 1, from.Format("2006-01-02"), to.AddDate(0, 0, 1).Format("2006-01-02"), ny); err == nil {
+		t.Error("expected an error for a range over the limit")
+	}
+}
+
+func TestWeightsForLocalDays_Policy(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	userID := int64(1)
+
+	morning := time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC)
+	_, _ = db.AddWeightEvent(ctx, userID, 80.4, "kg", domain.BodyComposition{}, morning)
+	_, _ = db.AddWeightEvent(ctx, userID, 79.9, "kg", domain.BodyComposition{}, morning.Add(4*time.Hour))
+	_, _ = db.AddWeightEvent(ctx, userID, 81.2, "kg", domain.BodyComposition{}, morning.Add(12*time.Hour))
+
+	for policy, want := range map[domain.WeightPolicy]float64{
+		domain.WeightPolicyLast:  81.2,
+		domain.WeightPolicyFirst: 80.4,
+		domain.WeightPolicyMin:   79.9,
+		domain.WeightPolicyMean:  80.5,
+	} {
+		entry, err := db.WeightForLocalDay(ctx, userID, "2026-03-01", policy, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: %v", policy, err)
+		}
+		if entry == nil || entry.Value < want-1e-9 || entry.Value > want+1e-9 || entry.Day != "2026-03-01" {
+			t.Errorf("%s: expected %v, got %+v", policy, want, entry)
+		}
+	}
+
+	if _, err := db.WeightsForLocalDays(ctx, userID, "2026-03-01", "2026-03-01", "median", time.UTC); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
+
+func TestLocalDayUsesLocation(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	userID := int64(1)
+
+	// 23:30 in New York on March 1st is already March 2nd in UTC.
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	at := time.Date(2026, 3, 1, 23, 30, 0, 0, ny)
+	_, _ = db.AddWeightEvent(ctx, userID, 80.0, "kg", domain.BodyComposition{}, at)
+	_, _ = db.AddWaterEvent(ctx, userID, 0.5, at)
+
+	entry, err := db.WeightForLocalDay(ctx, userID, "2026-03-01", domain.WeightPolicyLast, ny)
+	if err != nil {
+		t.Fatalf("WeightForLocalDay: %v", err)
+	}
+	if entry == nil {
+		t.Fatal("expected entry on the New York day")
+	}
+	if entry, _ := db.WeightForLocalDay(ctx, userID, "2026-03-02", domain.WeightPolicyLast, ny); entry != nil {
+		t.Error("expected no entry on the following New York day")
+	}
+
+	events, _ := db.ListRecentWeightEvents(ctx, userID, 1, ny)
+	if len(events) != 1 || events[0].Day != "2026-03-01" {
+		t.Errorf("expected day 2026-03-01, got %+v", events)
+	}
+
+	total, _ := db.WaterTotalForLocalDay(ctx, userID, "2026-03-01", ny)
+	if total != 0.5 {
+		t.Errorf("expected 0.5 on the New York day, got %f", total)
+	}
+	totals, _ := db.WaterTotalsForLocalDays(ctx, userID, "2026-03-01", "2026-03-02", time.UTC)
+	if totals["2026-03-02"] != 0.5 {
+		t.Errorf("expected UTC total on 2026-03-02, got %v", totals)
+	}
+}
+
+func TestWaterTotalsByWeekdayHour(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	// 2026-03-02 is a Monday.
+	_, _ = db.AddWaterEvent(ctx, 1, 0.25, time.Date(2026, 3, 2, 8, 5, 0, 0, ny))
+	_, _ = db.AddWaterEvent(ctx, 1, 0.5, time.Date(2026, 3, 2, 8, 55, 0, 0, ny))
+	_, _ = db.AddWaterEvent(ctx, 1, 0.5, time.Date(2026, 3, 8, 23, 30, 0, 0, ny))
+	_, _ = db.AddWaterEvent(ctx, 1, 1, time.Date(2026, 3, 9, 8, 0, 0, 0, ny))
+	_, _ = db.AddWaterEvent(ctx, 2, 1, time.Date(2026, 3, 2, 8, 0, 0, 0, ny))
+
+	totals, err := db.WaterTotalsByWeekdayHour(ctx, 1, "2026-03-02", "2026-03-08", ny)
+	if err != nil {
+		t.Fatalf("WaterTotalsByWeekdayHour: %v", err)
+	}
+	if totals[time.Monday][8] != 0.75 {
+		t.Errorf("expected 0.75 L on Monday at 8, got %v", totals[time.Monday][8])
+	}
+	if totals[time.Sunday][23] != 0.5 {
+		t.Errorf("expected 0.5 L on Sunday at 23 local time, got %v", totals[time.Sunday][23])
+	}
+	var sum float64
+	for _, hours := range totals {
+		for _, v := range hours {
+			sum += v
+		}
+	}
+	if sum != 1.25 {
+		t.Errorf("expected 1.25 L in range for the user, got %v", sum)
+	}
+}
+
+func TestImportEvents(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	_, _ = db.AddWeightEvent(ctx, 1, 80, "kg", domain.BodyComposition{}, time.Now())
+
+	at := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	err := db.ImportEvents(ctx, 1,
+		[]domain.WeightEntry{{ID: 99, UserID: 2, Value: 81, Unit: "kg", CreatedAt: at}},
+		[]domain.WaterEvent{{DeltaLiters: 0.5, CreatedAt: at}, {DeltaLiters: 0.25, CreatedAt: at.Add(time.Hour)}},
+	)
+	if err != nil {
+		t.Fatalf("ImportEvents: %v", err)
+	}
+
+	weights, _ := db.ListWeightEvents(ctx, 1, domain.EventQuery{Limit: 10}, time.UTC)
+	if len(weights) != 2 || weights[1].ID != 2 || weights[1].UserID != 1 || weights[1].Day != "2024-03-01" {
+		t.Errorf("expected the imported weigh-in with a fresh ID, got %+v", weights)
+	}
+	total, _ := db.WaterTotalForLocalDay(ctx, 1, "2024-03-01", time.UTC)
+	if total != 0.75 {
+		t.Errorf("expected 0.75 L imported, got %v", total)
+	}
+
+	_, _ = db.AddWaterEvent(ctx, 2, 1, at)
+	user, _ := db.Create(ctx, "alice", "hash")
+	tz := "Europe/Berlin"
+	restored, err := db.RestoreAccount(ctx, user.ID, []domain.WeightEntry{{Value: 82, Unit: "kg", CreatedAt: at}}, nil, domain.ProfileUpdate{Timezone: &tz})
+	if err != nil || !restored {
+		t.Fatalf("RestoreAccount: %v, %v", restored, err)
+	}
+	if u, _ := db.GetByID(ctx, user.ID); u.Timezone != tz {
+		t.Errorf("expected the profile restored, got %q", u.Timezone)
+	}
+	weights, _ = db.ListWeightEvents(ctx, 1, domain.EventQuery{Limit: 10}, time.UTC)
+	if len(weights) != 1 || weights[0].Value != 82 {
+		t.Errorf("expected only the replacement weigh-in, got %+v", weights)
+	}
+	if total, _ := db.WaterTotalForLocalDay(ctx, 1, "2024-03-01", time.UTC); total != 0 {
+		t.Errorf("expected the user's water replaced, got %v", total)
+	}
+	if total, _ := db.WaterTotalForLocalDay(ctx, 2, "2024-03-01", time.UTC); total != 1 {
+		t.Errorf("expected other users' water kept, got %v", total)
+	}
+	if restored, _ := db.RestoreAccount(ctx, 3, nil, nil, domain.ProfileUpdate{Timezone: &tz}); restored {
+		t.Error("expected no profile restored for a user without one")
+	}
+}
+
+func TestListEventsQuery(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Two events share a timestamp so the ID tiebreaker is exercised.
+	for _, h := range []int{0, 1, 1, 2, 3} {
+		_, _ = db.AddWaterEvent(ctx, 1, 0.25, base.Add(time.Duration(h)*time.Hour))
+	}
+	_, _ = db.AddWaterEvent(ctx, 2, 1, base)
+
+	q := domain.EventQuery{From: base.Add(time.Hour), To: base.Add(3 * time.Hour), Limit: 10}
+	got, _ := db.ListWaterEvents(ctx, 1, q)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 events in [1h, 3h), got %d", len(got))
+	}
+	if got[0].ID != 4 || got[1].ID != 3 || got[2].ID != 2 {
+		t.Fatalf("unexpected order: %d, %d, %d", got[0].ID, got[1].ID, got[2].ID)
+	}
+
+	q.After = &domain.EventCursor{CreatedAt: got[1].CreatedAt, ID: got[1].ID}
+	rest, _ := db.ListWaterEvents(ctx, 1, q)
+	if len(rest) != 1 || rest[0].ID != 2 {
+		t.Fatalf("expected only id 2 after cursor, got %+v", rest)
+	}
+
+	// An inclusive bound keeps the event stamped exactly at To.
+	q = domain.EventQuery{From: base.Add(time.Hour), To: base.Add(3 * time.Hour), ToInclusive: true, Limit: 10}
+	if got, _ := db.ListWaterEvents(ctx, 1, q); len(got) != 4 || got[0].ID != 5 {
+		t.Fatalf("expected 4 events in [1h, 3h] starting with id 5, got %+v", got)
+	}
+
+	_, _ = db.AddWeightEvent(ctx, 1, 80, "kg", domain.BodyComposition{}, base)
+	_, _ = db.AddWeightEvent(ctx, 1, 81, "kg", domain.BodyComposition{}, base.Add(time.Hour))
+	weights, _ := db.ListWeightEvents(ctx, 1, domain.EventQuery{Limit: 1}, time.UTC)
+	if len(weights) != 1 || weights[0].Value != 81 || weights[0].Day != "2026-03-01" {
+		t.Fatalf("unexpected weights: %+v", weights)
+	}
+}
+
+func TestBloodPressureRepository(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	day := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	pulse := 60
+
+	id, err := db.AddBloodPressureReading(ctx, 1, domain.BloodPressureReading{Systolic: 120, Diastolic: 80, Pulse: &pulse, Arm: "left", CreatedAt: day})
+	if err != nil {
+		t.Fatalf("AddBloodPressureReading: %v", err)
+	}
+	_, _ = db.AddBloodPressureReading(ctx, 1, domain.BloodPressureReading{Systolic: 130, Diastolic: 90, CreatedAt: day.Add(time.Hour)})
+	_, _ = db.AddBloodPressureReading(ctx, 2, domain.BloodPressureReading{Systolic: 200, Diastolic: 100, CreatedAt: day})
+
+	r, _ := db.GetBloodPressureReading(ctx, 1, id, time.UTC)
+	if r == nil || r.Arm != "left" || r.Pulse == nil || *r.Pulse != 60 || r.Day != "2026-03-01" {
+		t.Fatalf("unexpected reading: %+v", r)
+	}
+	if r, _ := db.GetBloodPressureReading(ctx, 2, id, time.UTC); r != nil {
+		t.Error("expected nil for other user's reading")
+	}
+
+	avgs, _ := db.BloodPressureAveragesForLocalDays(ctx, 1, "2026-03-01", "2026-03-02", time.UTC)
+	avg, ok := avgs["2026-03-01"]
+	if !ok || len(avgs) != 1 {
+		t.Fatalf("expected one day of averages, got %v", avgs)
+	}
+	if avg.Systolic != 125 || avg.Diastolic != 85 || avg.Readings != 2 || avg.Pulse == nil || *avg.Pulse != 60 {
+		t.Errorf("unexpected average: %+v", avg)
+	}
+
+	if ok, _ := db.UpdateBloodPressureReading(ctx, 1, id, domain.BloodPressureReading{Systolic: 118, Diastolic: 75, CreatedAt: day}); !ok {
+		t.Fatal("expected update to succeed")
+	}
+	list, _ := db.ListBloodPressureReadings(ctx, 1, domain.EventQuery{Limit: 10}, time.UTC)
+	if len(list) != 2 || list[1].Systolic != 118 || list[1].Pulse != nil {
+		t.Fatalf("unexpected readings after update: %+v", list)
+	}
+
+	if ok, _ := db.DeleteBloodPressureReading(ctx, 1, id); !ok {
+		t.Fatal("expected delete to succeed")
+	}
+	if ok, _ := db.DeleteBloodPressureReading(ctx, 1, id); ok {
+		t.Fatal("expected second delete to report not found")
+	}
+}
+
+func TestSleepRepository(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	loc := time.FixedZone("UTC-5", -5*3600)
+
+	// 23:00 on March 1 to 07:00 on March 2, local time.
+	bed := time.Date(2026, 3, 1, 23, 0, 0, 0, loc)
+	id, _ := db.AddSleepSession(ctx, 1, domain.SleepSession{Bedtime: bed, WakeTime: bed.Add(8 * time.Hour)})
+	// An afternoon nap on March 2.
+	nap := time.Date(2026, 3, 2, 14, 0, 0, 0, loc)
+	_, _ = db.AddSleepSession(ctx, 1, domain.SleepSession{Bedtime: nap, WakeTime: nap.Add(30 * time.Minute)})
+
+	s, _ := db.GetSleepSession(ctx, 1, id, loc)
+	if s == nil || s.Day != "2026-03-02" {
+		t.Fatalf("expected session on wake day 2026-03-02, got %+v", s)
+	}
+
+	minutes, _ := db.SleepMinutesForLocalDays(ctx, 1, "2026-03-01", "2026-03-02", loc)
+	if len(minutes) != 1 || minutes["2026-03-02"] != 510 {
+		t.Fatalf("expected 510 minutes on 2026-03-02 only, got %v", minutes)
+	}
+
+	list, _ := db.ListSleepSessions(ctx, 1, domain.EventQuery{Limit: 10}, loc)
+	if len(list) != 2 || list[0].Bedtime.Equal(bed) {
+		t.Fatalf("expected nap first, got %+v", list)
+	}
+
+	if ok, _ := db.DeleteSleepSession(ctx, 2, id); ok {
+		t.Error("expected delete by other user to fail")
+	}
+}
+
+func TestMeasurementRepository(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+	day := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+
+	_, _ = db.AddMeasurement(ctx, 1, "waist", 86, "cm", day)
+	later, _ := db.AddMeasurement(ctx, 1, "waist", 33.5, "in", day.Add(time.Hour))
+	_, _ = db.AddMeasurement(ctx, 1, "hips", 98, "cm", day)
+	_, _ = db.AddMeasurement(ctx, 2, "waist", 70, "cm", day)
+
+	latest, _ := db.LatestMeasurementsForLocalDays(ctx, 1, "2026-03-01", "2026-03-02", time.UTC)
+	if len(latest) != 1 || len(latest["2026-03-02"]) != 2 {
+		t.Fatalf("expected two sites on 2026-03-02 only, got %v", latest)
+	}
+	if w := latest["2026-03-02"]["waist"]; w.ID != later || w.Unit != "in" {
+		t.Errorf("expected latest waist measurement, got %+v", w)
+	}
+
+	list, _ := db.ListMeasurements(ctx, 1, "waist", domain.EventQuery{Limit: 10}, time.UTC)
+	if len(list) != 2 || list[0].ID != later {
+		t.Fatalf("expected two waist measurements newest first, got %+v", list)
+	}
+
+	if ok, _ := db.UpdateMeasurement(ctx, 2, later, "waist", 30, "in", day); ok {
+		t.Error("expected update by other user to fail")
+	}
+	if ok, _ := db.DeleteMeasurement(ctx, 1, later); !ok {
+		t.Error("expected delete to succeed")
+	}
+	if m, _ := db.GetMeasurement(ctx, 1, later, time.UTC); m != nil {
+		t.Errorf("expected deleted measurement to be gone, got %+v", m)
+	}
+}
+
+func TestWeightGoalRepository(t *testing.T) {
+	db := New()
+	ctx := context.Background()
+
+	if g, _ := db.GetWeightGoal(ctx, 1); g != nil {
+		t.Fatalf("expected no goal, got %+v", g)
+	}
+	_ = db.SetWeightGoal(ctx, 1, domain.WeightGoal{TargetValue: 80, Unit: "kg"})
+	_ = db.SetWeightGoal(ctx, 1, domain.WeightGoal{TargetValue: 170, Unit: "lb", TargetDate: "2026-06-01"})
+	g, _ := db.GetWeightGoal(ctx, 1)
+	if g == nil || g.UserID != 1 || g.TargetValue != 170 || g.TargetDate != "2026-06-01" {
+		t.Fatalf("expected replaced goal, got %+v", g)
+	}
+	if ok, _ := db.DeleteWeightGoal(ctx, 2); ok {
+		t.Error("expected delete for user without a goal to report false")
+	}
+	if ok, _ := db.DeleteWeightGoal(ctx, 1); !ok {
+		t.Error("expected delete to succeed")
+	}
+}
+
 func TestUserRepository(t *testing.T) {
 	db := New()
 	ctx := context.Background()
@@ -131,6 +593,29 @@ func TestUserRepository(t *testing.T) {
 	if count != 1 {
 		t.Errorf("expected 1 user, got %d", count)
 	}
+
+	tz := "Asia/Tokyo"
+	updated, err := db.UpdateProfile(ctx, u.ID, domain.ProfileUpdate{Timezone: &tz})
+	if err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	u3, _ := db.GetByID(ctx, u.ID)
+	if u3 == nil || u3.Timezone != "Asia/Tokyo" || updated == nil || *updated != *u3 {
+		t.Errorf("expected timezone Asia/Tokyo, got %+v and returned %+v", u3, updated)
+	}
+
+	goal, policy := 3.0, domain.WeightPolicyMin
+	if _, err := db.UpdateProfile(ctx, u.ID, domain.ProfileUpdate{WaterGoalLiters: &goal, WeightPolicy: &policy}); err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	u4, _ := db.GetByID(ctx, u.ID)
+	if u4 == nil || u4.WaterGoal() != 3 || u4.WeightPolicy() != domain.WeightPolicyMin || u4.Timezone != "Asia/Tokyo" {
+		t.Errorf("expected water goal 3 and policy min alongside timezone, got %+v", u4)
+	}
+
+	if missing, err := db.UpdateProfile(ctx, u.ID+1, domain.ProfileUpdate{Timezone: &tz}); err != nil || missing != nil {
+		t.Errorf("expected no user for an unknown ID, got %+v, %v", missing, err)
+	}
 }
 
 func TestSessionRepository(t *testing.T) {
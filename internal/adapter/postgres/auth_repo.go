@@ -4,6 +4,8 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"vitals/internal/domain"
@@ -13,9 +15,9 @@ import (
 func (d *DB) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
 	var u domain.User
 	err := d.sql.QueryRowContext(ctx,
-		"SELECT id, username, password_hash, created_at FROM users WHERE username = $1",
+		"SELECT id, username, password_hash, timezone, water_goal_liters, weight_policy, created_at FROM users WHERE username = $1",
 		username,
-	).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt)
+	).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Timezone, &u.WaterGoalLiters, &u.DailyWeightPolicy, &u.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -29,9 +31,9 @@ func (d *DB) GetByUsername(ctx context.Context, username string) (*domain.User,
 func (d *DB) GetByID(ctx context.Context, id int64) (*domain.User, error) {
 	var u domain.User
 	err := d.sql.QueryRowContext(ctx,
-		"SELECT id, username, password_hash, created_at FROM users WHERE id = $1",
+		"SELECT id, username, password_hash, timezone, water_goal_liters, weight_policy, created_at FROM users WHERE id = $1",
 		id,
-	).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt)
+	).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Timezone, &u.WaterGoalLiters, &u.DailyWeightPolicy, &u.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -45,9 +47,9 @@ func (d *DB) GetByID(ctx context.Context, id int64) (*domain.User, error) {
 func (d *DB) Create(ctx context.Context, username, passwordHash string) (*domain.User, error) {
 	var u domain.User
 	err := d.sql.QueryRowContext(ctx,
-		"INSERT INTO users (username, password_hash, created_at) VALUES ($1, $2, $3) RETURNING id, username, password_hash, created_at",
+		"INSERT INTO users (username, password_hash, created_at) VALUES ($1, $2, $3) RETURNING id, username, password_hash, timezone, water_goal_liters, weight_policy, created_at",
 		username, passwordHash, time.Now(),
-	).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt)
+	).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Timezone, &u.WaterGoalLiters, &u.DailyWeightPolicy, &u.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -61,6 +63,52 @@ func (d *DB) Count(ctx context.Context) (int, error) {
 	return count, err
 }
 
+// UpdateProfile sets the time zone, water goal and weight policy of a user,
+// as far as they are given, in one statement and returns the updated user.
+func (d *DB) UpdateProfile(ctx context.Context, id int64, p domain.ProfileUpdate) (*domain.User, error) {
+	query, args := profileUpdateSQL(id, p)
+	if query == "" {
+		return d.GetByID(ctx, id)
+	}
+	query += " RETURNING id, username, password_hash, timezone, water_goal_liters, weight_policy, created_at"
+	row := d.sql.QueryRowContext(ctx, query, args...) //nolint:gosec // the columns are constants
+	var u domain.User
+	err := row.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Timezone, &u.WaterGoalLiters, &u.DailyWeightPolicy, &u.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// profileUpdateSQL returns the statement that applies the set fields of p to
+// the user with the given ID, and its arguments. The statement is empty if no
+// field is set.
+func profileUpdateSQL(id int64, p domain.ProfileUpdate) (string, []any) {
+	var sets []string
+	var args []any
+	set := func(column string, v any) {
+		args = append(args, v)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if p.Timezone != nil {
+		set("timezone", *p.Timezone)
+	}
+	if p.WaterGoalLiters != nil {
+		set("water_goal_liters", *p.WaterGoalLiters)
+	}
+	if p.WeightPolicy != nil {
+		set("weight_policy", string(*p.WeightPolicy))
+	}
+	if len(sets) == 0 {
+		return "", nil
+	}
+	args = append(args, id)
+	return fmt.Sprintf("UPDATE users SET %s WHERE id = $%d", strings.Join(sets, ", "), len(args)), args
+}
+
 // SessionRepo implements session repository operations on DB.
 type SessionRepo struct {
 	db *DB
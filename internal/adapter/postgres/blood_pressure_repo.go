@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+const bpColumns = "id, systolic, diastolic, pulse, arm, position, created_at"
+
+// scanBloodPressure scans a row selected with bpColumns.
+func scanBloodPressure(row interface{ Scan(...any) error }, userID int64, loc *time.Location) (domain.BloodPressureReading, error) {
+	var r domain.BloodPressureReading
+	var pulse sql.NullInt64
+	if err := row.Scan(&r.ID, &r.Systolic, &r.Diastolic, &pulse, &r.Arm, &r.Position, &r.CreatedAt); err != nil {
+		return r, err
+	}
+	if pulse.Valid {
+		p := int(pulse.Int64)
+		r.Pulse = &p
+	}
+	r.UserID = userID
+	r.Day = r.CreatedAt.In(loc).Format("2006-01-02")
+	return r, nil
+}
+
+// AddBloodPressureReading inserts a new blood pressure reading.
+func (d *DB) AddBloodPressureReading(ctx context.Context, userID int64, r domain.BloodPressureReading) (int64, error) {
+	var id int64
+	err := d.sql.QueryRowContext(ctx,
+		"INSERT INTO blood_pressure_readings(user_id, systolic, diastolic, pulse, arm, position, created_at) VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id;",
+		userID, r.Systolic, r.Diastolic, nullableInt(r.Pulse), r.Arm, r.Position, r.CreatedAt.UTC(),
+	).Scan(&id)
+	return id, err
+}
+
+// GetBloodPressureReading returns a blood pressure reading by ID, scoped to a user.
+func (d *DB) GetBloodPressureReading(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.BloodPressureReading, error) {
+	r, err := scanBloodPressure(d.sql.QueryRowContext(ctx,
+		"SELECT "+bpColumns+" FROM blood_pressure_readings WHERE id=$1 AND user_id=$2;", id, userID,
+	), userID, loc)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &r, nil
+}
+
+// UpdateBloodPressureReading replaces a blood pressure reading, scoped to a user.
+func (d *DB) UpdateBloodPressureReading(ctx context.Context, userID int64, id int64, r domain.BloodPressureReading) (bool, error) {
+	res, err := d.sql.ExecContext(ctx,
+		"UPDATE blood_pressure_readings SET systolic=$1, diastolic=$2, pulse=$3, arm=$4, position=$5, created_at=$6 WHERE id=$7 AND user_id=$8;",
+		r.Systolic, r.Diastolic, nullableInt(r.Pulse), r.Arm, r.Position, r.CreatedAt.UTC(), id, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteBloodPressureReading removes a blood pressure reading by ID, scoped to a user.
+func (d *DB) DeleteBloodPressureReading(ctx context.Context, userID int64, id int64) (bool, error) {
+	res, err := d.sql.ExecContext(ctx, "DELETE FROM blood_pressure_readings WHERE id=$1 AND user_id=$2;", id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ListBloodPressureReadings returns the readings matching q for a user, newest first.
+func (d *DB) ListBloodPressureReadings(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.BloodPressureReading, error) {
+	clause, args := eventQuerySQL("created_at", userID, q)
+	rows, err := d.sql.QueryContext(ctx, "SELECT "+bpColumns+" FROM blood_pressure_readings"+clause+";", args...) //nolint:gosec // clause is built from constant fragments
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	out := make([]domain.BloodPressureReading, 0, q.Limit)
+	for rows.Next() {
+		r, err := scanBloodPressure(rows, userID, loc)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// BloodPressureAveragesForLocalDays returns the mean reading per local day in
+// the inclusive range [fromDay, toDay] for a user, grouped in a single query.
+func (d *DB) BloodPressureAveragesForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]domain.BloodPressureAverage, error) {
+	win, err := localDayWindows(fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.sql.QueryContext(ctx,
+		`SELECT d.day, AVG(b.systolic), AVG(b.diastolic), AVG(b.pulse), COUNT(*)
+		FROM unnest($2::text[], $3::timestamptz[], $4::timestamptz[]) AS d(day, start_at, end_at)
+		JOIN blood_pressure_readings b ON b.user_id=$1 AND b.created_at >= d.start_at AND b.created_at < d.end_at
+		GROUP BY d.day;`,
+		append([]any{userID}, win.args()...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	out := make(map[string]domain.BloodPressureAverage)
+	for rows.Next() {
+		var day string
+		var avg domain.BloodPressureAverage
+		var pulse sql.NullFloat64
+		if err := rows.Scan(&day, &avg.Systolic, &avg.Diastolic, &pulse, &avg.Readings); err != nil {
+			return nil, err
+		}
+		if pulse.Valid {
+			avg.Pulse = &pulse.Float64
+		}
+		out[day] = avg
+	}
+	return out, rows.Err()
+}
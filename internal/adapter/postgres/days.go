@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	"vitals/internal/domain"
+)
+
+// dayWindows holds parallel arrays describing consecutive local days, suitable
+// for unnest() in a range query.
+type dayWindows struct {
+	days   []string
+	starts []string
+	ends   []string
+}
+
+// localDayWindows builds one [start, end) UTC window per local day in loc for
+// the inclusive range [fromDay, toDay].
+func localDayWindows(fromDay, toDay string, loc *time.Location) (*dayWindows, error) {
+	from, err := time.ParseInLocation("2006-01-02", fromDay, loc)
+	if err != nil {
+		return nil, err
+	}
+	to, err := time.ParseInLocation("2006-01-02", toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+	if to.Before(from) {
+		return nil, errors.New("toDay must not be before fromDay")
+	}
+
+	w := &dayWindows{}
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if len(w.days) >= domain.MaxDayRange {
+			return nil, errors.New("day range too large")
+		}
+		w.days = append(w.days, d.Format("2006-01-02"))
+		w.starts = append(w.starts, d.UTC().Format(time.RFC3339))
+		w.ends = append(w.ends, d.AddDate(0, 0, 1).UTC().Format(time.RFC3339))
+	}
+	return w, nil
+}
+
+// args returns the windows as query arguments for $2, $3 and $4.
+func (w *dayWindows) args() []any {
+	return []any{pq.Array(w.days), pq.Array(w.starts), pq.Array(w.ends)}
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+// windowWeightRepo and windowWaterRepo answer per-day queries by building the
+// day windows the real repositories would, so that services can be checked
+// against the adapter's range limit without a database.
+type windowWeightRepo struct {
+	domain.WeightRepository
+}
+
+func (windowWeightRepo) WeightsForLocalDays(_ context.Context, _ int64, fromDay, toDay string, _ domain.WeightPolicy, loc *time.Location) (map[string]domain.WeightEntry, error) {
+	_, err := localDayWindows(fromDay, toDay, loc)
+	return nil, err
+}
+
+type windowWaterRepo struct {
+	domain.WaterRepository
+}
+
+func (windowWaterRepo) WaterTotalsForLocalDays(_ context.Context, _ int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error) {
+	_, err := localDayWindows(fromDay, toDay, loc)
+	return nil, err
+}
+
+func TestLocalDayWindows_Limit(t *testing.T) {
+	from := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, domain.MaxDayRange-1)
+	w, err := localDayWindows(from.Format("2006-01-02"), to.Format("2006-01-02"), time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(w.days) != domain.MaxDayRange {
+		t.Errorf("expected %d windows, got %d", domain.MaxDayRange, len(w.days))
+	}
+	if _, err := localDayWindows(from.Format("2006-01-02"), to.AddDate(0, 0, 1).Format("2006-01-02"), time.UTC); err == nil {
+		t.Error("expected an error for a range over the limit")
+	}
+}
+
+func TestLocalDayWindows_BucketedCharts(t *testing.T) {
+	svc := app.NewChartsService(windowWeightRepo{}, windowWaterRepo{})
+	ctx := context.Background()
+
+	// Day buckets are not widened, so their total length is the longest
+	// range GetBuckets allows.
+	points, err := svc.GetBuckets(ctx, 1, app.ChartQuery{Days: 1 << 20, Unit: "kg", Bucket: app.BucketDay}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	days := 0
+	for _, p := range points {
+		days += p.Days
+	}
+
+	// A longest range starting on December 31st is widened by almost a year
+	// when bucketed by year.
+	start := time.Date(2004, 12, 31, 0, 0, 0, 0, time.UTC)
+	q := app.ChartQuery{Days: days, To: start.AddDate(0, 0, days-1).Format("2006-01-02"), Unit: "kg"}
+	for _, bucket := range []string{app.BucketDay, app.BucketWeek, app.BucketMonth, app.BucketYear} {
+		q.Bucket = bucket
+		if _, err := svc.GetBuckets(ctx, 1, q, time.UTC); err != nil {
+			t.Errorf("bucket=%s over %d days: %v", bucket, days, err)
+		}
+	}
+}
+
+func TestLocalDayWindows_WaterWeightCorrelation(t *testing.T) {
+	svc := app.NewAnalyticsService(windowWeightRepo{}, windowWaterRepo{})
+	// The weights are fetched for Lag+1 days beyond the longest range.
+	q := app.CorrelationQuery{Days: 1 << 20, To: "2024-06-30", Lag: app.MaxCorrelationLag, Unit: "kg"}
+	c, err := svc.GetWaterWeightCorrelation(context.Background(), 1, q, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.To != "2024-06-30" || c.Samples != 0 {
+		t.Errorf("unexpected result %+v", c)
+	}
+}
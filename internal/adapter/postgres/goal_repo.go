@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"vitals/internal/domain"
+)
+
+// GetWeightGoal returns the weight goal of a user, or nil if none is set.
+func (d *DB) GetWeightGoal(ctx context.Context, userID int64) (*domain.WeightGoal, error) {
+	g := domain.WeightGoal{UserID: userID}
+	err := d.sql.QueryRowContext(ctx,
+		"SELECT target_value, unit, target_date, start_value, created_at FROM weight_goals WHERE user_id=$1;", userID,
+	).Scan(&g.TargetValue, &g.Unit, &g.TargetDate, &g.StartValue, &g.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &g, nil
+}
+
+// SetWeightGoal stores the weight goal of a user, replacing any existing one.
+func (d *DB) SetWeightGoal(ctx context.Context, userID int64, g domain.WeightGoal) error {
+	_, err := d.sql.ExecContext(ctx,
+		`INSERT INTO weight_goals(user_id, target_value, unit, target_date, start_value, created_at) VALUES($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET target_value=EXCLUDED.target_value, unit=EXCLUDED.unit,
+			target_date=EXCLUDED.target_date, start_value=EXCLUDED.start_value, created_at=EXCLUDED.created_at;`,
+		userID, g.TargetValue, g.Unit, g.TargetDate, g.StartValue, g.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// DeleteWeightGoal removes the weight goal of a user.
+func (d *DB) DeleteWeightGoal(ctx context.Context, userID int64) (bool, error) {
+	res, err := d.sql.ExecContext(ctx, "DELETE FROM weight_goals WHERE user_id=$1;", userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
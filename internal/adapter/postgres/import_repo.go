@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"vitals/internal/domain"
+)
+
+// ImportEvents adds the weight and water events to a user's history in a
+// single transaction.
+func (d *DB) ImportEvents(ctx context.Context, userID int64, weights []domain.WeightEntry, water []domain.WaterEvent) error {
+	return d.inTx(ctx, func(tx *sql.Tx) error {
+		return insertEvents(ctx, tx, userID, weights, water)
+	})
+}
+
+// RestoreAccount updates a user's preferences, deletes their weight and water
+// events and adds the given ones in a single transaction.
+func (d *DB) RestoreAccount(ctx context.Context, userID int64, weights []domain.WeightEntry, water []domain.WaterEvent, p domain.ProfileUpdate) (bool, error) {
+	var restored bool
+	err := d.inTx(ctx, func(tx *sql.Tx) error {
+		if query, args := profileUpdateSQL(userID, p); query != "" {
+			res, err := tx.ExecContext(ctx, query, args...) //nolint:gosec // the columns are constants
+			if err != nil {
+				return err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			restored = n > 0
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM weight_events WHERE user_id=$1;", userID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM water_events WHERE user_id=$1;", userID); err != nil {
+			return err
+		}
+		return insertEvents(ctx, tx, userID, weights, water)
+	})
+	return restored && err == nil, err
+}
+
+// inTx runs fn in a transaction, committing if it succeeds and rolling back
+// otherwise.
+func (d *DB) inTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := d.sql.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertEvents adds the events to a user's history within tx.
+func insertEvents(ctx context.Context, tx *sql.Tx, userID int64, weights []domain.WeightEntry, water []domain.WaterEvent) error {
+	weightStmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO weight_events(user_id, value, unit, body_fat_pct, muscle_mass, water_pct, bone_mass, visceral_fat, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9);`)
+	if err != nil {
+		return err
+	}
+	defer weightStmt.Close() //nolint:errcheck
+	for _, e := range weights {
+		c := e.BodyComposition
+		if _, err := weightStmt.ExecContext(ctx, userID, e.Value, e.Unit,
+			c.BodyFatPercent, c.MuscleMass, c.WaterPercent, c.BoneMass, c.VisceralFat,
+			e.CreatedAt.UTC(),
+		); err != nil {
+			return err
+		}
+	}
+
+	waterStmt, err := tx.PrepareContext(ctx,
+		"INSERT INTO water_events(user_id, delta_liters, created_at) VALUES($1, $2, $3);")
+	if err != nil {
+		return err
+	}
+	defer waterStmt.Close() //nolint:errcheck
+	for _, e := range water {
+		if _, err := waterStmt.ExecContext(ctx, userID, e.DeltaLiters, e.CreatedAt.UTC()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
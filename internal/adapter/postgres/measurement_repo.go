@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+const measurementColumns = "id, site, value, unit, created_at"
+
+// scanMeasurement scans a row selected with measurementColumns.
+func scanMeasurement(row interface{ Scan(...any) error }, userID int64, loc *time.Location) (domain.Measurement, error) {
+	var m domain.Measurement
+	if err := row.Scan(&m.ID, &m.Site, &m.Value, &m.Unit, &m.CreatedAt); err != nil {
+		return m, err
+	}
+	m.UserID = userID
+	m.Day = m.CreatedAt.In(loc).Format("2006-01-02")
+	return m, nil
+}
+
+// AddMeasurement inserts a new body measurement.
+func (d *DB) AddMeasurement(ctx context.Context, userID int64, site string, value float64, unit string, createdAt time.Time) (int64, error) {
+	var id int64
+	err := d.sql.QueryRowContext(ctx,
+		"INSERT INTO body_measurements(user_id, site, value, unit, created_at) VALUES($1, $2, $3, $4, $5) RETURNING id;",
+		userID, site, value, unit, createdAt.UTC(),
+	).Scan(&id)
+	return id, err
+}
+
+// GetMeasurement returns a body measurement by ID, scoped to a user.
+func (d *DB) GetMeasurement(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.Measurement, error) {
+	m, err := scanMeasurement(d.sql.QueryRowContext(ctx,
+		"SELECT "+measurementColumns+" FROM body_measurements WHERE id=$1 AND user_id=$2;", id, userID,
+	), userID, loc)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// UpdateMeasurement replaces a body measurement's site, value, unit and
+// timestamp, scoped to a user.
+func (d *DB) UpdateMeasurement(ctx context.Context, userID int64, id int64, site string, value float64, unit string, createdAt time.Time) (bool, error) {
+	res, err := d.sql.ExecContext(ctx,
+		"UPDATE body_measurements SET site=$1, value=$2, unit=$3, created_at=$4 WHERE id=$5 AND user_id=$6;",
+		site, value, unit, createdAt.UTC(), id, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteMeasurement removes a body measurement by ID, scoped to a user.
+func (d *DB) DeleteMeasurement(ctx context.Context, userID int64, id int64) (bool, error) {
+	res, err := d.sql.ExecContext(ctx, "DELETE FROM body_measurements WHERE id=$1 AND user_id=$2;", id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ListMeasurements returns the body measurements matching q for a user,
+// newest first, optionally restricted to one site.
+func (d *DB) ListMeasurements(ctx context.Context, userID int64, site string, q domain.EventQuery, loc *time.Location) ([]domain.Measurement, error) {
+	var filters []columnEquals
+	if site != "" {
+		filters = append(filters, columnEquals{Column: "site", Value: site})
+	}
+	clause, args := eventQuerySQL("created_at", userID, q, filters...)
+	rows, err := d.sql.QueryContext(ctx, "SELECT "+measurementColumns+" FROM body_measurements"+clause+";", args...) //nolint:gosec // clause is built from constant fragments
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	out := make([]domain.Measurement, 0, q.Limit)
+	for rows.Next() {
+		m, err := scanMeasurement(rows, userID, loc)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// LatestMeasurementsForLocalDays returns the most recent measurement per site
+// and local day in the inclusive range [fromDay, toDay] for a user, selected
+// in a single query.
+func (d *DB) LatestMeasurementsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]map[string]domain.Measurement, error) {
+	win, err := localDayWindows(fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.sql.QueryContext(ctx,
+		`SELECT DISTINCT ON (d.day, m.site) d.day, m.id, m.site, m.value, m.unit, m.created_at
+		FROM unnest($2::text[], $3::timestamptz[], $4::timestamptz[]) AS d(day, start_at, end_at)
+		JOIN body_measurements m ON m.user_id=$1 AND m.created_at >= d.start_at AND m.created_at < d.end_at
+		ORDER BY d.day, m.site, m.created_at DESC, m.id DESC;`,
+		append([]any{userID}, win.args()...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	out := make(map[string]map[string]domain.Measurement)
+	for rows.Next() {
+		var m domain.Measurement
+		if err := rows.Scan(&m.Day, &m.ID, &m.Site, &m.Value, &m.Unit, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		m.UserID = userID
+		if out[m.Day] == nil {
+			out[m.Day] = make(map[string]domain.Measurement)
+		}
+		out[m.Day][m.Site] = m
+	}
+	return out, rows.Err()
+}
@@ -54,6 +54,13 @@ func (d *DB) migrate(ctx context.Context) error {
 		"CREATE TABLE IF NOT EXISTS users (id BIGSERIAL PRIMARY KEY, username TEXT UNIQUE NOT NULL, password_hash TEXT NOT NULL, created_at TIMESTAMPTZ NOT NULL);",
 		"CREATE TABLE IF NOT EXISTS sessions (token TEXT PRIMARY KEY, user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE, expires_at TIMESTAMPTZ NOT NULL, created_at TIMESTAMPTZ NOT NULL);",
 		"CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);",
+		"CREATE TABLE IF NOT EXISTS blood_pressure_readings (id BIGSERIAL PRIMARY KEY, user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE, systolic INTEGER NOT NULL, diastolic INTEGER NOT NULL, pulse INTEGER, arm TEXT NOT NULL DEFAULT '', position TEXT NOT NULL DEFAULT '', created_at TIMESTAMPTZ NOT NULL);",
+		"CREATE INDEX IF NOT EXISTS idx_blood_pressure_readings_user_created ON blood_pressure_readings(user_id, created_at);",
+		"CREATE TABLE IF NOT EXISTS sleep_sessions (id BIGSERIAL PRIMARY KEY, user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE, bed_at TIMESTAMPTZ NOT NULL, wake_at TIMESTAMPTZ NOT NULL, quality INTEGER CHECK(quality BETWEEN 1 AND 5), interruptions INTEGER NOT NULL DEFAULT 0, created_at TIMESTAMPTZ NOT NULL, CHECK(wake_at > bed_at));",
+		"CREATE INDEX IF NOT EXISTS idx_sleep_sessions_user_wake ON sleep_sessions(user_id, wake_at);",
+		"CREATE TABLE IF NOT EXISTS body_measurements (id BIGSERIAL PRIMARY KEY, user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE, site TEXT NOT NULL, value DOUBLE PRECISION NOT NULL, unit TEXT NOT NULL CHECK(unit IN ('cm','in')), created_at TIMESTAMPTZ NOT NULL);",
+		"CREATE INDEX IF NOT EXISTS idx_body_measurements_user_created ON body_measurements(user_id, created_at);",
+		"CREATE TABLE IF NOT EXISTS weight_goals (user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE, target_value DOUBLE PRECISION NOT NULL, unit TEXT NOT NULL CHECK(unit IN ('kg','lb')), target_date TEXT NOT NULL DEFAULT '', created_at TIMESTAMPTZ NOT NULL);",
 	}
 
 	for _, stmt := range stmts {
@@ -70,6 +77,15 @@ func (d *DB) migrate(ctx context.Context) error {
 		"CREATE INDEX IF NOT EXISTS idx_water_events_user_id ON water_events(user_id);",
 		"ALTER TABLE sessions ADD COLUMN IF NOT EXISTS user_agent TEXT;",
 		"ALTER TABLE sessions ADD COLUMN IF NOT EXISTS ip TEXT;",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT '';",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS water_goal_liters DOUBLE PRECISION NOT NULL DEFAULT 0;",
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS weight_policy TEXT NOT NULL DEFAULT '';",
+		"ALTER TABLE weight_events ADD COLUMN IF NOT EXISTS body_fat_pct DOUBLE PRECISION;",
+		"ALTER TABLE weight_events ADD COLUMN IF NOT EXISTS muscle_mass DOUBLE PRECISION;",
+		"ALTER TABLE weight_events ADD COLUMN IF NOT EXISTS water_pct DOUBLE PRECISION;",
+		"ALTER TABLE weight_events ADD COLUMN IF NOT EXISTS bone_mass DOUBLE PRECISION;",
+		"ALTER TABLE weight_events ADD COLUMN IF NOT EXISTS visceral_fat DOUBLE PRECISION;",
+		"ALTER TABLE weight_goals ADD COLUMN IF NOT EXISTS start_value DOUBLE PRECISION;",
 	}
 	for _, stmt := range alterStmts {
 		if _, err := d.sql.ExecContext(ctx, stmt); err != nil {
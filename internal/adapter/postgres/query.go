@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"vitals/internal/domain"
+)
+
+// columnEquals is an extra equality condition for eventQuerySQL. Column must
+// be a constant.
+type columnEquals struct {
+	Column string
+	Value  any
+}
+
+// eventQuerySQL renders the WHERE, ORDER BY and LIMIT clauses for q against a
+// table with user_id and id columns, using timeCol as the event time. userID
+// is always $1. Each filter further restricts the rows.
+func eventQuerySQL(timeCol string, userID int64, q domain.EventQuery, filters ...columnEquals) (string, []any) {
+	args := []any{userID}
+	conds := []string{"user_id=$1"}
+
+	for _, f := range filters {
+		args = append(args, f.Value)
+		conds = append(conds, fmt.Sprintf("%s=$%d", f.Column, len(args)))
+	}
+
+	if !q.From.IsZero() {
+		args = append(args, q.From.UTC())
+		conds = append(conds, fmt.Sprintf("%s >= $%d", timeCol, len(args)))
+	}
+	if !q.To.IsZero() {
+		op := "<"
+		if q.ToInclusive {
+			op = "<="
+		}
+		args = append(args, q.To.UTC())
+		conds = append(conds, fmt.Sprintf("%s %s $%d", timeCol, op, len(args)))
+	}
+	if q.After != nil {
+		args = append(args, q.After.CreatedAt.UTC(), q.After.ID)
+		conds = append(conds, fmt.Sprintf("(%s, id) < ($%d, $%d)", timeCol, len(args)-1, len(args)))
+	}
+	args = append(args, q.Limit)
+
+	return fmt.Sprintf(" WHERE %s ORDER BY %s DESC, id DESC LIMIT $%d",
+		strings.Join(conds, " AND "), timeCol, len(args)), args
+}
+
+// nullableInt converts an optional integer to a query argument.
+func nullableInt(v *int) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*v), Valid: true}
+}
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+func TestEventQuerySQL_ToBound(t *testing.T) {
+	to := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		inclusive bool
+		want      string
+	}{
+		{false, "created_at < $2"},
+		{true, "created_at <= $2"},
+	} {
+		sql, args := eventQuerySQL("created_at", 1, domain.EventQuery{To: to, ToInclusive: tc.inclusive, Limit: 10})
+		if !strings.Contains(sql, tc.want) {
+			t.Errorf("inclusive=%v: expected %q in %q", tc.inclusive, tc.want, sql)
+		}
+		// The bound is passed as is, so an event stamped exactly at To
+		// matches an inclusive bound despite microsecond storage.
+		if got, ok := args[1].(time.Time); !ok || !got.Equal(to) {
+			t.Errorf("inclusive=%v: expected the bound %v, got %v", tc.inclusive, to, args[1])
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+const sleepColumns = "id, bed_at, wake_at, quality, interruptions"
+
+// scanSleep scans a row selected with sleepColumns.
+func scanSleep(row interface{ Scan(...any) error }, userID int64, loc *time.Location) (domain.SleepSession, error) {
+	var s domain.SleepSession
+	var quality sql.NullInt64
+	if err := row.Scan(&s.ID, &s.Bedtime, &s.WakeTime, &quality, &s.Interruptions); err != nil {
+		return s, err
+	}
+	if quality.Valid {
+		q := int(quality.Int64)
+		s.Quality = &q
+	}
+	s.UserID = userID
+	s.Day = s.WakeDay(loc)
+	return s, nil
+}
+
+// AddSleepSession inserts a new sleep session.
+func (d *DB) AddSleepSession(ctx context.Context, userID int64, s domain.SleepSession) (int64, error) {
+	var id int64
+	err := d.sql.QueryRowContext(ctx,
+		"INSERT INTO sleep_sessions(user_id, bed_at, wake_at, quality, interruptions, created_at) VALUES($1, $2, $3, $4, $5, $6) RETURNING id;",
+		userID, s.Bedtime.UTC(), s.WakeTime.UTC(), nullableInt(s.Quality), s.Interruptions, time.Now().UTC(),
+	).Scan(&id)
+	return id, err
+}
+
+// GetSleepSession returns a sleep session by ID, scoped to a user.
+func (d *DB) GetSleepSession(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.SleepSession, error) {
+	s, err := scanSleep(d.sql.QueryRowContext(ctx,
+		"SELECT "+sleepColumns+" FROM sleep_sessions WHERE id=$1 AND user_id=$2;", id, userID,
+	), userID, loc)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// UpdateSleepSession replaces a sleep session, scoped to a user.
+func (d *DB) UpdateSleepSession(ctx context.Context, userID int64, id int64, s domain.SleepSession) (bool, error) {
+	res, err := d.sql.ExecContext(ctx,
+		"UPDATE sleep_sessions SET bed_at=$1, wake_at=$2, quality=$3, interruptions=$4 WHERE id=$5 AND user_id=$6;",
+		s.Bedtime.UTC(), s.WakeTime.UTC(), nullableInt(s.Quality), s.Interruptions, id, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteSleepSession removes a sleep session by ID, scoped to a user.
+func (d *DB) DeleteSleepSession(ctx context.Context, userID int64, id int64) (bool, error) {
+	res, err := d.sql.ExecContext(ctx, "DELETE FROM sleep_sessions WHERE id=$1 AND user_id=$2;", id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ListSleepSessions returns the sessions matching q by wake time for a user,
+// newest first.
+func (d *DB) ListSleepSessions(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.SleepSession, error) {
+	clause, args := eventQuerySQL("wake_at", userID, q)
+	rows, err := d.sql.QueryContext(ctx, "SELECT "+sleepColumns+" FROM sleep_sessions"+clause+";", args...) //nolint:gosec // clause is built from constant fragments
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	out := make([]domain.SleepSession, 0, q.Limit)
+	for rows.Next() {
+		s, err := scanSleep(rows, userID, loc)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// SleepMinutesForLocalDays returns the total minutes slept per wake day in the
+// inclusive range [fromDay, toDay] for a user, grouped in a single query.
+func (d *DB) SleepMinutesForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error) {
+	win, err := localDayWindows(fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.sql.QueryContext(ctx,
+		`SELECT d.day, SUM(EXTRACT(EPOCH FROM s.wake_at - s.bed_at)) / 60
+		FROM unnest($2::text[], $3::timestamptz[], $4::timestamptz[]) AS d(day, start_at, end_at)
+		JOIN sleep_sessions s ON s.user_id=$1 AND s.wake_at >= d.start_at AND s.wake_at < d.end_at
+		GROUP BY d.day;`,
+		append([]any{userID}, win.args()...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	out := make(map[string]float64)
+	for rows.Next() {
+		var day string
+		var minutes float64
+		if err := rows.Scan(&day, &minutes); err != nil {
+			return nil, err
+		}
+		out[day] = minutes
+	}
+	return out, rows.Err()
+}
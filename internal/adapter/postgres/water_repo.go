@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"time"
 
 	"vitals/internal/domain"
@@ -18,15 +20,49 @@ func (d *DB) AddWaterEvent(ctx context.Context, userID int64, deltaLiters float6
 }
 
 // DeleteWaterEvent removes a water event by ID, scoped to a user.
-func (d *DB) DeleteWaterEvent(ctx context.Context, userID int64, id int64) error {
-	_, err := d.sql.ExecContext(ctx, "DELETE FROM water_events WHERE id=$1 AND user_id=$2;", id, userID)
-	return err
+func (d *DB) DeleteWaterEvent(ctx context.Context, userID int64, id int64) (bool, error) {
+	res, err := d.sql.ExecContext(ctx, "DELETE FROM water_events WHERE id=$1 AND user_id=$2;", id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetWaterEvent returns a water event by ID, scoped to a user.
+func (d *DB) GetWaterEvent(ctx context.Context, userID int64, id int64) (*domain.WaterEvent, error) {
+	var e domain.WaterEvent
+	err := d.sql.QueryRowContext(ctx,
+		"SELECT id, delta_liters, created_at FROM water_events WHERE id=$1 AND user_id=$2;", id, userID,
+	).Scan(&e.ID, &e.DeltaLiters, &e.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	e.UserID = userID
+	return &e, nil
+}
+
+// UpdateWaterEvent replaces a water event's delta and timestamp, scoped to a user.
+func (d *DB) UpdateWaterEvent(ctx context.Context, userID int64, id int64, deltaLiters float64, createdAt time.Time) (bool, error) {
+	res, err := d.sql.ExecContext(ctx,
+		"UPDATE water_events SET delta_liters=$1, created_at=$2 WHERE id=$3 AND user_id=$4;",
+		deltaLiters, createdAt.UTC(), id, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
 }
 
-// ListRecentWaterEvents returns the most recent water events up to limit for a user.
+// ListRecentWaterEvents returns the most recently logged water events up to
+// limit for a user, in reverse insertion order.
 func (d *DB) ListRecentWaterEvents(ctx context.Context, userID int64, limit int) ([]domain.WaterEvent, error) {
 	rows, err := d.sql.QueryContext(ctx,
-		"SELECT id, delta_liters, created_at FROM water_events WHERE user_id=$1 ORDER BY created_at DESC LIMIT $2;", userID, limit)
+		"SELECT id, delta_liters, created_at FROM water_events WHERE user_id=$1 ORDER BY id DESC LIMIT $2;", userID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -44,13 +80,34 @@ func (d *DB) ListRecentWaterEvents(ctx context.Context, userID int64, limit int)
 	return out, rows.Err()
 }
 
+// ListWaterEvents returns the water events matching q for a user, newest first.
+func (d *DB) ListWaterEvents(ctx context.Context, userID int64, q domain.EventQuery) ([]domain.WaterEvent, error) {
+	clause, args := eventQuerySQL("created_at", userID, q)
+	rows, err := d.sql.QueryContext(ctx, "SELECT id, delta_liters, created_at FROM water_events"+clause+";", args...) //nolint:gosec // clause is built from constant fragments
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	out := make([]domain.WaterEvent, 0, q.Limit)
+	for rows.Next() {
+		var e domain.WaterEvent
+		if err := rows.Scan(&e.ID, &e.DeltaLiters, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		e.UserID = userID
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
 // WaterTotalForLocalDay returns the total water intake for a local calendar day for a user.
-func (d *DB) WaterTotalForLocalDay(ctx context.Context, userID int64, localDay string) (float64, error) {
-	dayStart, err := time.ParseInLocation("2006-01-02", localDay, time.Local)
+func (d *DB) WaterTotalForLocalDay(ctx context.Context, userID int64, localDay string, loc *time.Location) (float64, error) {
+	dayStart, err := time.ParseInLocation("2006-01-02", localDay, loc)
 	if err != nil {
 		return 0, err
 	}
-	dayEnd := dayStart.Add(24 * time.Hour)
+	dayEnd := dayStart.AddDate(0, 0, 1)
 
 	var total float64
 	err = d.sql.QueryRowContext(ctx,
@@ -59,3 +116,76 @@ func (d *DB) WaterTotalForLocalDay(ctx context.Context, userID int64, localDay s
 	).Scan(&total)
 	return total, err
 }
+
+// WaterTotalsForLocalDays returns the total water intake per local day in the
+// inclusive range [fromDay, toDay] for a user, grouped in a single query.
+func (d *DB) WaterTotalsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error) {
+	win, err := localDayWindows(fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.sql.QueryContext(ctx,
+		`SELECT d.day, SUM(w.delta_liters)
+		FROM unnest($2::text[], $3::timestamptz[], $4::timestamptz[]) AS d(day, start_at, end_at)
+		JOIN water_events w ON w.user_id=$1 AND w.created_at >= d.start_at AND w.created_at < d.end_at
+		GROUP BY d.day;`,
+		append([]any{userID}, win.args()...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	out := make(map[string]float64)
+	for rows.Next() {
+		var day string
+		var total float64
+		if err := rows.Scan(&day, &total); err != nil {
+			return nil, err
+		}
+		out[day] = total
+	}
+	return out, rows.Err()
+}
+
+// WaterTotalsByWeekdayHour returns the total water intake per local day of
+// the week and hour of the day in the inclusive range [fromDay, toDay] for a
+// user. Events are summed per UTC quarter hour in a single grouped query, a
+// granularity at which every zone offset maps each slot to one local hour.
+func (d *DB) WaterTotalsByWeekdayHour(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (domain.WeekdayHourTotals, error) {
+	var out domain.WeekdayHourTotals
+	from, err := time.ParseInLocation("2006-01-02", fromDay, loc)
+	if err != nil {
+		return out, err
+	}
+	to, err := time.ParseInLocation("2006-01-02", toDay, loc)
+	if err != nil {
+		return out, err
+	}
+	if to.Before(from) {
+		return out, errors.New("toDay must not be before fromDay")
+	}
+
+	rows, err := d.sql.QueryContext(ctx,
+		`SELECT to_timestamp(floor(extract(epoch FROM created_at) / 900) * 900) AS slot, SUM(delta_liters)
+		FROM water_events WHERE user_id=$1 AND created_at >= $2 AND created_at < $3
+		GROUP BY slot;`,
+		userID, from.UTC(), to.AddDate(0, 0, 1).UTC(),
+	)
+	if err != nil {
+		return out, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	for rows.Next() {
+		var slot time.Time
+		var total float64
+		if err := rows.Scan(&slot, &total); err != nil {
+			return out, err
+		}
+		local := slot.In(loc)
+		out[local.Weekday()][local.Hour()] += total
+	}
+	return out, rows.Err()
+}
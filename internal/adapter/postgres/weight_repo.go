@@ -4,25 +4,41 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"vitals/internal/domain"
 )
 
+const weightColumns = "id, value, unit, body_fat_pct, muscle_mass, water_pct, bone_mass, visceral_fat, created_at"
+
+// weightDest returns scan destinations for the columns in weightColumns.
+func weightDest(e *domain.WeightEntry) []any {
+	return []any{
+		&e.ID, &e.Value, &e.Unit,
+		&e.BodyFatPercent, &e.MuscleMass, &e.WaterPercent, &e.BoneMass, &e.VisceralFat,
+		&e.CreatedAt,
+	}
+}
+
 // AddWeightEvent inserts a new weight event.
-func (d *DB) AddWeightEvent(ctx context.Context, userID int64, value float64, unit string, createdAt time.Time) (int64, error) {
+func (d *DB) AddWeightEvent(ctx context.Context, userID int64, value float64, unit string, comp domain.BodyComposition, createdAt time.Time) (int64, error) {
 	var id int64
 	err := d.sql.QueryRowContext(ctx,
-		"INSERT INTO weight_events(user_id, value, unit, created_at) VALUES($1, $2, $3, $4) RETURNING id;",
-		userID, value, unit, createdAt.UTC(),
+		`INSERT INTO weight_events(user_id, value, unit, body_fat_pct, muscle_mass, water_pct, bone_mass, visceral_fat, created_at)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id;`,
+		userID, value, unit,
+		comp.BodyFatPercent, comp.MuscleMass, comp.WaterPercent, comp.BoneMass, comp.VisceralFat,
+		createdAt.UTC(),
 	).Scan(&id)
 	return id, err
 }
 
-// DeleteLatestWeightEvent removes the most recent weight event for a user.
+// DeleteLatestWeightEvent removes the most recently logged weight event for a
+// user, which need not be the latest by timestamp once entries are backdated.
 func (d *DB) DeleteLatestWeightEvent(ctx context.Context, userID int64) (bool, error) {
 	var id int64
-	err := d.sql.QueryRowContext(ctx, "SELECT id FROM weight_events WHERE user_id=$1 ORDER BY created_at DESC LIMIT 1;", userID).Scan(&id)
+	err := d.sql.QueryRowContext(ctx, "SELECT id FROM weight_events WHERE user_id=$1 ORDER BY id DESC LIMIT 1;", userID).Scan(&id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return false, nil
@@ -33,35 +49,68 @@ func (d *DB) DeleteLatestWeightEvent(ctx context.Context, userID int64) (bool, e
 	return err == nil, err
 }
 
-// LatestWeightForLocalDay returns the most recent weight entry for a local calendar day for a user.
-func (d *DB) LatestWeightForLocalDay(ctx context.Context, userID int64, localDay string) (*domain.WeightEntry, error) {
-	dayStart, err := time.ParseInLocation("2006-01-02", localDay, time.Local)
+// GetWeightEvent returns a weight event by ID, scoped to a user.
+func (d *DB) GetWeightEvent(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.WeightEntry, error) {
+	var e domain.WeightEntry
+	err := d.sql.QueryRowContext(ctx,
+		"SELECT "+weightColumns+" FROM weight_events WHERE id=$1 AND user_id=$2;", id, userID,
+	).Scan(weightDest(&e)...)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
 		return nil, err
 	}
-	dayEnd := dayStart.Add(24 * time.Hour)
+	e.UserID = userID
+	e.Day = e.CreatedAt.In(loc).Format("2006-01-02")
+	return &e, nil
+}
 
-	row := d.sql.QueryRowContext(ctx,
-		"SELECT id, value, unit, created_at FROM weight_events WHERE user_id=$1 AND created_at >= $2 AND created_at < $3 ORDER BY created_at DESC LIMIT 1;",
-		userID, dayStart.UTC(), dayEnd.UTC(),
+// UpdateWeightEvent replaces a weight event's value, unit, composition and
+// timestamp, scoped to a user.
+func (d *DB) UpdateWeightEvent(ctx context.Context, userID int64, id int64, value float64, unit string, comp domain.BodyComposition, createdAt time.Time) (bool, error) {
+	res, err := d.sql.ExecContext(ctx,
+		`UPDATE weight_events SET value=$1, unit=$2, body_fat_pct=$3, muscle_mass=$4, water_pct=$5, bone_mass=$6, visceral_fat=$7, created_at=$8
+		WHERE id=$9 AND user_id=$10;`,
+		value, unit,
+		comp.BodyFatPercent, comp.MuscleMass, comp.WaterPercent, comp.BoneMass, comp.VisceralFat,
+		createdAt.UTC(), id, userID,
 	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
 
-	var e domain.WeightEntry
-	if err := row.Scan(&e.ID, &e.Value, &e.Unit, &e.CreatedAt); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
+// DeleteWeightEvent removes a weight event by ID, scoped to a user.
+func (d *DB) DeleteWeightEvent(ctx context.Context, userID int64, id int64) (bool, error) {
+	res, err := d.sql.ExecContext(ctx, "DELETE FROM weight_events WHERE id=$1 AND user_id=$2;", id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// WeightForLocalDay returns the weight entry representing a local calendar
+// day for a user under policy.
+func (d *DB) WeightForLocalDay(ctx context.Context, userID int64, localDay string, policy domain.WeightPolicy, loc *time.Location) (*domain.WeightEntry, error) {
+	days, err := d.WeightsForLocalDays(ctx, userID, localDay, localDay, policy, loc)
+	if err != nil {
 		return nil, err
 	}
-	e.UserID = userID
-	e.Day = localDay
+	e, ok := days[localDay]
+	if !ok {
+		return nil, nil
+	}
 	return &e, nil
 }
 
 // ListRecentWeightEvents returns the most recent weight events up to limit for a user.
-func (d *DB) ListRecentWeightEvents(ctx context.Context, userID int64, limit int) ([]domain.WeightEntry, error) {
+func (d *DB) ListRecentWeightEvents(ctx context.Context, userID int64, limit int, loc *time.Location) ([]domain.WeightEntry, error) {
 	rows, err := d.sql.QueryContext(ctx,
-		"SELECT id, value, unit, created_at FROM weight_events WHERE user_id=$1 ORDER BY created_at DESC LIMIT $2;", userID, limit)
+		"SELECT "+weightColumns+" FROM weight_events WHERE user_id=$1 ORDER BY created_at DESC LIMIT $2;", userID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -70,11 +119,96 @@ func (d *DB) ListRecentWeightEvents(ctx context.Context, userID int64, limit int
 	out := make([]domain.WeightEntry, 0, limit)
 	for rows.Next() {
 		var e domain.WeightEntry
-		if err := rows.Scan(&e.ID, &e.Value, &e.Unit, &e.CreatedAt); err != nil {
+		if err := rows.Scan(weightDest(&e)...); err != nil {
+			return nil, err
+		}
+		e.UserID = userID
+		e.Day = e.CreatedAt.In(loc).Format("2006-01-02")
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// lbPerKg is the factor converting kg to lb.
+var lbPerKg = domain.ConvertWeight(1, "kg", "lb")
+
+// weightKgSQL converts a weight_events row's value to kg.
+var weightKgSQL = fmt.Sprintf("CASE w.unit WHEN 'lb' THEN w.value / %v ELSE w.value END", lbPerKg)
+
+// weightPolicySQL returns the value expression and the ORDER BY terms that put
+// the entry representing a day first, per daily weight policy.
+func weightPolicySQL(policy domain.WeightPolicy) (value, order string, err error) {
+	const latestFirst = "w.created_at DESC, w.id DESC"
+	switch policy {
+	case domain.WeightPolicyLast:
+		return "w.value", latestFirst, nil
+	case domain.WeightPolicyFirst:
+		return "w.value", "w.created_at, w.id", nil
+	case domain.WeightPolicyMin:
+		return "w.value", weightKgSQL + ", " + latestFirst, nil
+	case domain.WeightPolicyMean:
+		mean := "AVG(" + weightKgSQL + ") OVER (PARTITION BY d.day)"
+		return fmt.Sprintf("CASE w.unit WHEN 'lb' THEN %s * %v ELSE %s END", mean, lbPerKg, mean), latestFirst, nil
+	default:
+		return "", "", fmt.Errorf("unknown weight policy %q", policy)
+	}
+}
+
+// WeightsForLocalDays returns the weight entry representing each local day
+// under policy in the inclusive range [fromDay, toDay] for a user, selected in
+// a single query.
+func (d *DB) WeightsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, policy domain.WeightPolicy, loc *time.Location) (map[string]domain.WeightEntry, error) {
+	value, order, err := weightPolicySQL(policy)
+	if err != nil {
+		return nil, err
+	}
+	win, err := localDayWindows(fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.sql.QueryContext(ctx,
+		`SELECT DISTINCT ON (d.day) d.day, w.id, `+value+`, w.unit,
+			w.body_fat_pct, w.muscle_mass, w.water_pct, w.bone_mass, w.visceral_fat, w.created_at
+		FROM unnest($2::text[], $3::timestamptz[], $4::timestamptz[]) AS d(day, start_at, end_at)
+		JOIN weight_events w ON w.user_id=$1 AND w.created_at >= d.start_at AND w.created_at < d.end_at
+		ORDER BY d.day, `+order+`;`, //nolint:gosec // value and order are constant fragments
+		append([]any{userID}, win.args()...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	out := make(map[string]domain.WeightEntry)
+	for rows.Next() {
+		var e domain.WeightEntry
+		if err := rows.Scan(append([]any{&e.Day}, weightDest(&e)...)...); err != nil {
+			return nil, err
+		}
+		e.UserID = userID
+		out[e.Day] = e
+	}
+	return out, rows.Err()
+}
+
+// ListWeightEvents returns the weight events matching q for a user, newest first.
+func (d *DB) ListWeightEvents(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.WeightEntry, error) {
+	clause, args := eventQuerySQL("created_at", userID, q)
+	rows, err := d.sql.QueryContext(ctx, "SELECT "+weightColumns+" FROM weight_events"+clause+";", args...) //nolint:gosec // clause is built from constant fragments
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	out := make([]domain.WeightEntry, 0, q.Limit)
+	for rows.Next() {
+		var e domain.WeightEntry
+		if err := rows.Scan(weightDest(&e)...); err != nil {
 			return nil, err
 		}
 		e.UserID = userID
-		e.Day = e.CreatedAt.In(time.Local).Format("2006-01-02")
+		e.Day = e.CreatedAt.In(loc).Format("2006-01-02")
 		out = append(out, e)
 	}
 	return out, rows.Err()
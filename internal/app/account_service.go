@@ -0,0 +1,220 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// Takeout archive identification. TakeoutVersion is bumped whenever the
+// archive gains or changes fields; older versions remain importable.
+const (
+	TakeoutFormat  = "vitals-takeout"
+	TakeoutVersion = 1
+)
+
+// Restore modes.
+const (
+	// RestoreMerge adds the archived events that are not already present.
+	RestoreMerge = "merge"
+	// RestoreReplace replaces the user's weight and water events and profile
+	// with the archived ones.
+	RestoreReplace = "replace"
+)
+
+// Takeout is a portable archive of a user's account.
+type Takeout struct {
+	Format       string          `json:"format"`
+	Version      int             `json:"version"`
+	ExportedAt   time.Time       `json:"exportedAt"`
+	Profile      TakeoutProfile  `json:"profile"`
+	WeightEvents []TakeoutWeight `json:"weightEvents"`
+	WaterEvents  []TakeoutWater  `json:"waterEvents"`
+}
+
+// TakeoutProfile holds the user's preferences. Empty values mean the
+// defaults.
+type TakeoutProfile struct {
+	Username        string              `json:"username"`
+	Timezone        string              `json:"timezone"`
+	WaterGoalLiters float64             `json:"waterGoalLiters"`
+	WeightPolicy    domain.WeightPolicy `json:"weightPolicy"`
+}
+
+// TakeoutWeight is an archived weigh-in.
+type TakeoutWeight struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+	Unit  string    `json:"unit"`
+	domain.BodyComposition
+}
+
+// TakeoutWater is an archived water intake event.
+type TakeoutWater struct {
+	Time        time.Time `json:"time"`
+	DeltaLiters float64   `json:"deltaLiters"`
+}
+
+// RestoreResult reports the outcome of restoring a Takeout.
+type RestoreResult struct {
+	Mode string `json:"mode"`
+	// Weights and Water count the events added.
+	Weights int `json:"weights"`
+	Water   int `json:"water"`
+	// Duplicates counts the archived events skipped by a merge because they
+	// were already present.
+	Duplicates int `json:"duplicates"`
+	// ProfileRestored reports whether the archived preferences were applied.
+	// A replace applies them unless the user has no stored profile, as the
+	// development user without authentication does not.
+	ProfileRestored bool `json:"profileRestored"`
+}
+
+// AccountService encapsulates whole-account export and restore.
+type AccountService struct {
+	weightRepo domain.WeightRepository
+	waterRepo  domain.WaterRepository
+	imports    domain.ImportRepository
+}
+
+// NewAccountService creates an AccountService backed by the given
+// repositories.
+func NewAccountService(wr domain.WeightRepository, wa domain.WaterRepository, imports domain.ImportRepository) *AccountService {
+	return &AccountService{weightRepo: wr, waterRepo: wa, imports: imports}
+}
+
+// Export returns an archive of the user's profile and of all their weight
+// and water events, oldest first.
+func (s *AccountService) Export(ctx context.Context, user *domain.User) (*Takeout, error) {
+	weights, water, err := eventHistory(ctx, s.weightRepo, s.waterRepo, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Takeout{
+		Format:     TakeoutFormat,
+		Version:    TakeoutVersion,
+		ExportedAt: time.Now().UTC(),
+		Profile: TakeoutProfile{
+			Username:        user.Username,
+			Timezone:        user.Timezone,
+			WaterGoalLiters: user.WaterGoalLiters,
+			WeightPolicy:    user.DailyWeightPolicy,
+		},
+		WeightEvents: make([]TakeoutWeight, len(weights)),
+		WaterEvents:  make([]TakeoutWater, len(water)),
+	}
+	for i, e := range weights {
+		t.WeightEvents[len(weights)-1-i] = TakeoutWeight{Time: e.CreatedAt.UTC(), Value: e.Value, Unit: e.Unit, BodyComposition: e.BodyComposition}
+	}
+	for i, e := range water {
+		t.WaterEvents[len(water)-1-i] = TakeoutWater{Time: e.CreatedAt.UTC(), DeltaLiters: e.DeltaLiters}
+	}
+	return t, nil
+}
+
+// Restore validates the archive and loads it into the user's account. A
+// merge adds the archived events that do not duplicate an existing one and
+// leaves the profile alone; a replace swaps all weight and water events for
+// the archived ones and applies the archived profile in one repository
+// transaction. Nothing is changed if the archive is invalid, in which case the
+// error matches ErrInvalidImport, or if storing it fails.
+func (s *AccountService) Restore(ctx context.Context, userID int64, t *Takeout, mode string) (*RestoreResult, error) {
+	if mode == "" {
+		mode = RestoreMerge
+	}
+	if mode != RestoreMerge && mode != RestoreReplace {
+		return nil, invalidImport(errors.New("mode must be \"merge\" or \"replace\""))
+	}
+	weights, water, err := t.events()
+	if err != nil {
+		return nil, invalidImport(err)
+	}
+	res := &RestoreResult{Mode: mode}
+
+	if mode == RestoreReplace {
+		restored, err := s.imports.RestoreAccount(ctx, userID, weights, water, t.Profile.update())
+		if err != nil {
+			return nil, err
+		}
+		res.Weights, res.Water = len(weights), len(water)
+		res.ProfileRestored = restored
+		return res, nil
+	}
+
+	existingWeights, existingWater, err := eventHistory(ctx, s.weightRepo, s.waterRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	seen := newEventSet(existingWeights, existingWater)
+	var newWeights []domain.WeightEntry
+	for _, e := range weights {
+		if seen.addWeight(e) {
+			newWeights = append(newWeights, e)
+		}
+	}
+	var newWater []domain.WaterEvent
+	for _, e := range water {
+		if seen.addWater(e) {
+			newWater = append(newWater, e)
+		}
+	}
+	res.Weights, res.Water = len(newWeights), len(newWater)
+	res.Duplicates = len(weights) + len(water) - res.Weights - res.Water
+	if res.Weights+res.Water > 0 {
+		if err := s.imports.ImportEvents(ctx, userID, newWeights, newWater); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// update returns the change that sets every preference to the archived one.
+func (p TakeoutProfile) update() domain.ProfileUpdate {
+	return domain.ProfileUpdate{
+		Timezone:        &p.Timezone,
+		WaterGoalLiters: &p.WaterGoalLiters,
+		WeightPolicy:    &p.WeightPolicy,
+	}
+}
+
+// events validates the archive and returns its events.
+func (t *Takeout) events() ([]domain.WeightEntry, []domain.WaterEvent, error) {
+	if t.Format != TakeoutFormat {
+		return nil, nil, fmt.Errorf("format must be %q", TakeoutFormat)
+	}
+	if t.Version < 1 || t.Version > TakeoutVersion {
+		return nil, nil, fmt.Errorf("unsupported takeout version %d; this server reads versions 1 to %d", t.Version, TakeoutVersion)
+	}
+	if err := ValidateProfile(t.Profile.update()); err != nil {
+		return nil, nil, fmt.Errorf("profile: %w", err)
+	}
+
+	weights := make([]domain.WeightEntry, len(t.WeightEvents))
+	for i, e := range t.WeightEvents {
+		if e.Time.IsZero() {
+			return nil, nil, fmt.Errorf("weightEvents[%d]: time is required", i)
+		}
+		if err := validateWeight(e.Value, e.Unit); err != nil {
+			return nil, nil, fmt.Errorf("weightEvents[%d]: %w", i, err)
+		}
+		if err := validateComposition(e.Value, e.BodyComposition); err != nil {
+			return nil, nil, fmt.Errorf("weightEvents[%d]: %w", i, err)
+		}
+		weights[i] = domain.WeightEntry{Value: e.Value, Unit: e.Unit, BodyComposition: e.BodyComposition, CreatedAt: e.Time}
+	}
+	water := make([]domain.WaterEvent, len(t.WaterEvents))
+	for i, e := range t.WaterEvents {
+		if e.Time.IsZero() {
+			return nil, nil, fmt.Errorf("waterEvents[%d]: time is required", i)
+		}
+		if err := validateWaterDelta(e.DeltaLiters); err != nil {
+			return nil, nil, fmt.Errorf("waterEvents[%d]: %w", i, err)
+		}
+		water[i] = domain.WaterEvent{DeltaLiters: e.DeltaLiters, CreatedAt: e.Time}
+	}
+	return weights, water, nil
+}
@@ -0,0 +1,175 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+// historyRepos returns mocks holding a weigh-in and a water event at each of
+// the given times, newest first.
+func historyRepos(times ...time.Time) (*mockWeightRepo, *mockWaterRepo) {
+	var weights []domain.WeightEntry
+	var water []domain.WaterEvent
+	for i, at := range times {
+		weights = append(weights, domain.WeightEntry{ID: int64(i + 1), Value: 80, Unit: "kg", CreatedAt: at})
+		water = append(water, domain.WaterEvent{ID: int64(i + 1), DeltaLiters: 0.5, CreatedAt: at})
+	}
+	return &mockWeightRepo{
+		queryFn: func(_ context.Context, _ int64, _ domain.EventQuery, _ *time.Location) ([]domain.WeightEntry, error) {
+			return weights, nil
+		},
+	}, &mockWaterRepo{
+		queryFn: func(_ context.Context, _ int64, _ domain.EventQuery) ([]domain.WaterEvent, error) {
+			return water, nil
+		},
+	}
+}
+
+func TestAccountExport(t *testing.T) {
+	newer := time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)
+	older := newer.AddDate(0, 0, -1)
+	wr, wa := historyRepos(newer, older)
+	svc := app.NewAccountService(wr, wa, &mockImportRepo{})
+
+	user := &domain.User{ID: 1, Username: "ada", Timezone: "Europe/London", WaterGoalLiters: 3, DailyWeightPolicy: domain.WeightPolicyMin}
+	tk, err := svc.Export(context.Background(), user)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tk.Format != app.TakeoutFormat || tk.Version != app.TakeoutVersion {
+		t.Errorf("unexpected format %q version %d", tk.Format, tk.Version)
+	}
+	want := app.TakeoutProfile{Username: "ada", Timezone: "Europe/London", WaterGoalLiters: 3, WeightPolicy: domain.WeightPolicyMin}
+	if tk.Profile != want {
+		t.Errorf("expected profile %+v, got %+v", want, tk.Profile)
+	}
+	if len(tk.WeightEvents) != 2 || !tk.WeightEvents[0].Time.Equal(older) || len(tk.WaterEvents) != 2 || !tk.WaterEvents[1].Time.Equal(newer) {
+		t.Errorf("expected both kinds of events oldest first, got %+v and %+v", tk.WeightEvents, tk.WaterEvents)
+	}
+}
+
+func TestAccountRestore_Merge(t *testing.T) {
+	existing := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	wr, wa := historyRepos(existing)
+	var gotWeights []domain.WeightEntry
+	var gotWater []domain.WaterEvent
+	imports := &mockImportRepo{
+		importFn: func(_ context.Context, _ int64, weights []domain.WeightEntry, water []domain.WaterEvent) error {
+			gotWeights, gotWater = weights, water
+			return nil
+		},
+		restoreFn: func(context.Context, int64, []domain.WeightEntry, []domain.WaterEvent, domain.ProfileUpdate) (bool, error) {
+			t.Fatal("a merge must not replace events or the profile")
+			return false, nil
+		},
+	}
+	svc := app.NewAccountService(wr, wa, imports)
+
+	tk := &app.Takeout{
+		Format:  app.TakeoutFormat,
+		Version: app.TakeoutVersion,
+		Profile: app.TakeoutProfile{Timezone: "Europe/Berlin"},
+		WeightEvents: []app.TakeoutWeight{
+			{Time: existing, Value: 80, Unit: "kg"},
+			{Time: existing, Value: 81, Unit: "kg"},
+			{Time: existing.Add(time.Hour), Value: 80, Unit: "kg"},
+		},
+		WaterEvents: []app.TakeoutWater{
+			{Time: existing, DeltaLiters: 0.5},
+			{Time: existing.Add(time.Hour), DeltaLiters: 0.25},
+			{Time: existing.Add(time.Hour), DeltaLiters: 0.25},
+		},
+	}
+	res, err := svc.Restore(context.Background(), 1, tk, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Mode != app.RestoreMerge || res.Weights != 2 || res.Water != 1 || res.Duplicates != 3 || res.ProfileRestored {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if len(gotWeights) != 2 || gotWeights[0].Value != 81 || len(gotWater) != 1 || gotWater[0].DeltaLiters != 0.25 {
+		t.Errorf("unexpected events imported: %+v, %+v", gotWeights, gotWater)
+	}
+}
+
+func TestAccountRestore_Replace(t *testing.T) {
+	var replaced int
+	var stored *domain.ProfileUpdate
+	var storeErr error
+	imports := &mockImportRepo{
+		restoreFn: func(_ context.Context, _ int64, weights []domain.WeightEntry, water []domain.WaterEvent, p domain.ProfileUpdate) (bool, error) {
+			if storeErr != nil {
+				return false, storeErr
+			}
+			replaced, stored = len(weights)+len(water), &p
+			return true, nil
+		},
+	}
+	svc := app.NewAccountService(&mockWeightRepo{}, &mockWaterRepo{}, imports)
+
+	tk := &app.Takeout{
+		Format:       app.TakeoutFormat,
+		Version:      1,
+		Profile:      app.TakeoutProfile{Timezone: "Europe/Berlin", WaterGoalLiters: 2.5, WeightPolicy: domain.WeightPolicyMean},
+		WeightEvents: []app.TakeoutWeight{{Time: time.Now().AddDate(-5, 0, 0), Value: 180, Unit: "lb"}},
+	}
+	res, err := svc.Restore(context.Background(), 1, tk, app.RestoreReplace)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if replaced != 1 || res.Weights != 1 || !res.ProfileRestored {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if stored == nil || *stored.Timezone != "Europe/Berlin" || *stored.WaterGoalLiters != 2.5 || *stored.WeightPolicy != domain.WeightPolicyMean {
+		t.Errorf("expected the archived profile, got %+v", stored)
+	}
+
+	storeErr = errors.New("connection refused")
+	if res, err := svc.Restore(context.Background(), 1, tk, app.RestoreReplace); !errors.Is(err, storeErr) || res != nil {
+		t.Errorf("expected the storage error, got %+v, %v", res, err)
+	}
+}
+
+func TestAccountRestore_Invalid(t *testing.T) {
+	imports := &mockImportRepo{
+		importFn: func(context.Context, int64, []domain.WeightEntry, []domain.WaterEvent) error {
+			t.Fatal("nothing should be imported from an invalid archive")
+			return nil
+		},
+		restoreFn: func(context.Context, int64, []domain.WeightEntry, []domain.WaterEvent, domain.ProfileUpdate) (bool, error) {
+			t.Fatal("nothing should be replaced from an invalid archive")
+			return false, nil
+		},
+	}
+	svc := app.NewAccountService(&mockWeightRepo{}, &mockWaterRepo{}, imports)
+
+	at := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	valid := func() app.Takeout {
+		return app.Takeout{Format: app.TakeoutFormat, Version: app.TakeoutVersion}
+	}
+	for _, tc := range []struct {
+		name   string
+		mode   string
+		modify func(*app.Takeout)
+	}{
+		{"mode", "overwrite", func(*app.Takeout) {}},
+		{"format", app.RestoreReplace, func(tk *app.Takeout) { tk.Format = "other" }},
+		{"future version", app.RestoreReplace, func(tk *app.Takeout) { tk.Version = app.TakeoutVersion + 1 }},
+		{"timezone", app.RestoreReplace, func(tk *app.Takeout) { tk.Profile.Timezone = "Mars/Olympus" }},
+		{"weight", app.RestoreMerge, func(tk *app.Takeout) {
+			tk.WeightEvents = []app.TakeoutWeight{{Time: at, Value: 80, Unit: "kg"}, {Time: at, Value: 80, Unit: "st"}}
+		}},
+		{"water time", app.RestoreMerge, func(tk *app.Takeout) { tk.WaterEvents = []app.TakeoutWater{{DeltaLiters: 0.5}} }},
+	} {
+		tk := valid()
+		tc.modify(&tk)
+		if _, err := svc.Restore(context.Background(), 1, &tk, tc.mode); !errors.Is(err, app.ErrInvalidImport) {
+			t.Errorf("%s: expected an invalid import error, got %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// AnalyticsService encapsulates use cases that look for patterns across the
+// logged history.
+type AnalyticsService struct {
+	weightRepo domain.WeightRepository
+	waterRepo  domain.WaterRepository
+}
+
+// NewAnalyticsService creates an AnalyticsService backed by the given
+// repositories.
+func NewAnalyticsService(wr domain.WeightRepository, wa domain.WaterRepository) *AnalyticsService {
+	return &AnalyticsService{weightRepo: wr, waterRepo: wa}
+}
+
+// HeatmapQuery selects the range of a heatmap. The range is given as in
+// ChartQuery, up to about twenty years.
+type HeatmapQuery struct {
+	Days int
+	From string
+	To   string
+}
+
+// heatmapWeekdays lists the rows of a WaterHeatmap, Monday first.
+var heatmapWeekdays = [7]time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+// WaterHeatmap holds water intake over a range of local days broken down by
+// day of the week and hour of the day. Rows follow Weekdays, Monday first, and
+// columns are the local hours 0 to 23. Days after today are left out of the
+// range.
+type WaterHeatmap struct {
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Days     int       `json:"days"`
+	Weekdays [7]string `json:"weekdays"`
+	// Occurrences counts how often each weekday falls within the range.
+	Occurrences [7]int `json:"occurrences"`
+	// Total is the intake in liters logged in each cell over the range.
+	Total [7][24]float64 `json:"total"`
+	// Mean is Total divided by the weekday's occurrences, the typical intake
+	// in that hour of that weekday.
+	Mean [7][24]float64 `json:"mean"`
+}
+
+// GetWaterHeatmap returns the user's water intake by weekday and hour for the
+// range selected by q in loc, fetched with one repository call.
+func (s *AnalyticsService) GetWaterHeatmap(ctx context.Context, userID int64, q HeatmapQuery, loc *time.Location) (*WaterHeatmap, error) {
+	first, days, err := resolveDayRange(q.Days, q.From, q.To, maxBucketDays, loc)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	days = min(days, daysBetween(first, today)+1)
+	h := &WaterHeatmap{}
+	for i, wd := range heatmapWeekdays {
+		h.Weekdays[i] = wd.String()[:3]
+	}
+	if days < 1 {
+		return h, nil
+	}
+	h.From = first.Format("2006-01-02")
+	h.To = first.AddDate(0, 0, days-1).Format("2006-01-02")
+	h.Days = days
+
+	totals, err := s.waterRepo.WaterTotalsByWeekdayHour(ctx, userID, h.From, h.To, loc)
+	if err != nil {
+		return nil, err
+	}
+	// Each weekday occurs once per full week plus once more for each of the
+	// leftover days, which start at the range's first weekday.
+	for i, wd := range heatmapWeekdays {
+		h.Occurrences[i] = days / 7
+		if (int(wd)-int(first.Weekday())+7)%7 < days%7 {
+			h.Occurrences[i]++
+		}
+		h.Total[i] = totals[wd]
+		for hour, total := range totals[wd] {
+			if h.Occurrences[i] > 0 {
+				h.Mean[i][hour] = total / float64(h.Occurrences[i])
+			}
+		}
+	}
+	return h, nil
+}
+
+// MaxCorrelationLag is the largest lag, in days, between a day's water intake
+// and the weight change it is compared with.
+const MaxCorrelationLag = 3
+
+// minCorrelationSamples is the fewest samples a coefficient is computed from.
+const minCorrelationSamples = 3
+
+// CorrelationQuery selects the range, lag and unit of a WaterWeightCorrelation.
+// The range is given as in ChartQuery, up to about twenty years.
+type CorrelationQuery struct {
+	Days int
+	From string
+	To   string
+	// Lag is the number of days, 0 to MaxCorrelationLag, from a day's water
+	// intake to the start of the weight change it is paired with.
+	Lag int
+	// Unit is the weight unit, "kg" or "lb".
+	Unit string
+	// WeightPolicy selects each day's weight; empty means
+	// domain.DefaultWeightPolicy.
+	WeightPolicy domain.WeightPolicy
+}
+
+// WaterWeightCorrelation relates daily water intake to the change in weight
+// over the following day. Days after today are left out of the range.
+type WaterWeightCorrelation struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Lag  int    `json:"lag"`
+	Unit string `json:"unit"`
+	// Samples is the number of Points.
+	Samples int `json:"samples"`
+	// Coefficient is the Pearson correlation of the points, or nil if there
+	// are too few or either series is constant.
+	Coefficient *float64           `json:"coefficient"`
+	Points      []CorrelationPoint `json:"points"`
+}
+
+// CorrelationPoint pairs a day's water intake with a weight change.
+type CorrelationPoint struct {
+	// Day is the day of the water intake.
+	Day         string  `json:"day"`
+	WaterLiters float64 `json:"waterLiters"`
+	// WeightChange is the weight, in the requested unit, on the day Lag+1
+	// days after Day minus that on the day before it.
+	WeightChange float64 `json:"weightChange"`
+}
+
+// GetWaterWeightCorrelation correlates the water intake of each day in the
+// range selected by q in loc with the weight change q.Lag days later. A day
+// is a sample if water was logged on it and both days of the weight change
+// have a weight; days without water are taken as untracked rather than dry.
+// Each series is fetched with one repository call.
+func (s *AnalyticsService) GetWaterWeightCorrelation(ctx context.Context, userID int64, q CorrelationQuery, loc *time.Location) (*WaterWeightCorrelation, error) {
+	if q.Unit != "kg" && q.Unit != "lb" {
+		return nil, errors.New("unit must be \"kg\" or \"lb\"")
+	}
+	if q.Lag < 0 || q.Lag > MaxCorrelationLag {
+		return nil, fmt.Errorf("lag must be between 0 and %d", MaxCorrelationLag)
+	}
+	policy, err := resolveWeightPolicy(q.WeightPolicy)
+	if err != nil {
+		return nil, err
+	}
+	// maxBucketDays leaves room below domain.MaxDayRange for the Lag+1 days
+	// the weights are fetched beyond the range.
+	first, days, err := resolveDayRange(q.Days, q.From, q.To, maxBucketDays, loc)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	days = min(days, daysBetween(first, today)+1)
+	c := &WaterWeightCorrelation{Lag: q.Lag, Unit: q.Unit, Points: []CorrelationPoint{}}
+	if days < 1 {
+		return c, nil
+	}
+	last := first.AddDate(0, 0, days-1)
+	c.From = first.Format("2006-01-02")
+	c.To = last.Format("2006-01-02")
+
+	water, err := s.waterRepo.WaterTotalsForLocalDays(ctx, userID, c.From, c.To, loc)
+	if err != nil {
+		return nil, err
+	}
+	// The weight change paired with the range's last day ends Lag+1 days on.
+	weights, err := s.weightRepo.WeightsForLocalDays(ctx, userID, first.AddDate(0, 0, q.Lag).Format("2006-01-02"),
+		last.AddDate(0, 0, q.Lag+1).Format("2006-01-02"), policy, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < days; i++ {
+		day := first.AddDate(0, 0, i)
+		liters := water[day.Format("2006-01-02")]
+		if liters <= 0 {
+			continue
+		}
+		before, ok := weights[day.AddDate(0, 0, q.Lag).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		after, ok := weights[day.AddDate(0, 0, q.Lag+1).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		c.Points = append(c.Points, CorrelationPoint{
+			Day:          day.Format("2006-01-02"),
+			WaterLiters:  liters,
+			WeightChange: domain.ConvertWeight(after.Value, after.Unit, q.Unit) - domain.ConvertWeight(before.Value, before.Unit, q.Unit),
+		})
+	}
+	c.Samples = len(c.Points)
+	c.Coefficient = pearson(c.Points)
+	return c, nil
+}
+
+// pearson returns the Pearson correlation coefficient of the points' water
+// intake and weight change, or nil if it is undefined or there are fewer than
+// minCorrelationSamples points.
+func pearson(points []CorrelationPoint) *float64 {
+	n := len(points)
+	if n < minCorrelationSamples {
+		return nil
+	}
+	var meanX, meanY float64
+	for _, p := range points {
+		meanX += p.WaterLiters
+		meanY += p.WeightChange
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var cov, varX, varY float64
+	for _, p := range points {
+		dx, dy := p.WaterLiters-meanX, p.WeightChange-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+	r := cov / math.Sqrt(varX*varY)
+	return &r
+}
@@ -0,0 +1,157 @@
+package app_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+func TestGetWaterHeatmap(t *testing.T) {
+	var gotFrom, gotTo string
+	wa := &mockWaterRepo{
+		hourlyFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (domain.WeekdayHourTotals, error) {
+			gotFrom, gotTo = from, to
+			var totals domain.WeekdayHourTotals
+			totals[time.Monday][8] = 1.5
+			totals[time.Sunday][21] = 0.5
+			totals[time.Wednesday][12] = 0.75
+			return totals, nil
+		},
+	}
+
+	// 2024-03-04 is a Monday; the range has two Mondays and one Sunday.
+	svc := app.NewAnalyticsService(&mockWeightRepo{}, wa)
+	h, err := svc.GetWaterHeatmap(context.Background(), 1, app.HeatmapQuery{From: "2024-03-04", To: "2024-03-11"}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotFrom != "2024-03-04" || gotTo != "2024-03-11" || h.Days != 8 {
+		t.Fatalf("unexpected range %s..%s (%d days)", gotFrom, gotTo, h.Days)
+	}
+	if h.Weekdays[0] != "Mon" || h.Weekdays[6] != "Sun" {
+		t.Errorf("expected Monday-first rows, got %v", h.Weekdays)
+	}
+	if h.Occurrences != [7]int{2, 1, 1, 1, 1, 1, 1} {
+		t.Errorf("unexpected occurrences %v", h.Occurrences)
+	}
+	if h.Total[0][8] != 1.5 || h.Mean[0][8] != 0.75 {
+		t.Errorf("expected Monday 8:00 total 1.5 and mean 0.75, got %v and %v", h.Total[0][8], h.Mean[0][8])
+	}
+	if h.Total[6][21] != 0.5 || h.Mean[6][21] != 0.5 {
+		t.Errorf("expected Sunday 21:00 total and mean 0.5, got %v and %v", h.Total[6][21], h.Mean[6][21])
+	}
+	if h.Mean[2][12] != 0.75 {
+		t.Errorf("expected Wednesday 12:00 mean 0.75, got %v", h.Mean[2][12])
+	}
+}
+
+func TestGetWaterHeatmap_Range(t *testing.T) {
+	svc := app.NewAnalyticsService(&mockWeightRepo{}, &mockWaterRepo{})
+	if _, err := svc.GetWaterHeatmap(context.Background(), 1, app.HeatmapQuery{From: "2024-03-10", To: "2024-03-01"}, time.UTC); err == nil {
+		t.Error("expected an error for a reversed range")
+	}
+
+	h, err := svc.GetWaterHeatmap(context.Background(), 1, app.HeatmapQuery{Days: 14}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.Days != 14 || h.Occurrences != [7]int{2, 2, 2, 2, 2, 2, 2} {
+		t.Errorf("expected two of each weekday over 14 days, got %d days and %v", h.Days, h.Occurrences)
+	}
+	if h.To != time.Now().UTC().Format("2006-01-02") {
+		t.Errorf("expected range to end today, got %s", h.To)
+	}
+
+	future := time.Now().UTC().AddDate(0, 0, 10).Format("2006-01-02")
+	h, err = svc.GetWaterHeatmap(context.Background(), 1, app.HeatmapQuery{From: future, To: future}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if h.Days != 0 || h.From != "" {
+		t.Errorf("expected an empty heatmap for a future range, got %+v", h)
+	}
+}
+
+func TestGetWaterWeightCorrelation(t *testing.T) {
+	// Weight falls by a quarter kg for every liter drunk the day before,
+	// except that 2024-03-04 has no weigh-in and 2024-03-06 no water.
+	water := map[string]float64{
+		"2024-03-01": 1, "2024-03-02": 2, "2024-03-03": 3, "2024-03-04": 2, "2024-03-05": 1,
+	}
+	weights := map[string]domain.WeightEntry{}
+	weight := 80.0
+	for _, day := range []string{"2024-03-01", "2024-03-02", "2024-03-03", "2024-03-04", "2024-03-05", "2024-03-06", "2024-03-07"} {
+		if day != "2024-03-04" {
+			weights[day] = domain.WeightEntry{Day: day, Value: weight, Unit: "kg"}
+		}
+		weight -= water[day] / 4
+	}
+
+	for _, tc := range []struct {
+		lag      int
+		wantFrom string
+		wantTo   string
+		samples  int
+	}{
+		{0, "2024-03-01", "2024-03-08", 3},
+		{2, "2024-03-03", "2024-03-10", 2},
+	} {
+		var gotFrom, gotTo string
+		wr := &mockWeightRepo{
+			rangeFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+				gotFrom, gotTo = from, to
+				return weights, nil
+			},
+		}
+		wa := &mockWaterRepo{
+			totalsFn: func(_ context.Context, _ int64, _, _ string, _ *time.Location) (map[string]float64, error) {
+				return water, nil
+			},
+		}
+
+		svc := app.NewAnalyticsService(wr, wa)
+		c, err := svc.GetWaterWeightCorrelation(context.Background(), 1, app.CorrelationQuery{
+			From: "2024-03-01",
+			To:   "2024-03-07",
+			Lag:  tc.lag,
+			Unit: "kg",
+		}, time.UTC)
+		if err != nil {
+			t.Fatalf("lag %d: unexpected error: %v", tc.lag, err)
+		}
+		if gotFrom != tc.wantFrom || gotTo != tc.wantTo {
+			t.Errorf("lag %d: unexpected weight window %s..%s", tc.lag, gotFrom, gotTo)
+		}
+		if c.Samples != tc.samples || len(c.Points) != tc.samples {
+			t.Fatalf("lag %d: expected %d samples, got %+v", tc.lag, tc.samples, c.Points)
+		}
+		if tc.lag == 0 {
+			if c.Points[0].Day != "2024-03-01" || c.Points[0].WeightChange != -0.25 {
+				t.Errorf("unexpected first point %+v", c.Points[0])
+			}
+			if c.Coefficient == nil || math.Abs(*c.Coefficient+1) > 1e-9 {
+				t.Errorf("expected a perfect negative correlation, got %v", c.Coefficient)
+			}
+		} else if c.Coefficient != nil {
+			t.Errorf("lag %d: expected no coefficient from two samples, got %v", tc.lag, *c.Coefficient)
+		}
+	}
+}
+
+func TestGetWaterWeightCorrelation_Invalid(t *testing.T) {
+	svc := app.NewAnalyticsService(&mockWeightRepo{}, &mockWaterRepo{})
+	for _, q := range []app.CorrelationQuery{
+		{Days: 30, Lag: 4, Unit: "kg"},
+		{Days: 30, Lag: -1, Unit: "kg"},
+		{Days: 30, Unit: "st"},
+		{From: "2024-03-10", To: "2024-03-01", Unit: "kg"},
+	} {
+		if _, err := svc.GetWaterWeightCorrelation(context.Background(), 1, q, time.UTC); err == nil {
+			t.Errorf("expected an error for %+v", q)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package app
+package app_test
 
 import (
 	"context"
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"vitals/internal/app"
 	"vitals/internal/domain"
 
 	"golang.org/x/crypto/bcrypt"
@@ -18,6 +19,7 @@ type mockUserRepo struct {
 	getByIDFn       func(ctx context.Context, id int64) (*domain.User, error)
 	createFn        func(ctx context.Context, username, passwordHash string) (*domain.User, error)
 	countFn         func(ctx context.Context) (int, error)
+	updateProfileFn func(ctx context.Context, id int64, p domain.ProfileUpdate) error
 }
 
 func (m *mockUserRepo) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
@@ -48,6 +50,15 @@ func (m *mockUserRepo) Count(ctx context.Context) (int, error) {
 	return 0, nil
 }
 
+func (m *mockUserRepo) UpdateProfile(ctx context.Context, id int64, p domain.ProfileUpdate) (*domain.User, error) {
+	if m.updateProfileFn != nil {
+		if err := m.updateProfileFn(ctx, id, p); err != nil {
+			return nil, err
+		}
+	}
+	return &domain.User{ID: id}, nil
+}
+
 type mockSessionRepo struct {
 	createFn        func(ctx context.Context, userID int64, token, userAgent, ip string, expiresAt time.Time) error
 	getByTokenFn    func(ctx context.Context, token string) (*domain.Session, error)
@@ -110,7 +121,7 @@ func TestAuthService_Login_Success(t *testing.T) {
 		},
 	}
 
-	svc := NewAuthService(users, sessions)
+	svc := app.NewAuthService(users, sessions)
 	// Add userAgent=testUserAgent, ip="127.0.0.1"
 	token, err := svc.Login(ctx, "testuser", password, testUserAgent, "127.0.0.1")
 
@@ -137,11 +148,11 @@ func TestAuthService_Login_InvalidPassword(t *testing.T) {
 	}
 
 	sessions := &mockSessionRepo{}
-	svc := NewAuthService(users, sessions)
+	svc := app.NewAuthService(users, sessions)
 
 	// Add userAgent=testUserAgent, ip="127.0.0.1"
 	_, err := svc.Login(ctx, "testuser", "wrongpass", testUserAgent, "127.0.0.1")
-	if err != ErrInvalidCredentials {
+	if err != app.ErrInvalidCredentials {
 		t.Errorf("expected ErrInvalidCredentials, got %v", err)
 	}
 }
@@ -171,7 +182,7 @@ func TestAuthService_ValidateSession_Valid(t *testing.T) {
 		},
 	}
 
-	svc := NewAuthService(users, sessions)
+	svc := app.NewAuthService(users, sessions)
 	// Add userAgent=testUserAgent
 	user, err := svc.ValidateSession(ctx, token, userAgent)
 
@@ -205,11 +216,11 @@ func TestAuthService_ValidateSession_Expired(t *testing.T) {
 	}
 
 	users := &mockUserRepo{}
-	svc := NewAuthService(users, sessions)
+	svc := app.NewAuthService(users, sessions)
 
 	// Add userAgent=testUserAgent
 	_, err := svc.ValidateSession(ctx, token, userAgent)
-	if err != ErrSessionExpired {
+	if err != app.ErrSessionExpired {
 		t.Errorf("expected ErrSessionExpired, got %v", err)
 	}
 	if !deleted {
@@ -229,10 +240,10 @@ func TestAuthService_ValidateSession_NotFound(t *testing.T) {
 	}
 
 	users := &mockUserRepo{}
-	svc := NewAuthService(users, sessions)
+	svc := app.NewAuthService(users, sessions)
 
 	_, err := svc.ValidateSession(ctx, token, userAgent)
-	if err != ErrSessionNotFound {
+	if err != app.ErrSessionNotFound {
 		t.Errorf("expected ErrSessionNotFound, got %v", err)
 	}
 }
@@ -248,10 +259,10 @@ func TestAuthService_Login_UserNotFound(t *testing.T) {
 
 	sessions := &mockSessionRepo{}
 
-	svc := NewAuthService(users, sessions)
+	svc := app.NewAuthService(users, sessions)
 
 	_, err := svc.Login(ctx, "nonexistent", "password", "agent", "127.0.0.1")
-	if err != ErrInvalidCredentials {
+	if err != app.ErrInvalidCredentials {
 		t.Errorf("expected ErrInvalidCredentials, got %v", err)
 	}
 }
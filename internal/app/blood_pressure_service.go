@@ -0,0 +1,215 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// Plausible ranges for blood pressure readings, in mmHg and beats per minute.
+const (
+	minSystolic  = 60
+	maxSystolic  = 300
+	minDiastolic = 30
+	maxDiastolic = 200
+	minPulse     = 20
+	maxPulse     = 250
+)
+
+// maxReadingsPerDay caps the readings returned for a single day.
+const maxReadingsPerDay = 100
+
+// BloodPressureService encapsulates blood-pressure-tracking use cases.
+type BloodPressureService struct {
+	repo domain.BloodPressureRepository
+}
+
+// NewBloodPressureService creates a BloodPressureService backed by the given
+// repository.
+func NewBloodPressureService(repo domain.BloodPressureRepository) *BloodPressureService {
+	return &BloodPressureService{repo: repo}
+}
+
+// GetToday returns the readings taken on the given local day in loc, newest
+// first.
+func (s *BloodPressureService) GetToday(ctx context.Context, userID int64, today string, loc *time.Location) ([]domain.BloodPressureReading, error) {
+	start, err := time.ParseInLocation("2006-01-02", today, loc)
+	if err != nil {
+		return nil, err
+	}
+	q := domain.EventQuery{From: start, To: start.AddDate(0, 0, 1), Limit: maxReadingsPerDay}
+	readings, err := s.repo.ListBloodPressureReadings(ctx, userID, q, loc)
+	if err != nil {
+		return nil, err
+	}
+	return classifyReadings(readings), nil
+}
+
+// RecordReading validates and stores a new reading at the time described by
+// at, returning the stored reading with its ID, local day and category.
+func (s *BloodPressureService) RecordReading(ctx context.Context, userID int64, r domain.BloodPressureReading, at EntryTime, loc *time.Location) (*domain.BloodPressureReading, error) {
+	if err := validateBloodPressure(r); err != nil {
+		return nil, err
+	}
+	createdAt, err := at.resolve(time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+	r.CreatedAt = createdAt
+	if r.ID, err = s.repo.AddBloodPressureReading(ctx, userID, r); err != nil {
+		return nil, err
+	}
+	r.UserID = userID
+	r.Day = createdAt.In(loc).Format("2006-01-02")
+	r.Category = domain.ClassifyBloodPressure(float64(r.Systolic), float64(r.Diastolic))
+	return &r, nil
+}
+
+// ListRecent returns a newest-first page of readings selected by opts, with
+// days computed in loc, and the cursor of the next page if any.
+func (s *BloodPressureService) ListRecent(ctx context.Context, userID int64, opts ListOptions, loc *time.Location) ([]domain.BloodPressureReading, string, error) {
+	q, err := opts.query(loc)
+	if err != nil {
+		return nil, "", err
+	}
+	readings, err := s.repo.ListBloodPressureReadings(ctx, userID, q, loc)
+	if err != nil {
+		return nil, "", err
+	}
+	readings, next := nextPage(readings, q.Limit, func(r domain.BloodPressureReading) domain.EventCursor {
+		return domain.EventCursor{CreatedAt: r.CreatedAt, ID: r.ID}
+	})
+	return classifyReadings(readings), next, nil
+}
+
+// UndoLast deletes the most recent reading.
+func (s *BloodPressureService) UndoLast(ctx context.Context, userID int64) (bool, int64, error) {
+	readings, err := s.repo.ListBloodPressureReadings(ctx, userID, domain.EventQuery{Limit: 1}, time.UTC)
+	if err != nil {
+		return false, 0, err
+	}
+	if len(readings) == 0 {
+		return false, 0, nil
+	}
+	if _, err := s.repo.DeleteBloodPressureReading(ctx, userID, readings[0].ID); err != nil {
+		return false, 0, err
+	}
+	return true, readings[0].ID, nil
+}
+
+// BloodPressurePatch holds the fields to change on an existing reading. Nil
+// fields are left unchanged.
+type BloodPressurePatch struct {
+	Systolic  *int
+	Diastolic *int
+	Pulse     *int
+	Arm       *string
+	Position  *string
+	At        *time.Time
+}
+
+// GetReading returns the user's reading with the given ID.
+func (s *BloodPressureService) GetReading(ctx context.Context, userID, id int64, loc *time.Location) (*domain.BloodPressureReading, error) {
+	r, err := s.repo.GetBloodPressureReading(ctx, userID, id, loc)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, ErrEntryNotFound
+	}
+	r.Category = domain.ClassifyBloodPressure(float64(r.Systolic), float64(r.Diastolic))
+	return r, nil
+}
+
+// UpdateReading applies patch to the user's reading with the given ID and
+// returns the updated reading.
+func (s *BloodPressureService) UpdateReading(ctx context.Context, userID, id int64, patch BloodPressurePatch, loc *time.Location) (*domain.BloodPressureReading, error) {
+	r, err := s.GetReading(ctx, userID, id, loc)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Systolic != nil {
+		r.Systolic = *patch.Systolic
+	}
+	if patch.Diastolic != nil {
+		r.Diastolic = *patch.Diastolic
+	}
+	if patch.Pulse != nil {
+		r.Pulse = patch.Pulse
+	}
+	if patch.Arm != nil {
+		r.Arm = *patch.Arm
+	}
+	if patch.Position != nil {
+		r.Position = *patch.Position
+	}
+	if err := validateBloodPressure(*r); err != nil {
+		return nil, err
+	}
+	if patch.At != nil {
+		if r.CreatedAt, err = (EntryTime{At: patch.At}).resolve(time.Now(), loc); err != nil {
+			return nil, err
+		}
+	}
+
+	found, err := s.repo.UpdateBloodPressureReading(ctx, userID, id, *r)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrEntryNotFound
+	}
+	r.Day = r.CreatedAt.In(loc).Format("2006-01-02")
+	r.Category = domain.ClassifyBloodPressure(float64(r.Systolic), float64(r.Diastolic))
+	return r, nil
+}
+
+// DeleteReading removes the user's reading with the given ID.
+func (s *BloodPressureService) DeleteReading(ctx context.Context, userID, id int64) error {
+	found, err := s.repo.DeleteBloodPressureReading(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// classifyReadings sets the category of each reading in place.
+func classifyReadings(readings []domain.BloodPressureReading) []domain.BloodPressureReading {
+	for i := range readings {
+		r := &readings[i]
+		r.Category = domain.ClassifyBloodPressure(float64(r.Systolic), float64(r.Diastolic))
+	}
+	return readings
+}
+
+func validateBloodPressure(r domain.BloodPressureReading) error {
+	if r.Systolic < minSystolic || r.Systolic > maxSystolic {
+		return fmt.Errorf("systolic must be between %d and %d", minSystolic, maxSystolic)
+	}
+	if r.Diastolic < minDiastolic || r.Diastolic > maxDiastolic {
+		return fmt.Errorf("diastolic must be between %d and %d", minDiastolic, maxDiastolic)
+	}
+	if r.Diastolic >= r.Systolic {
+		return errors.New("diastolic must be lower than systolic")
+	}
+	if r.Pulse != nil && (*r.Pulse < minPulse || *r.Pulse > maxPulse) {
+		return fmt.Errorf("pulse must be between %d and %d", minPulse, maxPulse)
+	}
+	switch r.Arm {
+	case "", "left", "right":
+	default:
+		return errors.New("arm must be \"left\" or \"right\"")
+	}
+	switch r.Position {
+	case "", "sitting", "standing", "lying":
+	default:
+		return errors.New("position must be \"sitting\", \"standing\" or \"lying\"")
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+type mockBloodPressureRepo struct {
+	addFn    func(ctx context.Context, userID int64, r domain.BloodPressureReading) (int64, error)
+	getFn    func(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.BloodPressureReading, error)
+	updateFn func(ctx context.Context, userID int64, id int64, r domain.BloodPressureReading) (bool, error)
+	delFn    func(ctx context.Context, userID int64, id int64) (bool, error)
+	listFn   func(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.BloodPressureReading, error)
+	avgFn    func(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]domain.BloodPressureAverage, error)
+}
+
+func (m *mockBloodPressureRepo) AddBloodPressureReading(ctx context.Context, userID int64, r domain.BloodPressureReading) (int64, error) {
+	if m.addFn != nil {
+		return m.addFn(ctx, userID, r)
+	}
+	return 1, nil
+}
+
+func (m *mockBloodPressureRepo) GetBloodPressureReading(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.BloodPressureReading, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID, id, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockBloodPressureRepo) UpdateBloodPressureReading(ctx context.Context, userID int64, id int64, r domain.BloodPressureReading) (bool, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, userID, id, r)
+	}
+	return true, nil
+}
+
+func (m *mockBloodPressureRepo) DeleteBloodPressureReading(ctx context.Context, userID int64, id int64) (bool, error) {
+	if m.delFn != nil {
+		return m.delFn(ctx, userID, id)
+	}
+	return true, nil
+}
+
+func (m *mockBloodPressureRepo) ListBloodPressureReadings(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.BloodPressureReading, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, q, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockBloodPressureRepo) BloodPressureAveragesForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]domain.BloodPressureAverage, error) {
+	if m.avgFn != nil {
+		return m.avgFn(ctx, userID, fromDay, toDay, loc)
+	}
+	return nil, nil
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestRecordReading_Validation(t *testing.T) {
+	svc := app.NewBloodPressureService(&mockBloodPressureRepo{})
+	tests := []struct {
+		name string
+		r    domain.BloodPressureReading
+	}{
+		{"systolic too low", domain.BloodPressureReading{Systolic: 40, Diastolic: 30}},
+		{"systolic too high", domain.BloodPressureReading{Systolic: 320, Diastolic: 90}},
+		{"diastolic too low", domain.BloodPressureReading{Systolic: 110, Diastolic: 20}},
+		{"diastolic above systolic", domain.BloodPressureReading{Systolic: 90, Diastolic: 95}},
+		{"pulse out of range", domain.BloodPressureReading{Systolic: 120, Diastolic: 80, Pulse: intPtr(300)}},
+		{"bad arm", domain.BloodPressureReading{Systolic: 120, Diastolic: 80, Arm: "both"}},
+		{"bad position", domain.BloodPressureReading{Systolic: 120, Diastolic: 80, Position: "kneeling"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.RecordReading(context.Background(), 1, tc.r, app.EntryTime{}, time.UTC); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestRecordReading_Success(t *testing.T) {
+	var stored domain.BloodPressureReading
+	repo := &mockBloodPressureRepo{
+		addFn: func(_ context.Context, _ int64, r domain.BloodPressureReading) (int64, error) {
+			stored = r
+			return 9, nil
+		},
+	}
+	svc := app.NewBloodPressureService(repo)
+	in := domain.BloodPressureReading{Systolic: 135, Diastolic: 85, Pulse: intPtr(64), Arm: "right", Position: "sitting"}
+	got, err := svc.RecordReading(context.Background(), 1, in, app.EntryTime{}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ID != 9 || got.Category != domain.BPStage1 || got.Day == "" {
+		t.Errorf("unexpected reading: %+v", got)
+	}
+	if stored.CreatedAt.IsZero() || stored.Systolic != 135 {
+		t.Errorf("unexpected stored reading: %+v", stored)
+	}
+}
+
+func TestUpdateReading(t *testing.T) {
+	repo := &mockBloodPressureRepo{
+		getFn: func(_ context.Context, _ int64, id int64, _ *time.Location) (*domain.BloodPressureReading, error) {
+			return &domain.BloodPressureReading{ID: id, Systolic: 118, Diastolic: 76, CreatedAt: time.Now()}, nil
+		},
+	}
+	svc := app.NewBloodPressureService(repo)
+
+	got, err := svc.UpdateReading(context.Background(), 1, 4, app.BloodPressurePatch{Systolic: intPtr(185)}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Systolic != 185 || got.Diastolic != 76 || got.Category != domain.BPCrisis {
+		t.Errorf("unexpected reading: %+v", got)
+	}
+
+	if _, err := svc.UpdateReading(context.Background(), 1, 4, app.BloodPressurePatch{Diastolic: intPtr(130)}, time.UTC); err == nil {
+		t.Fatal("expected validation error when diastolic exceeds systolic")
+	}
+}
+
+func TestReadingByID_NotFound(t *testing.T) {
+	repo := &mockBloodPressureRepo{
+		delFn: func(_ context.Context, _ int64, _ int64) (bool, error) { return false, nil },
+	}
+	svc := app.NewBloodPressureService(repo)
+	if _, err := svc.GetReading(context.Background(), 1, 5, time.UTC); !errors.Is(err, app.ErrEntryNotFound) {
+		t.Errorf("GetReading: expected ErrEntryNotFound, got %v", err)
+	}
+	if err := svc.DeleteReading(context.Background(), 1, 5); !errors.Is(err, app.ErrEntryNotFound) {
+		t.Errorf("DeleteReading: expected ErrEntryNotFound, got %v", err)
+	}
+}
+
+func TestBloodPressureGetToday(t *testing.T) {
+	var got domain.EventQuery
+	repo := &mockBloodPressureRepo{
+		listFn: func(_ context.Context, _ int64, q domain.EventQuery, _ *time.Location) ([]domain.BloodPressureReading, error) {
+			got = q
+			return []domain.BloodPressureReading{{ID: 1, Systolic: 110, Diastolic: 70}}, nil
+		},
+	}
+	svc := app.NewBloodPressureService(repo)
+	loc := time.FixedZone("UTC-5", -5*3600)
+	readings, err := svc.GetToday(context.Background(), 1, "2026-03-01", loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(readings) != 1 || readings[0].Category != domain.BPNormal {
+		t.Fatalf("unexpected readings: %+v", readings)
+	}
+	want := time.Date(2026, 3, 1, 5, 0, 0, 0, time.UTC)
+	if !got.From.Equal(want) || !got.To.Equal(want.Add(24*time.Hour)) {
+		t.Errorf("unexpected day bounds %v..%v", got.From, got.To)
+	}
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// Chart bucket periods. Weeks start on Monday.
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+	BucketYear  = "year"
+)
+
+// Bucket aggregations. Weight buckets support all but AggSum; water buckets
+// support AggSum and AggMean.
+const (
+	AggMean = "mean"
+	AggMin  = "min"
+	AggMax  = "max"
+	AggLast = "last"
+	AggSum  = "sum"
+)
+
+// maxBucketDays bounds the range covered by GetBuckets, about twenty years. It
+// stays a year below domain.MaxDayRange, so the range can still be widened to
+// the start of its first period.
+const maxBucketDays = domain.MaxDayRange - 366
+
+// BucketPoint is a single data point returned by GetBuckets, aggregating the
+// days from Start to End inclusive.
+type BucketPoint struct {
+	Start string `json:"start"`
+	// End is the bucket's last day, or the range's last day for the final
+	// bucket.
+	End  string `json:"end"`
+	Days int    `json:"days"`
+	// WaterLiters is the sum or daily mean of the bucket's water totals.
+	WaterLiters float64 `json:"waterLiters"`
+	// Weight aggregates each day's weight, selected by the query's weight
+	// policy, in the requested unit, or is nil if there were none.
+	Weight *float64 `json:"weight"`
+	// WeighInDays is the number of days with a weigh-in.
+	WeighInDays int `json:"weighInDays"`
+}
+
+// GetBuckets returns chart data for the range selected by q in loc, grouped
+// into q.Bucket periods. The range is widened to start at the beginning of
+// its first period, while the final bucket ends with the range. Each day's
+// weight under q.WeightPolicy, converted to q.Unit, is combined with
+// q.WeightAgg and each day's water total with q.WaterAgg. Each series for the whole range is
+// fetched with one repository call.
+func (s *ChartsService) GetBuckets(ctx context.Context, userID int64, q ChartQuery, loc *time.Location) ([]BucketPoint, error) {
+	unit := q.Unit
+	if unit != "kg" && unit != "lb" {
+		return nil, errors.New("unit must be \"kg\" or \"lb\"")
+	}
+	if _, ok := nextBucket(time.Time{}, q.Bucket); !ok {
+		return nil, errors.New("bucket must be \"day\", \"week\", \"month\" or \"year\"")
+	}
+	weightAgg, waterAgg := q.WeightAgg, q.WaterAgg
+	if weightAgg == "" {
+		weightAgg = AggMean
+	}
+	if waterAgg == "" {
+		waterAgg = AggSum
+	}
+	switch weightAgg {
+	case AggMean, AggMin, AggMax, AggLast:
+	default:
+		return nil, errors.New("weightAgg must be \"mean\", \"min\", \"max\" or \"last\"")
+	}
+	if waterAgg != AggSum && waterAgg != AggMean {
+		return nil, errors.New("waterAgg must be \"sum\" or \"mean\"")
+	}
+	policy, err := resolveWeightPolicy(q.WeightPolicy)
+	if err != nil {
+		return nil, err
+	}
+	from, days, err := q.dayRange(maxBucketDays, loc)
+	if err != nil {
+		return nil, err
+	}
+	if days < 1 {
+		return []BucketPoint{}, nil
+	}
+	last := from.AddDate(0, 0, days-1)
+	first := bucketStart(from, q.Bucket)
+	fromDay := first.Format("2006-01-02")
+	toDay := last.Format("2006-01-02")
+
+	water, err := s.waterRepo.WaterTotalsForLocalDays(ctx, userID, fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+	weights, err := s.weightRepo.WeightsForLocalDays(ctx, userID, fromDay, toDay, policy, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []BucketPoint
+	for start := first; !start.After(last); {
+		next, _ := nextBucket(start, q.Bucket)
+		end := next.AddDate(0, 0, -1)
+		if end.After(last) {
+			end = last
+		}
+
+		p := BucketPoint{Start: start.Format("2006-01-02"), End: end.Format("2006-01-02")}
+		var weightSum float64
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			day := d.Format("2006-01-02")
+			p.Days++
+			p.WaterLiters += water[day]
+			e, ok := weights[day]
+			if !ok {
+				continue
+			}
+			v := domain.ConvertWeight(e.Value, e.Unit, unit)
+			p.WeighInDays++
+			weightSum += v
+			switch {
+			case p.Weight == nil, weightAgg == AggLast,
+				weightAgg == AggMin && v < *p.Weight,
+				weightAgg == AggMax && v > *p.Weight:
+				p.Weight = &v
+			}
+		}
+		if weightAgg == AggMean && p.WeighInDays > 0 {
+			mean := weightSum / float64(p.WeighInDays)
+			p.Weight = &mean
+		}
+		if waterAgg == AggMean {
+			p.WaterLiters /= float64(p.Days)
+		}
+		points = append(points, p)
+		start = next
+	}
+	return points, nil
+}
+
+// bucketStart returns the local midnight starting the bucket period that
+// contains the local midnight day.
+func bucketStart(day time.Time, bucket string) time.Time {
+	switch bucket {
+	case BucketWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case BucketMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	case BucketYear:
+		return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day
+	}
+}
+
+// nextBucket returns the start of the bucket period after the one starting at
+// start. It reports false for an unknown bucket.
+func nextBucket(start time.Time, bucket string) (time.Time, bool) {
+	switch bucket {
+	case BucketDay:
+		return start.AddDate(0, 0, 1), true
+	case BucketWeek:
+		return start.AddDate(0, 0, 7), true
+	case BucketMonth:
+		return start.AddDate(0, 1, 0), true
+	case BucketYear:
+		return start.AddDate(1, 0, 0), true
+	default:
+		return time.Time{}, false
+	}
+}
@@ -0,0 +1,170 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+// dayOfMonthRepos returns mocks with a 1 L water total and a weigh-in equal
+// to the day of the month, in kg, on every day of the requested range.
+func dayOfMonthRepos(t *testing.T) (*mockWeightRepo, *mockWaterRepo) {
+	t.Helper()
+	wr := &mockWeightRepo{
+		rangeFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+			out := map[string]domain.WeightEntry{}
+			eachDay(t, from, to, func(day string) {
+				d, _ := time.Parse("2006-01-02", day)
+				out[day] = domain.WeightEntry{Day: day, Value: float64(d.Day()), Unit: "kg"}
+			})
+			return out, nil
+		},
+	}
+	wa := &mockWaterRepo{
+		totalsFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (map[string]float64, error) {
+			out := map[string]float64{}
+			eachDay(t, from, to, func(day string) { out[day] = 1 })
+			return out, nil
+		},
+	}
+	return wr, wa
+}
+
+func TestGetBuckets_Month(t *testing.T) {
+	today := time.Now().UTC().Format("2006-01-02")
+	for _, tc := range []struct {
+		agg  string
+		want func(start, end time.Time) float64
+	}{
+		{app.AggMean, func(s, e time.Time) float64 { return float64(s.Day()+e.Day()) / 2 }},
+		{app.AggMin, func(s, _ time.Time) float64 { return float64(s.Day()) }},
+		{app.AggMax, func(_, e time.Time) float64 { return float64(e.Day()) }},
+		{app.AggLast, func(_, e time.Time) float64 { return float64(e.Day()) }},
+	} {
+		wr, wa := dayOfMonthRepos(t)
+		svc := app.NewChartsService(wr, wa)
+		points, err := svc.GetBuckets(context.Background(), 1, app.ChartQuery{
+			Days:      90,
+			Unit:      "kg",
+			Bucket:    app.BucketMonth,
+			WeightAgg: tc.agg,
+		}, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.agg, err)
+		}
+		if len(points) < 3 || len(points) > 4 {
+			t.Fatalf("%s: expected 3 or 4 months, got %d", tc.agg, len(points))
+		}
+		if last := points[len(points)-1]; last.End != today {
+			t.Errorf("%s: expected the last bucket to end today, got %s", tc.agg, last.End)
+		}
+		for i, p := range points {
+			start, _ := time.Parse("2006-01-02", p.Start)
+			end, _ := time.Parse("2006-01-02", p.End)
+			if start.Day() != 1 {
+				t.Errorf("%s: bucket %d starts on %s", tc.agg, i, p.Start)
+			}
+			if i < len(points)-1 && end.AddDate(0, 0, 1).Format("2006-01-02") != points[i+1].Start {
+				t.Errorf("%s: bucket %d ends on %s but the next starts on %s", tc.agg, i, p.End, points[i+1].Start)
+			}
+			if p.Days != end.Day() || p.WeighInDays != p.Days || p.WaterLiters != float64(p.Days) {
+				t.Errorf("%s: unexpected counts in %+v", tc.agg, p)
+			}
+			if want := tc.want(start, end); p.Weight == nil || *p.Weight != want {
+				t.Errorf("%s: expected weight %v for %s, got %v", tc.agg, want, p.Start, p.Weight)
+			}
+		}
+	}
+}
+
+func TestGetBuckets_WeekMeanWater(t *testing.T) {
+	wr, wa := dayOfMonthRepos(t)
+	svc := app.NewChartsService(wr, wa)
+	points, err := svc.GetBuckets(context.Background(), 1, app.ChartQuery{
+		Days:     28,
+		Unit:     "lb",
+		Bucket:   app.BucketWeek,
+		WaterAgg: app.AggMean,
+	}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, p := range points {
+		start, _ := time.Parse("2006-01-02", p.Start)
+		if start.Weekday() != time.Monday {
+			t.Errorf("expected weeks to start on Monday, got %s", p.Start)
+		}
+		if p.WaterLiters != 1 {
+			t.Errorf("expected a mean of 1 L for %s, got %v", p.Start, p.WaterLiters)
+		}
+		if p.Weight == nil || *p.Weight < 2.2 {
+			t.Errorf("expected a weight in lb for %s, got %v", p.Start, p.Weight)
+		}
+	}
+}
+
+func TestGetBuckets_YearsBeyondDailyLimit(t *testing.T) {
+	var gotFrom string
+	wr := &mockWeightRepo{
+		rangeFn: func(_ context.Context, _ int64, from, _ string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+			gotFrom = from
+			return nil, nil
+		},
+	}
+	svc := app.NewChartsService(wr, &mockWaterRepo{})
+	points, err := svc.GetBuckets(context.Background(), 1, app.ChartQuery{Days: 3 * 366, Unit: "kg", Bucket: app.BucketYear}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(points) < 4 {
+		t.Fatalf("expected at least 4 years, got %d", len(points))
+	}
+	if want := points[0].Start; gotFrom != want || want[5:] != "01-01" {
+		t.Errorf("expected weights from %s, got %s", want, gotFrom)
+	}
+	for _, p := range points {
+		if p.Weight != nil || p.WeighInDays != 0 {
+			t.Errorf("expected no weight in %+v", p)
+		}
+	}
+}
+
+func TestGetBuckets_Invalid(t *testing.T) {
+	svc := app.NewChartsService(&mockWeightRepo{}, &mockWaterRepo{})
+	for _, q := range []app.ChartQuery{
+		{Days: 30, Unit: "kg", Bucket: "fortnight"},
+		{Days: 30, Unit: "kg", Bucket: app.BucketWeek, WeightAgg: app.AggSum},
+		{Days: 30, Unit: "kg", Bucket: app.BucketWeek, WaterAgg: app.AggMax},
+		{Days: 30, Unit: "st", Bucket: app.BucketWeek},
+	} {
+		if _, err := svc.GetBuckets(context.Background(), 1, q, time.UTC); err == nil {
+			t.Errorf("expected an error for %+v", q)
+		}
+	}
+}
+
+func TestGetBuckets_FromTo(t *testing.T) {
+	wr, wa := dayOfMonthRepos(t)
+	svc := app.NewChartsService(wr, wa)
+	points, err := svc.GetBuckets(context.Background(), 1, app.ChartQuery{
+		From:   "2023-06-15",
+		To:     "2024-02-10",
+		Unit:   "kg",
+		Bucket: app.BucketMonth,
+	}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(points) != 9 {
+		t.Fatalf("expected 9 months, got %d", len(points))
+	}
+	if points[0].Start != "2023-06-01" || points[8].Start != "2024-02-01" || points[8].End != "2024-02-10" {
+		t.Errorf("unexpected bounds %s..%s", points[0].Start, points[8].End)
+	}
+	if points[8].Days != 10 || points[8].Weight == nil || *points[8].Weight != 5.5 {
+		t.Errorf("unexpected final bucket %+v", points[8])
+	}
+}
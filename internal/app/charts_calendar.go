@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// MaxWaterLevel is the CalendarDay water level of a day that met the goal.
+const MaxWaterLevel = 4
+
+// CalendarDay is a single cell of a year calendar.
+type CalendarDay struct {
+	Day         string  `json:"day"`
+	WaterLiters float64 `json:"waterLiters"`
+	// WaterLevel grades WaterLiters against the goal: 0 for no intake, 1 to 3
+	// for intake below a third, two thirds or all of the goal, and
+	// MaxWaterLevel once the goal is met. Without a goal any intake is 1.
+	WaterLevel   int  `json:"waterLevel"`
+	WaterGoalMet bool `json:"waterGoalMet"`
+	WeightLogged bool `json:"weightLogged"`
+}
+
+// GetCalendar returns one cell for every local day in loc of the given year,
+// grading water intake against waterGoal. The days are aggregated as for
+// GetDaily.
+func (s *ChartsService) GetCalendar(ctx context.Context, userID int64, year int, waterGoal float64, loc *time.Location) ([]CalendarDay, error) {
+	if year < 1 || year > 9999 {
+		return nil, errors.New("year must be between 1 and 9999")
+	}
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	points, err := s.GetDaily(ctx, userID, ChartQuery{
+		From:      first.Format("2006-01-02"),
+		To:        first.AddDate(1, 0, -1).Format("2006-01-02"),
+		Unit:      "kg",
+		WaterGoal: waterGoal,
+	}, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make([]CalendarDay, len(points))
+	for i, p := range points {
+		cells[i] = CalendarDay{
+			Day:          p.Day,
+			WaterLiters:  p.WaterLiters,
+			WaterLevel:   waterLevel(p.WaterLiters, waterGoal),
+			WaterGoalMet: p.WaterGoalMet,
+			WeightLogged: p.Weight != nil,
+		}
+	}
+	return cells, nil
+}
+
+// waterLevel grades a day's water total against goal as described for
+// CalendarDay.WaterLevel.
+func waterLevel(total, goal float64) int {
+	switch {
+	case total <= 0:
+		return 0
+	case goal <= 0:
+		return 1
+	case waterGoalMet(total, goal):
+		return MaxWaterLevel
+	default:
+		return min(1+int(3*total/goal), MaxWaterLevel-1)
+	}
+}
@@ -0,0 +1,83 @@
+package app_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+func TestGetCalendar(t *testing.T) {
+	var gotFrom, gotTo string
+	wr := &mockWeightRepo{
+		rangeFn: func(_ context.Context, _ int64, _, _ string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+			return map[string]domain.WeightEntry{"2024-02-29": {Value: 80, Unit: "kg"}}, nil
+		},
+	}
+	wa := &mockWaterRepo{
+		totalsFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (map[string]float64, error) {
+			gotFrom, gotTo = from, to
+			return map[string]float64{
+				"2024-01-01": 0.5,
+				"2024-01-02": 1,
+				"2024-01-03": 1.5,
+				"2024-01-04": 2,
+				"2024-12-31": 3,
+			}, nil
+		},
+	}
+
+	cells, err := app.NewChartsService(wr, wa).GetCalendar(context.Background(), 1, 2024, 2, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotFrom != "2024-01-01" || gotTo != "2024-12-31" || len(cells) != 366 {
+		t.Fatalf("expected the leap year 2024, got %s..%s with %d cells", gotFrom, gotTo, len(cells))
+	}
+	for i, want := range []int{1, 2, 3, app.MaxWaterLevel, 0} {
+		if cells[i].WaterLevel != want {
+			t.Errorf("%s: expected level %d, got %d", cells[i].Day, want, cells[i].WaterLevel)
+		}
+	}
+	if !cells[3].WaterGoalMet || cells[2].WaterGoalMet {
+		t.Error("expected the goal to be met on 2024-01-04 only among the first days")
+	}
+	if last := cells[365]; last.Day != "2024-12-31" || last.WaterLevel != app.MaxWaterLevel {
+		t.Errorf("unexpected last cell %+v", last)
+	}
+	for _, c := range cells {
+		if c.WeightLogged != (c.Day == "2024-02-29") {
+			t.Errorf("%s: unexpected weightLogged %v", c.Day, c.WeightLogged)
+		}
+	}
+
+	if _, err := app.NewChartsService(wr, wa).GetCalendar(context.Background(), 1, 0, 2, time.UTC); err == nil {
+		t.Error("expected an error for year 0")
+	}
+}
+
+func TestGetCalendar_NoGoal(t *testing.T) {
+	wa := &mockWaterRepo{
+		totalsFn: func(_ context.Context, _ int64, _, _ string, _ *time.Location) (map[string]float64, error) {
+			return map[string]float64{"2023-06-01": 5}, nil
+		},
+	}
+	cells, err := app.NewChartsService(&mockWeightRepo{}, wa).GetCalendar(context.Background(), 1, 2023, 0, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cells) != 365 {
+		t.Fatalf("expected 365 cells, got %d", len(cells))
+	}
+	for _, c := range cells {
+		want := 0
+		if c.Day == "2023-06-01" {
+			want = 1
+		}
+		if c.WaterLevel != want || c.WaterGoalMet {
+			t.Errorf("%s: expected level %d without a goal, got %+v", c.Day, want, c)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"vitals/internal/domain"
@@ -12,6 +13,10 @@ import (
 type ChartsService struct {
 	weightRepo domain.WeightRepository
 	waterRepo  domain.WaterRepository
+	bpRepo     domain.BloodPressureRepository
+	sleepRepo  domain.SleepRepository
+	measRepo   domain.MeasurementRepository
+	goalRepo   domain.WeightGoalRepository
 }
 
 // NewChartsService creates a ChartsService backed by the given repositories.
@@ -19,56 +24,302 @@ func NewChartsService(wr domain.WeightRepository, wa domain.WaterRepository) *Ch
 	return &ChartsService{weightRepo: wr, waterRepo: wa}
 }
 
+// WithBloodPressure adds a daily blood pressure series backed by repo.
+func (s *ChartsService) WithBloodPressure(repo domain.BloodPressureRepository) *ChartsService {
+	s.bpRepo = repo
+	return s
+}
+
+// WithSleep adds a daily sleep duration series backed by repo.
+func (s *ChartsService) WithSleep(repo domain.SleepRepository) *ChartsService {
+	s.sleepRepo = repo
+	return s
+}
+
+// WithMeasurements enables GetMeasurements backed by repo.
+func (s *ChartsService) WithMeasurements(repo domain.MeasurementRepository) *ChartsService {
+	s.measRepo = repo
+	return s
+}
+
+// WithWeightGoal overlays the user's target weight on the daily series,
+// backed by repo.
+func (s *ChartsService) WithWeightGoal(repo domain.WeightGoalRepository) *ChartsService {
+	s.goalRepo = repo
+	return s
+}
+
 // DayPoint is a single data point returned by GetDaily.
 type DayPoint struct {
-	Day         string       `json:"day"`
-	WaterLiters float64      `json:"waterLiters"`
-	Weight      *WeightPoint `json:"weight"`
+	Day         string  `json:"day"`
+	WaterLiters float64 `json:"waterLiters"`
+	// WaterGoalMet reports whether WaterLiters reached the daily water goal.
+	WaterGoalMet bool         `json:"waterGoalMet"`
+	Weight       *WeightPoint `json:"weight"`
+	// WeightTrend is the smoothed weight in the requested unit. It is present
+	// from the first weigh-in on and bridges days without one.
+	WeightTrend *float64 `json:"weightTrend,omitempty"`
+	// WeightGoal is the user's target weight in the requested unit, if set.
+	WeightGoal    *float64            `json:"weightGoal,omitempty"`
+	BloodPressure *BloodPressurePoint `json:"bloodPressure,omitempty"`
+	// SleepMinutes is the total sleep of sessions that ended on Day.
+	SleepMinutes *float64 `json:"sleepMinutes,omitempty"`
 }
 
-// WeightPoint is the optional weight value within a DayPoint.
+// WeightPoint is the optional weight value within a DayPoint. Composition
+// masses, and the fat and lean mass derived from the body fat percentage, are
+// in Unit.
 type WeightPoint struct {
 	Value float64 `json:"value"`
 	Unit  string  `json:"unit"`
+	domain.BodyComposition
+	FatMass  *float64 `json:"fatMass,omitempty"`
+	LeanMass *float64 `json:"leanMass,omitempty"`
+}
+
+// BloodPressurePoint is the optional mean blood pressure within a DayPoint,
+// classified by its mean values.
+type BloodPressurePoint struct {
+	domain.BloodPressureAverage
+	Category domain.BloodPressureCategory `json:"category"`
+}
+
+// ChartQuery selects the data returned by GetDaily.
+type ChartQuery struct {
+	// Days is the number of days up to and including To, or today if To is
+	// empty. GetDaily clamps it to 366 and GetBuckets to about twenty years.
+	// It is ignored when From is set.
+	Days int
+	// From and To are optional local days bounding the range inclusively.
+	From string
+	To   string
+	// Unit is the weight unit, "kg" or "lb".
+	Unit string
+	// WaterGoal is the daily water goal in liters.
+	WaterGoal float64
+	// WeightPolicy selects each day's weight; empty means
+	// domain.DefaultWeightPolicy.
+	WeightPolicy domain.WeightPolicy
+	// Trend selects how WeightTrend is smoothed.
+	Trend TrendOptions
+	// Bucket is the GetBuckets period, one of the Bucket constants.
+	Bucket string
+	// WeightAgg combines a bucket's daily weights: AggMean (the default),
+	// AggMin, AggMax or AggLast.
+	WeightAgg string
+	// WaterAgg combines a bucket's daily water totals: AggSum (the default)
+	// or AggMean.
+	WaterAgg string
+}
+
+// maxDailyDays bounds the range covered by GetDaily.
+const maxDailyDays = 366
+
+// dayRange resolves the local days selected by q in loc; see resolveDayRange.
+func (q ChartQuery) dayRange(maxDays int, loc *time.Location) (time.Time, int, error) {
+	return resolveDayRange(q.Days, q.From, q.To, maxDays, loc)
+}
+
+// resolveDayRange resolves a range of local days in loc, given either as the
+// days days ending on to, or today if to is empty, or as the inclusive local
+// days from and to, to the midnight of its first day and its number of days.
+// days is clamped to maxDays and may leave the range empty; an explicit range
+// must be non-empty and no longer than maxDays.
+func resolveDayRange(days int, from, to string, maxDays int, loc *time.Location) (time.Time, int, error) {
+	now := time.Now().In(loc)
+	last := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if to != "" {
+		var err error
+		if last, err = time.ParseInLocation("2006-01-02", to, loc); err != nil {
+			return time.Time{}, 0, errors.New("to must be YYYY-MM-DD")
+		}
+	}
+	if from == "" {
+		days = min(days, maxDays)
+		return last.AddDate(0, 0, -(days - 1)), days, nil
+	}
+	first, err := time.ParseInLocation("2006-01-02", from, loc)
+	if err != nil {
+		return time.Time{}, 0, errors.New("from must be YYYY-MM-DD")
+	}
+	days = daysBetween(first, last) + 1
+	if days < 1 {
+		return time.Time{}, 0, errors.New("from must not be after to")
+	}
+	if days > maxDays {
+		return time.Time{}, 0, fmt.Errorf("range must not exceed %d days", maxDays)
+	}
+	return first, days, nil
 }
 
-// GetDaily returns per-day chart data for the last days days, with weights
-// converted to the requested unit.
-func (s *ChartsService) GetDaily(ctx context.Context, userID int64, days int, unit string) ([]DayPoint, error) {
+// GetDaily returns per-day chart data for the range selected by q in loc,
+// with weights and their trend converted to q.Unit and water intake compared
+// against q.WaterGoal. Each series for the whole range is fetched with one
+// repository call.
+func (s *ChartsService) GetDaily(ctx context.Context, userID int64, q ChartQuery, loc *time.Location) ([]DayPoint, error) {
+	unit := q.Unit
 	if unit != "kg" && unit != "lb" {
 		return nil, errors.New("unit must be \"kg\" or \"lb\"")
 	}
-	if days > 366 {
-		days = 366
+	if err := q.Trend.validate(); err != nil {
+		return nil, err
 	}
+	policy, err := resolveWeightPolicy(q.WeightPolicy)
+	if err != nil {
+		return nil, err
+	}
+	first, days, err := q.dayRange(maxDailyDays, loc)
+	if err != nil {
+		return nil, err
+	}
+	if days < 1 {
+		return []DayPoint{}, nil
+	}
+	fromDay := first.Format("2006-01-02")
+	toDay := first.AddDate(0, 0, days-1).Format("2006-01-02")
 
-	today := time.Now().In(time.Local)
-	points := make([]DayPoint, 0, days)
-
-	for i := days - 1; i >= 0; i-- {
-		d := today.AddDate(0, 0, -i)
-		dayStr := d.Format("2006-01-02")
-
-		waterLiters, err := s.waterRepo.WaterTotalForLocalDay(ctx, userID, dayStr)
-		if err != nil {
+	water, err := s.waterRepo.WaterTotalsForLocalDays(ctx, userID, fromDay, toDay, loc)
+	if err != nil {
+		return nil, err
+	}
+	// Weights are read from before the range too, so its trend starts warm.
+	warmup := q.Trend.warmupDays()
+	weights, err := s.weightRepo.WeightsForLocalDays(ctx, userID, first.AddDate(0, 0, -warmup).Format("2006-01-02"), toDay, policy, loc)
+	if err != nil {
+		return nil, err
+	}
+	trend := dailyTrend(weights, first, warmup, days, unit, q.Trend)
+	var bp map[string]domain.BloodPressureAverage
+	if s.bpRepo != nil {
+		if bp, err = s.bpRepo.BloodPressureAveragesForLocalDays(ctx, userID, fromDay, toDay, loc); err != nil {
 			return nil, err
 		}
-
-		entry, err := s.weightRepo.LatestWeightForLocalDay(ctx, userID, dayStr)
+	}
+	var goal *float64
+	if s.goalRepo != nil {
+		g, err := s.goalRepo.GetWeightGoal(ctx, userID)
 		if err != nil {
 			return nil, err
 		}
+		if g != nil {
+			target := g.Target(unit)
+			goal = &target
+		}
+	}
+	var sleep map[string]float64
+	if s.sleepRepo != nil {
+		if sleep, err = s.sleepRepo.SleepMinutesForLocalDays(ctx, userID, fromDay, toDay, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	points := make([]DayPoint, 0, days)
+	for i := 0; i < days; i++ {
+		dayStr := first.AddDate(0, 0, i).Format("2006-01-02")
 
 		var wp *WeightPoint
-		if entry != nil {
-			val := entry.Value
-			if entry.Unit != unit {
-				val = domain.ConvertWeight(val, entry.Unit, unit)
+		if entry, ok := weights[dayStr]; ok {
+			wp = newWeightPoint(entry, unit)
+		}
+
+		var bpp *BloodPressurePoint
+		if avg, ok := bp[dayStr]; ok {
+			bpp = &BloodPressurePoint{
+				BloodPressureAverage: avg,
+				Category:             domain.ClassifyBloodPressure(avg.Systolic, avg.Diastolic),
 			}
-			wp = &WeightPoint{Value: val, Unit: unit}
 		}
 
-		points = append(points, DayPoint{Day: dayStr, WaterLiters: waterLiters, Weight: wp})
+		var sleepMinutes *float64
+		if m, ok := sleep[dayStr]; ok {
+			sleepMinutes = &m
+		}
+
+		points = append(points, DayPoint{
+			Day:           dayStr,
+			WaterLiters:   water[dayStr],
+			WaterGoalMet:  waterGoalMet(water[dayStr], q.WaterGoal),
+			Weight:        wp,
+			WeightTrend:   trend[i],
+			WeightGoal:    goal,
+			BloodPressure: bpp,
+			SleepMinutes:  sleepMinutes,
+		})
+	}
+	return points, nil
+}
+
+// dailyTrend smooths the daily weights, keyed by local day, over the warmup
+// days before first and the days days from first on, and returns the trend of
+// the latter in unit.
+func dailyTrend(weights map[string]domain.WeightEntry, first time.Time, warmup, days int, unit string, opts TrendOptions) []*float64 {
+	values := make([]*float64, warmup+days)
+	for i := range values {
+		if e, ok := weights[first.AddDate(0, 0, i-warmup).Format("2006-01-02")]; ok {
+			v := domain.ConvertWeight(e.Value, e.Unit, unit)
+			values[i] = &v
+		}
+	}
+	return opts.smooth(values)[warmup:]
+}
+
+// newWeightPoint converts entry to unit and derives its fat and lean mass.
+func newWeightPoint(entry domain.WeightEntry, unit string) *WeightPoint {
+	val := entry.Value
+	comp := entry.BodyComposition
+	if entry.Unit != unit {
+		val = domain.ConvertWeight(val, entry.Unit, unit)
+		comp = comp.Convert(entry.Unit, unit)
+	}
+	return &WeightPoint{
+		Value:           val,
+		Unit:            unit,
+		BodyComposition: comp,
+		FatMass:         comp.FatMass(val),
+		LeanMass:        comp.LeanMass(val),
+	}
+}
+
+// MeasurementDayPoint is a single data point returned by GetMeasurements.
+type MeasurementDayPoint struct {
+	Day string `json:"day"`
+	// Sites holds the latest measurement of each site taken on Day, keyed by
+	// site. Sites without a measurement that day are omitted.
+	Sites map[string]float64 `json:"sites"`
+}
+
+// GetMeasurements returns per-day body measurements for the last days days in
+// loc, converted to the requested length unit. The whole range is fetched
+// with one repository call.
+func (s *ChartsService) GetMeasurements(ctx context.Context, userID int64, days int, unit string, loc *time.Location) ([]MeasurementDayPoint, error) {
+	if s.measRepo == nil {
+		return nil, errors.New("measurements are not enabled")
+	}
+	if unit != "cm" && unit != "in" {
+		return nil, errors.New("unit must be \"cm\" or \"in\"")
+	}
+	if days > 366 {
+		days = 366
+	}
+	if days < 1 {
+		return []MeasurementDayPoint{}, nil
+	}
+
+	today := time.Now().In(loc)
+	first := today.AddDate(0, 0, -(days - 1))
+	latest, err := s.measRepo.LatestMeasurementsForLocalDays(ctx, userID, first.Format("2006-01-02"), today.Format("2006-01-02"), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]MeasurementDayPoint, 0, days)
+	for i := 0; i < days; i++ {
+		dayStr := first.AddDate(0, 0, i).Format("2006-01-02")
+		sites := make(map[string]float64, len(latest[dayStr]))
+		for site, m := range latest[dayStr] {
+			sites[site] = domain.ConvertLength(m.Value, m.Unit, unit)
+		}
+		points = append(points, MeasurementDayPoint{Day: dayStr, Sites: sites})
 	}
 	return points, nil
 }
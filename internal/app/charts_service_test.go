@@ -2,15 +2,33 @@ package app_test
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"vitals/internal/app"
 	"vitals/internal/domain"
 )
 
+// eachDay calls fn for every day in the inclusive range [from, to].
+func eachDay(t *testing.T, from, to string, fn func(day string)) {
+	t.Helper()
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		t.Fatalf("bad from day %q: %v", from, err)
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		t.Fatalf("bad to day %q: %v", to, err)
+	}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		fn(d.Format("2006-01-02"))
+	}
+}
+
 func TestGetDaily_BadUnit(t *testing.T) {
 	svc := app.NewChartsService(&mockWeightRepo{}, &mockWaterRepo{})
-	_, err := svc.GetDaily(context.Background(), 1, 7, "stones")
+	_, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 7, Unit: "stones", WaterGoal: domain.DefaultWaterGoalLiters}, time.UTC)
 	if err == nil {
 		t.Fatal("expected error for bad unit")
 	}
@@ -18,16 +36,24 @@ func TestGetDaily_BadUnit(t *testing.T) {
 
 func TestGetDaily_Success(t *testing.T) {
 	wr := &mockWeightRepo{
-		latestFn: func(_ context.Context, _ int64, _ string) (*domain.WeightEntry, error) {
-			return &domain.WeightEntry{ID: 1, Value: 80, Unit: "kg"}, nil
+		rangeFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+			out := map[string]domain.WeightEntry{}
+			eachDay(t, from, to, func(day string) {
+				out[day] = domain.WeightEntry{ID: 1, Day: day, Value: 80, Unit: "kg"}
+			})
+			return out, nil
 		},
 	}
 	wa := &mockWaterRepo{
-		totalFn: func(_ context.Context, _ int64, _ string) (float64, error) { return 2.5, nil },
+		totalsFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (map[string]float64, error) {
+			out := map[string]float64{}
+			eachDay(t, from, to, func(day string) { out[day] = 2.5 })
+			return out, nil
+		},
 	}
 
 	svc := app.NewChartsService(wr, wa)
-	points, err := svc.GetDaily(context.Background(), 1, 3, "kg")
+	points, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 3, Unit: "kg", WaterGoal: domain.DefaultWaterGoalLiters}, time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -35,8 +61,8 @@ func TestGetDaily_Success(t *testing.T) {
 		t.Fatalf("expected 3 points, got %d", len(points))
 	}
 	for _, p := range points {
-		if p.WaterLiters != 2.5 {
-			t.Errorf("expected waterLiters=2.5, got %v", p.WaterLiters)
+		if p.WaterLiters != 2.5 || !p.WaterGoalMet {
+			t.Errorf("expected waterLiters=2.5 meeting the goal, got %v", p.WaterLiters)
 		}
 		if p.Weight == nil || p.Weight.Value != 80 {
 			t.Errorf("expected weight 80, got %v", p.Weight)
@@ -44,41 +70,59 @@ func TestGetDaily_Success(t *testing.T) {
 	}
 }
 
-func TestGetDaily_ConvertUnit(t *testing.T) {
+func TestGetDaily_SingleRangeQuery(t *testing.T) {
+	var weightCalls, waterCalls int
+	var gotFrom, gotTo string
 	wr := &mockWeightRepo{
-		latestFn: func(_ context.Context, _ int64, _ string) (*domain.WeightEntry, error) {
-			return &domain.WeightEntry{ID: 1, Value: 100, Unit: "kg"}, nil
+		rangeFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+			weightCalls++
+			gotFrom, gotTo = from, to
+			return nil, nil
 		},
 	}
 	wa := &mockWaterRepo{
-		totalFn: func(_ context.Context, _ int64, _ string) (float64, error) { return 0, nil },
+		totalsFn: func(_ context.Context, _ int64, _, _ string, _ *time.Location) (map[string]float64, error) {
+			waterCalls++
+			return nil, nil
+		},
 	}
 
 	svc := app.NewChartsService(wr, wa)
-	points, err := svc.GetDaily(context.Background(), 1, 1, "lb")
+	points, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 30, Unit: "kg", WaterGoal: domain.DefaultWaterGoalLiters}, time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(points) != 1 {
-		t.Fatalf("expected 1 point, got %d", len(points))
+	if weightCalls != 1 || waterCalls != 1 {
+		t.Fatalf("expected one call per repo, got weight=%d water=%d", weightCalls, waterCalls)
 	}
-	if points[0].Weight == nil || points[0].Weight.Value < 220 || points[0].Weight.Value > 221 {
-		t.Errorf("expected ~220.46 lb, got %v", points[0].Weight)
+	if gotFrom != points[0].Day || gotTo != points[len(points)-1].Day {
+		t.Errorf("range %s..%s does not match points %s..%s", gotFrom, gotTo, points[0].Day, points[len(points)-1].Day)
 	}
 }
 
-func TestGetDaily_ClampsTo366(t *testing.T) {
+func TestGetDaily_ConvertUnit(t *testing.T) {
 	wr := &mockWeightRepo{
-		latestFn: func(_ context.Context, _ int64, _ string) (*domain.WeightEntry, error) {
-			return nil, nil
+		rangeFn: func(_ context.Context, _ int64, _, to string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+			return map[string]domain.WeightEntry{to: {ID: 1, Day: to, Value: 100, Unit: "kg"}}, nil
 		},
 	}
-	wa := &mockWaterRepo{
-		totalFn: func(_ context.Context, _ int64, _ string) (float64, error) { return 0, nil },
+
+	svc := app.NewChartsService(wr, &mockWaterRepo{})
+	points, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 1, Unit: "lb", WaterGoal: domain.DefaultWaterGoalLiters}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(points) != 1 {
+		t.Fatalf("expected 1 point, got %d", len(points))
 	}
+	if points[0].Weight == nil || points[0].Weight.Value < 220 || points[0].Weight.Value > 221 {
+		t.Errorf("expected ~220.46 lb, got %v", points[0].Weight)
+	}
+}
 
-	svc := app.NewChartsService(wr, wa)
-	points, err := svc.GetDaily(context.Background(), 1, 500, "kg")
+func TestGetDaily_ClampsTo366(t *testing.T) {
+	svc := app.NewChartsService(&mockWeightRepo{}, &mockWaterRepo{})
+	points, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 500, Unit: "kg", WaterGoal: domain.DefaultWaterGoalLiters}, time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -88,17 +132,14 @@ func TestGetDaily_ClampsTo366(t *testing.T) {
 }
 
 func TestGetDaily_NoWeight(t *testing.T) {
-	wr := &mockWeightRepo{
-		latestFn: func(_ context.Context, _ int64, _ string) (*domain.WeightEntry, error) {
-			return nil, nil
-		},
-	}
 	wa := &mockWaterRepo{
-		totalFn: func(_ context.Context, _ int64, _ string) (float64, error) { return 1.0, nil },
+		totalsFn: func(_ context.Context, _ int64, _, to string, _ *time.Location) (map[string]float64, error) {
+			return map[string]float64{to: 1.0}, nil
+		},
 	}
 
-	svc := app.NewChartsService(wr, wa)
-	points, err := svc.GetDaily(context.Background(), 1, 1, "kg")
+	svc := app.NewChartsService(&mockWeightRepo{}, wa)
+	points, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 1, Unit: "kg", WaterGoal: domain.DefaultWaterGoalLiters}, time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -112,3 +153,303 @@ func TestGetDaily_NoWeight(t *testing.T) {
 		t.Errorf("expected waterLiters=1.0, got %v", points[0].WaterLiters)
 	}
 }
+
+func TestGetDaily_RepoError(t *testing.T) {
+	wa := &mockWaterRepo{
+		totalsFn: func(_ context.Context, _ int64, _, _ string, _ *time.Location) (map[string]float64, error) {
+			return nil, errors.New("db down")
+		},
+	}
+
+	svc := app.NewChartsService(&mockWeightRepo{}, wa)
+	if _, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 7, Unit: "kg", WaterGoal: domain.DefaultWaterGoalLiters}, time.UTC); err == nil {
+		t.Fatal("expected error from repo")
+	}
+}
+
+func TestGetDaily_BloodPressure(t *testing.T) {
+	br := &mockBloodPressureRepo{
+		avgFn: func(_ context.Context, _ int64, _, to string, _ *time.Location) (map[string]domain.BloodPressureAverage, error) {
+			return map[string]domain.BloodPressureAverage{to: {Systolic: 132, Diastolic: 78, Readings: 2}}, nil
+		},
+	}
+
+	svc := app.NewChartsService(&mockWeightRepo{}, &mockWaterRepo{}).WithBloodPressure(br)
+	points, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 2, Unit: "kg", WaterGoal: domain.DefaultWaterGoalLiters}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if points[0].BloodPressure != nil {
+		t.Errorf("expected no blood pressure on first day, got %+v", points[0].BloodPressure)
+	}
+	bp := points[1].BloodPressure
+	if bp == nil || bp.Systolic != 132 || bp.Readings != 2 || bp.Category != domain.BPStage1 {
+		t.Errorf("unexpected blood pressure point: %+v", bp)
+	}
+}
+
+func TestGetDaily_Sleep(t *testing.T) {
+	sr := &mockSleepRepo{
+		minutesFn: func(_ context.Context, _ int64, _, to string, _ *time.Location) (map[string]float64, error) {
+			return map[string]float64{to: 450}, nil
+		},
+	}
+
+	svc := app.NewChartsService(&mockWeightRepo{}, &mockWaterRepo{}).WithSleep(sr)
+	points, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 2, Unit: "kg", WaterGoal: domain.DefaultWaterGoalLiters}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if points[0].SleepMinutes != nil {
+		t.Errorf("expected no sleep on first day, got %v", *points[0].SleepMinutes)
+	}
+	if points[1].SleepMinutes == nil || *points[1].SleepMinutes != 450 {
+		t.Errorf("expected 450 sleep minutes, got %v", points[1].SleepMinutes)
+	}
+}
+
+func TestGetDaily_Composition(t *testing.T) {
+	fat, muscle := 25.0, 60.0
+	wr := &mockWeightRepo{
+		rangeFn: func(_ context.Context, _ int64, _, to string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+			return map[string]domain.WeightEntry{to: {
+				ID: 1, Day: to, Value: 100, Unit: "kg",
+				BodyComposition: domain.BodyComposition{BodyFatPercent: &fat, MuscleMass: &muscle},
+			}}, nil
+		},
+	}
+
+	svc := app.NewChartsService(wr, &mockWaterRepo{})
+	points, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 1, Unit: "lb", WaterGoal: domain.DefaultWaterGoalLiters}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wp := points[0].Weight
+	if wp == nil || wp.FatMass == nil || wp.LeanMass == nil || wp.MuscleMass == nil {
+		t.Fatalf("expected derived composition, got %+v", wp)
+	}
+	if *wp.FatMass < 55.1 || *wp.FatMass > 55.2 {
+		t.Errorf("expected ~55.12 lb fat mass, got %v", *wp.FatMass)
+	}
+	if *wp.LeanMass < 165.3 || *wp.LeanMass > 165.4 {
+		t.Errorf("expected ~165.35 lb lean mass, got %v", *wp.LeanMass)
+	}
+	if *wp.MuscleMass < 132.2 || *wp.MuscleMass > 132.3 {
+		t.Errorf("expected ~132.28 lb muscle mass, got %v", *wp.MuscleMass)
+	}
+	if *wp.BodyFatPercent != 25 {
+		t.Errorf("expected body fat percentage unchanged, got %v", *wp.BodyFatPercent)
+	}
+	if muscle != 60 {
+		t.Error("conversion must not modify the stored entry")
+	}
+}
+
+func TestGetMeasurements(t *testing.T) {
+	mr := &mockMeasurementRepo{
+		latestFn: func(_ context.Context, _ int64, _, to string, _ *time.Location) (map[string]map[string]domain.Measurement, error) {
+			return map[string]map[string]domain.Measurement{to: {
+				"waist": {Site: "waist", Value: 81.28, Unit: "cm"},
+				"neck":  {Site: "neck", Value: 15, Unit: "in"},
+			}}, nil
+		},
+	}
+
+	svc := app.NewChartsService(&mockWeightRepo{}, &mockWaterRepo{}).WithMeasurements(mr)
+	if _, err := svc.GetMeasurements(context.Background(), 1, 2, "ft", time.UTC); err == nil {
+		t.Fatal("expected error for bad unit")
+	}
+	points, err := svc.GetMeasurements(context.Background(), 1, 2, "in", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(points) != 2 || len(points[0].Sites) != 0 {
+		t.Fatalf("expected an empty first day, got %+v", points)
+	}
+	sites := points[1].Sites
+	if sites["neck"] != 15 || sites["waist"] < 31.99 || sites["waist"] > 32.01 {
+		t.Errorf("unexpected sites in inches: %v", sites)
+	}
+}
+
+func TestGetDaily_WeightGoal(t *testing.T) {
+	goals := &mockGoalRepo{goal: &domain.WeightGoal{TargetValue: 70, Unit: "kg"}}
+	svc := app.NewChartsService(&mockWeightRepo{}, &mockWaterRepo{}).WithWeightGoal(goals)
+	points, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 2, Unit: "lb", WaterGoal: domain.DefaultWaterGoalLiters}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, p := range points {
+		if p.WeightGoal == nil || *p.WeightGoal < 154.32 || *p.WeightGoal > 154.33 {
+			t.Errorf("expected a goal line at ~154.32 lb on %s, got %v", p.Day, p.WeightGoal)
+		}
+	}
+}
+
+// weightsBefore returns a weight mock whose entries are keyed by how many days
+// before the end of the requested range they fall.
+func weightsBefore(t *testing.T, values map[int]float64, gotFrom *string) *mockWeightRepo {
+	t.Helper()
+	return &mockWeightRepo{
+		rangeFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+			*gotFrom = from
+			end, err := time.Parse("2006-01-02", to)
+			if err != nil {
+				t.Fatalf("bad day %q: %v", to, err)
+			}
+			out := map[string]domain.WeightEntry{}
+			for ago, v := range values {
+				day := end.AddDate(0, 0, -ago).Format("2006-01-02")
+				out[day] = domain.WeightEntry{Day: day, Value: v, Unit: "kg"}
+			}
+			return out, nil
+		},
+	}
+}
+
+func trendValues(points []app.DayPoint) []float64 {
+	out := make([]float64, len(points))
+	for i, p := range points {
+		out[i] = -1
+		if p.WeightTrend != nil {
+			out[i] = *p.WeightTrend
+		}
+	}
+	return out
+}
+
+func TestGetDaily_TrendEMA(t *testing.T) {
+	var from string
+	wr := weightsBefore(t, map[int]float64{10: 80, 2: 82}, &from)
+	svc := app.NewChartsService(wr, &mockWaterRepo{})
+	points, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{
+		Days:  4,
+		Unit:  "kg",
+		Trend: app.TrendOptions{Method: app.TrendEMA, Alpha: 0.5},
+	}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// An alpha of 0.5 needs seven days of warm-up before the range.
+	if want := time.Now().UTC().AddDate(0, 0, -10).Format("2006-01-02"); from != want {
+		t.Errorf("expected weights from %s, got %s", want, from)
+	}
+	got := trendValues(points)
+	want := []float64{80, 81, 81, 81}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected trend %v, got %v", want, got)
+		}
+	}
+	if points[0].Weight != nil {
+		t.Errorf("expected the warm-up weigh-in to stay out of the range, got %v", points[0].Weight)
+	}
+}
+
+func TestGetDaily_TrendSMA(t *testing.T) {
+	var from string
+	wr := weightsBefore(t, map[int]float64{3: 80, 1: 83}, &from)
+	svc := app.NewChartsService(wr, &mockWaterRepo{})
+	points, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{
+		Days:  5,
+		Unit:  "kg",
+		Trend: app.TrendOptions{Method: app.TrendSMA, Window: 3},
+	}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Now().UTC().AddDate(0, 0, -6).Format("2006-01-02"); from != want {
+		t.Errorf("expected weights from %s, got %s", want, from)
+	}
+	got := trendValues(points)
+	want := []float64{-1, 80, 80, 81.5, 83}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected trend %v, got %v", want, got)
+		}
+	}
+}
+
+func TestGetDaily_TrendInvalid(t *testing.T) {
+	svc := app.NewChartsService(&mockWeightRepo{}, &mockWaterRepo{})
+	for _, trend := range []app.TrendOptions{
+		{Method: "wma"},
+		{Method: app.TrendEMA, Alpha: 0},
+		{Method: app.TrendEMA, Alpha: 1.5},
+		{Method: app.TrendSMA, Window: 0},
+		{Method: app.TrendSMA, Window: 91},
+	} {
+		if _, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 7, Unit: "kg", Trend: trend}, time.UTC); err == nil {
+			t.Errorf("expected an error for %+v", trend)
+		}
+	}
+}
+
+func TestGetDaily_FromTo(t *testing.T) {
+	var gotFrom, gotTo string
+	wr := &mockWeightRepo{
+		rangeFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+			gotFrom, gotTo = from, to
+			return nil, nil
+		},
+	}
+	svc := app.NewChartsService(wr, &mockWaterRepo{})
+
+	for _, tc := range []struct {
+		name             string
+		q                app.ChartQuery
+		wantFrom, wantTo string
+		wantPoints       int
+	}{
+		{"explicit range", app.ChartQuery{Days: 7, From: "2024-06-01", To: "2024-08-31"}, "2024-06-01", "2024-08-31", 92},
+		{"single day", app.ChartQuery{From: "2024-02-29", To: "2024-02-29"}, "2024-02-29", "2024-02-29", 1},
+		{"days ending on to", app.ChartQuery{Days: 10, To: "2024-03-05"}, "2024-02-25", "2024-03-05", 10},
+	} {
+		tc.q.Unit = "kg"
+		points, err := svc.GetDaily(context.Background(), 1, tc.q, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if len(points) != tc.wantPoints || points[0].Day != tc.wantFrom || points[len(points)-1].Day != tc.wantTo {
+			t.Errorf("%s: expected %d points %s..%s, got %d", tc.name, tc.wantPoints, tc.wantFrom, tc.wantTo, len(points))
+		}
+		if gotFrom != tc.wantFrom || gotTo != tc.wantTo {
+			t.Errorf("%s: queried %s..%s", tc.name, gotFrom, gotTo)
+		}
+	}
+}
+
+func TestGetDaily_FromToInvalid(t *testing.T) {
+	svc := app.NewChartsService(&mockWeightRepo{}, &mockWaterRepo{})
+	for _, q := range []app.ChartQuery{
+		{From: "2024-13-01"},
+		{From: "2024-01-01", To: "yesterday"},
+		{From: "2024-03-02", To: "2024-03-01"},
+		{From: "2023-01-01", To: "2024-01-02"},
+	} {
+		q.Unit = "kg"
+		if _, err := svc.GetDaily(context.Background(), 1, q, time.UTC); err == nil {
+			t.Errorf("expected an error for %s..%s", q.From, q.To)
+		}
+	}
+}
+
+func TestGetDaily_WeightPolicy(t *testing.T) {
+	wr := &mockWeightRepo{}
+	svc := app.NewChartsService(wr, &mockWaterRepo{})
+	if _, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 7, Unit: "kg"}, time.UTC); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wr.gotPolicy != domain.DefaultWeightPolicy {
+		t.Errorf("expected the default policy, got %q", wr.gotPolicy)
+	}
+	if _, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 7, Unit: "kg", WeightPolicy: domain.WeightPolicyMean}, time.UTC); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wr.gotPolicy != domain.WeightPolicyMean {
+		t.Errorf("expected the mean policy, got %q", wr.gotPolicy)
+	}
+	if _, err := svc.GetDaily(context.Background(), 1, app.ChartQuery{Days: 7, Unit: "kg", WeightPolicy: "median"}, time.UTC); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// eventKey identifies an event by its content, so that the same reading
+// imported twice can be recognized.
+type eventKey struct {
+	kind  string
+	at    int64
+	value float64
+	unit  string
+}
+
+// eventSet tracks the events of a user's history by content.
+type eventSet map[eventKey]struct{}
+
+// newEventSet returns a set holding the given events.
+func newEventSet(weights []domain.WeightEntry, water []domain.WaterEvent) eventSet {
+	s := eventSet{}
+	for _, e := range weights {
+		s.addWeight(e)
+	}
+	for _, e := range water {
+		s.addWater(e)
+	}
+	return s
+}
+
+// addWeight adds a weigh-in, reporting whether it was not already present.
+func (s eventSet) addWeight(e domain.WeightEntry) bool {
+	return s.add(eventKey{kind: "weight", at: eventInstant(e.CreatedAt), value: e.Value, unit: e.Unit})
+}
+
+// addWater adds a water event, reporting whether it was not already present.
+func (s eventSet) addWater(e domain.WaterEvent) bool {
+	return s.add(eventKey{kind: "water", at: eventInstant(e.CreatedAt), value: e.DeltaLiters})
+}
+
+func (s eventSet) add(k eventKey) bool {
+	if _, ok := s[k]; ok {
+		return false
+	}
+	s[k] = struct{}{}
+	return true
+}
+
+// eventInstant truncates t to the microsecond precision kept by PostgreSQL.
+func eventInstant(t time.Time) int64 {
+	return t.Truncate(time.Microsecond).UnixMicro()
+}
+
+// eventHistory returns all of the user's weight and water events, newest
+// first.
+func eventHistory(ctx context.Context, wr domain.WeightRepository, wa domain.WaterRepository, userID int64) ([]domain.WeightEntry, []domain.WaterEvent, error) {
+	q := domain.EventQuery{Limit: exportPage}
+	weights, err := listAll(q, func(q domain.EventQuery) ([]domain.WeightEntry, error) {
+		return wr.ListWeightEvents(ctx, userID, q, time.UTC)
+	}, func(e domain.WeightEntry) domain.EventCursor {
+		return domain.EventCursor{CreatedAt: e.CreatedAt, ID: e.ID}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	water, err := listAll(q, func(q domain.EventQuery) ([]domain.WaterEvent, error) {
+		return wa.ListWaterEvents(ctx, userID, q)
+	}, func(e domain.WaterEvent) domain.EventCursor {
+		return domain.EventCursor{CreatedAt: e.CreatedAt, ID: e.ID}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return weights, water, nil
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// MaxBackdate is how far in the past a new entry may be placed.
+	MaxBackdate = 365 * 24 * time.Hour
+	// clockSkew tolerates small differences between client and server clocks.
+	clockSkew = 5 * time.Minute
+)
+
+// EntryTime optionally places a new entry at a time other than now. At most
+// one field may be set; with neither set the entry is stamped with the
+// current time.
+type EntryTime struct {
+	// At is the exact instant of the entry.
+	At *time.Time
+	// Day is a local day ("2006-01-02"). Past days are stamped at local noon;
+	// today is stamped with the current time.
+	Day string
+}
+
+// resolve returns the instant to store for the entry, validating that it is
+// neither in the future nor older than MaxBackdate.
+func (e EntryTime) resolve(now time.Time, loc *time.Location) (time.Time, error) {
+	if e.At != nil && e.Day != "" {
+		return time.Time{}, errors.New("specify either at or day, not both")
+	}
+
+	t := now
+	switch {
+	case e.At != nil:
+		t = *e.At
+	case e.Day != "":
+		day, err := time.ParseInLocation("2006-01-02", e.Day, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("day must be YYYY-MM-DD: %w", err)
+		}
+		if e.Day != now.In(loc).Format("2006-01-02") {
+			t = time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, loc)
+		}
+	}
+
+	if t.After(now.Add(clockSkew)) {
+		return time.Time{}, errors.New("entry cannot be in the future")
+	}
+	if now.Sub(t) > MaxBackdate {
+		return time.Time{}, fmt.Errorf("entry cannot be more than %d days old", int(MaxBackdate.Hours()/24))
+	}
+	return t, nil
+}
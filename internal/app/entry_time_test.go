@@ -0,0 +1,65 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryTimeResolve(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, loc)
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name    string
+		entry   EntryTime
+		want    time.Time
+		wantErr bool
+	}{
+		{"zero value is now", EntryTime{}, now, false},
+		{"exact instant", EntryTime{At: ptr(now.Add(-3 * time.Hour))}, now.Add(-3 * time.Hour), false},
+		{"small clock skew allowed", EntryTime{At: ptr(now.Add(time.Minute))}, now.Add(time.Minute), false},
+		{"today is now", EntryTime{Day: "2026-03-10"}, now, false},
+		{"past day at local noon", EntryTime{Day: "2026-03-08"}, time.Date(2026, 3, 8, 12, 0, 0, 0, loc), false},
+		{"future instant", EntryTime{At: ptr(now.Add(time.Hour))}, time.Time{}, true},
+		{"future day", EntryTime{Day: "2026-03-11"}, time.Time{}, true},
+		{"too old", EntryTime{At: ptr(now.Add(-MaxBackdate - time.Hour))}, time.Time{}, true},
+		{"bad day", EntryTime{Day: "03/08/2026"}, time.Time{}, true},
+		{"both set", EntryTime{At: ptr(now), Day: "2026-03-10"}, time.Time{}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.entry.resolve(now, loc)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("resolve() = %v; want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEntryTimeResolve_DST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	now := time.Date(2026, 3, 10, 20, 0, 0, 0, ny)
+	// Clocks fell back on November 2nd, 2025 and sprang forward on March 8th, 2026.
+	for _, day := range []string{"2026-03-08", "2025-11-02"} {
+		got, err := EntryTime{Day: day}.resolve(now, ny)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", day, err)
+		}
+		if local := got.In(ny); local.Format("2006-01-02 15:04") != day+" 12:00" {
+			t.Errorf("%s: expected local noon, got %v", day, local)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package app
+
+// classError is err marked as belonging to class, a sentinel that callers
+// match with errors.Is to tell invalid input from other failures, while the
+// error keeps its own message.
+type classError struct {
+	class error
+	err   error
+}
+
+func (e classError) Error() string        { return e.err.Error() }
+func (e classError) Unwrap() error        { return e.err }
+func (e classError) Is(target error) bool { return target == e.class }
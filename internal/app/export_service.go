@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// exportPage is the number of events fetched per repository call while
+// exporting.
+const exportPage = 500
+
+// exportHeader is the first row of a CSV export.
+var exportHeader = []string{"type", "id", "timestamp", "day", "value", "unit"}
+
+// ExportService encapsulates data export use cases.
+type ExportService struct {
+	weightRepo domain.WeightRepository
+	waterRepo  domain.WaterRepository
+}
+
+// NewExportService creates an ExportService backed by the given repositories.
+func NewExportService(wr domain.WeightRepository, wa domain.WaterRepository) *ExportService {
+	return &ExportService{weightRepo: wr, waterRepo: wa}
+}
+
+// ExportOptions selects the events and units of an export.
+type ExportOptions struct {
+	// From and To bound the export as in ListOptions; empty means unbounded.
+	From string
+	To   string
+	// Unit converts weights to "kg" or "lb"; empty keeps each weigh-in's
+	// recorded unit.
+	Unit string
+}
+
+// Validate reports whether the options are well formed, so callers can
+// reject them before any output is written.
+func (o ExportOptions) Validate() error {
+	_, err := o.query(time.UTC)
+	return err
+}
+
+// query converts the options to the repository query of the first page.
+func (o ExportOptions) query(loc *time.Location) (domain.EventQuery, error) {
+	if o.Unit != "" && o.Unit != "kg" && o.Unit != "lb" {
+		return domain.EventQuery{}, errors.New("unit must be \"kg\" or \"lb\"")
+	}
+	q, err := ListOptions{From: o.From, To: o.To}.query(loc)
+	if err != nil {
+		return q, err
+	}
+	q.Limit = exportPage
+	return q, nil
+}
+
+// WriteCSV streams the user's weight events followed by their water events,
+// each newest first, to w as CSV with the columns type, id, timestamp (RFC
+// 3339, UTC), day (local day in loc), value and unit. Water values are in
+// liters with the unit "L". Events are fetched and written a page at a time,
+// so an error may leave w with a partial export.
+func (s *ExportService) WriteCSV(ctx context.Context, userID int64, opts ExportOptions, w io.Writer, loc *time.Location) error {
+	first, err := opts.query(loc)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportHeader); err != nil {
+		return err
+	}
+
+	err = eachPage(first, func(q domain.EventQuery) ([]domain.WeightEntry, error) {
+		return s.weightRepo.ListWeightEvents(ctx, userID, q, loc)
+	}, func(e domain.WeightEntry) domain.EventCursor {
+		return domain.EventCursor{CreatedAt: e.CreatedAt, ID: e.ID}
+	}, func(page []domain.WeightEntry) error {
+		for _, e := range page {
+			value, unit := e.Value, e.Unit
+			if opts.Unit != "" {
+				value, unit = domain.ConvertWeight(e.Value, e.Unit, opts.Unit), opts.Unit
+			}
+			if err := cw.Write(exportRow("weight", e.ID, e.CreatedAt, value, unit, loc)); err != nil {
+				return err
+			}
+		}
+		return flushCSV(cw)
+	})
+	if err != nil {
+		return err
+	}
+
+	return eachPage(first, func(q domain.EventQuery) ([]domain.WaterEvent, error) {
+		return s.waterRepo.ListWaterEvents(ctx, userID, q)
+	}, func(e domain.WaterEvent) domain.EventCursor {
+		return domain.EventCursor{CreatedAt: e.CreatedAt, ID: e.ID}
+	}, func(page []domain.WaterEvent) error {
+		for _, e := range page {
+			if err := cw.Write(exportRow("water", e.ID, e.CreatedAt, e.DeltaLiters, "L", loc)); err != nil {
+				return err
+			}
+		}
+		return flushCSV(cw)
+	})
+}
+
+// exportRow formats a single event as a CSV record.
+func exportRow(kind string, id int64, at time.Time, value float64, unit string, loc *time.Location) []string {
+	return []string{
+		kind,
+		strconv.FormatInt(id, 10),
+		at.UTC().Format(time.RFC3339Nano),
+		at.In(loc).Format("2006-01-02"),
+		strconv.FormatFloat(value, 'f', -1, 64),
+		unit,
+	}
+}
+
+// flushCSV writes any buffered records to the underlying writer.
+func flushCSV(cw *csv.Writer) error {
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package app_test
+
+import (
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+func TestWriteCSV(t *testing.T) {
+	base := time.Date(2024, 3, 1, 23, 30, 0, 0, time.UTC)
+	// 1001 weigh-ins, newest first, span three pages.
+	var weights []domain.WeightEntry
+	for i := 1001; i >= 1; i-- {
+		weights = append(weights, domain.WeightEntry{ID: int64(i), Value: 100, Unit: "kg", CreatedAt: base.Add(time.Duration(i) * time.Minute)})
+	}
+	var calls int
+	var gotFrom, gotTo time.Time
+	wr := &mockWeightRepo{
+		queryFn: func(_ context.Context, _ int64, q domain.EventQuery, _ *time.Location) ([]domain.WeightEntry, error) {
+			calls++
+			gotFrom, gotTo = q.From, q.To
+			start := 0
+			if q.After != nil {
+				start = int(int64(len(weights)) - q.After.ID + 1)
+			}
+			return weights[start:min(start+q.Limit, len(weights))], nil
+		},
+	}
+	wa := &mockWaterRepo{
+		queryFn: func(_ context.Context, _ int64, _ domain.EventQuery) ([]domain.WaterEvent, error) {
+			return []domain.WaterEvent{{ID: 7, DeltaLiters: 0.25, CreatedAt: base}}, nil
+		},
+	}
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	var buf strings.Builder
+	svc := app.NewExportService(wr, wa)
+	if err := svc.WriteCSV(context.Background(), 1, app.ExportOptions{From: "2024-03-01", To: "2024-03-02", Unit: "lb"}, &buf, ny); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected three pages of weigh-ins, got %d calls", calls)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, ny); !gotFrom.Equal(want) || !gotTo.Equal(want.AddDate(0, 0, 2)) {
+		t.Errorf("unexpected bounds %v..%v", gotFrom, gotTo)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+	if len(records) != 1+1001+1 {
+		t.Fatalf("expected a header and 1002 rows, got %d", len(records))
+	}
+	if strings.Join(records[0], ",") != "type,id,timestamp,day,value,unit" {
+		t.Errorf("unexpected header %v", records[0])
+	}
+	if got := strings.Join(records[1], ","); got != "weight,1001,2024-03-02T16:11:00Z,2024-03-02,220.46226218,lb" {
+		t.Errorf("unexpected first row %s", got)
+	}
+	if got := strings.Join(records[1002], ","); got != "water,7,2024-03-01T23:30:00Z,2024-03-01,0.25,L" {
+		t.Errorf("unexpected water row %s", got)
+	}
+}
+
+func TestWriteCSV_Invalid(t *testing.T) {
+	for _, opts := range []app.ExportOptions{
+		{Unit: "st"},
+		{From: "March"},
+		{From: "2024-03-02", To: "2024-03-01"},
+	} {
+		if err := opts.Validate(); err == nil {
+			t.Errorf("expected an error for %+v", opts)
+		}
+		var buf strings.Builder
+		if err := app.NewExportService(&mockWeightRepo{}, &mockWaterRepo{}).WriteCSV(context.Background(), 1, opts, &buf, time.UTC); err == nil || buf.Len() != 0 {
+			t.Errorf("expected an error before any output for %+v, wrote %q", opts, buf.String())
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// ErrNoGoal indicates that the user has not set the requested goal.
+var ErrNoGoal = errors.New("no goal set")
+
+// ErrInvalidGoal indicates an invalid goal or unit. The errors matching it
+// keep their own message.
+var ErrInvalidGoal = errors.New("invalid goal")
+
+// invalidGoal returns err marked as matching ErrInvalidGoal.
+func invalidGoal(err error) error {
+	return classError{class: ErrInvalidGoal, err: err}
+}
+
+const (
+	// trendWindow is how far back from the latest weigh-in the trend is fitted.
+	trendWindow = 28 * 24 * time.Hour
+	// trendSampleLimit caps the weigh-ins fetched to fit the trend.
+	trendSampleLimit = 200
+	// goalTolerance is how close, in the goal's unit, the latest weight must be
+	// to the target for the goal to count as reached.
+	goalTolerance = 0.1
+	// maxProjectionDays bounds how far ahead a goal date is projected.
+	maxProjectionDays = 3650
+)
+
+// GoalService encapsulates goal-tracking use cases.
+type GoalService struct {
+	goals   domain.WeightGoalRepository
+	weights domain.WeightRepository
+}
+
+// NewGoalService creates a GoalService backed by the given repositories.
+func NewGoalService(goals domain.WeightGoalRepository, weights domain.WeightRepository) *GoalService {
+	return &GoalService{goals: goals, weights: weights}
+}
+
+// WeightGoalProgress reports progress towards a weight goal. All weights and
+// rates are in Unit; rates are signed, so losing weight is negative.
+type WeightGoalProgress struct {
+	Goal   domain.WeightGoal `json:"goal"`
+	Unit   string            `json:"unit"`
+	Target float64           `json:"target"`
+	// Direction is domain.GoalLose, GoalGain or GoalMaintain, or empty if it
+	// is not known yet.
+	Direction string `json:"direction"`
+	// Current is the latest weigh-in, or nil if there is none.
+	Current *float64 `json:"current"`
+	// Remaining is Target minus Current, or 0 once the target is passed in
+	// the goal's direction.
+	Remaining *float64 `json:"remaining"`
+	// Reached reports whether Current is within goalTolerance of the target
+	// or past it in the goal's direction.
+	Reached bool `json:"reached"`
+	// RatePerWeek is the slope of a least-squares fit of the weigh-ins in the
+	// four weeks up to the latest one, or nil if there are too few.
+	RatePerWeek *float64 `json:"ratePerWeek"`
+	// RequiredPerWeek is the rate needed to reach the target by its date.
+	RequiredPerWeek *float64 `json:"requiredPerWeek"`
+	// ProjectedDate is the local day the target is reached at RatePerWeek, or
+	// nil if the trend is not heading towards it.
+	ProjectedDate *string `json:"projectedDate"`
+	// OnPace reports whether ProjectedDate is no later than the goal's target
+	// date. It is nil when the goal has no date or there is no trend yet.
+	OnPace *bool `json:"onPace"`
+}
+
+// SetWeightGoal validates and stores the user's weight goal, starting from the
+// latest weigh-in. targetDate is an optional local day in loc, which must be
+// in the future. Validation errors match ErrInvalidGoal.
+func (s *GoalService) SetWeightGoal(ctx context.Context, userID int64, target float64, unit, targetDate string, loc *time.Location) (*domain.WeightGoal, error) {
+	if err := validateWeight(target, unit); err != nil {
+		return nil, invalidGoal(err)
+	}
+	if targetDate != "" {
+		day, err := time.ParseInLocation("2006-01-02", targetDate, loc)
+		if err != nil {
+			return nil, invalidGoal(errors.New("targetDate must be YYYY-MM-DD"))
+		}
+		if !day.After(time.Now()) {
+			return nil, invalidGoal(errors.New("targetDate must be in the future"))
+		}
+	}
+	g := domain.WeightGoal{
+		UserID:      userID,
+		TargetValue: target,
+		Unit:        unit,
+		TargetDate:  targetDate,
+		CreatedAt:   time.Now().UTC(),
+	}
+	latest, err := s.weights.ListRecentWeightEvents(ctx, userID, 1, loc)
+	if err != nil {
+		return nil, err
+	}
+	if len(latest) > 0 {
+		start := domain.ConvertWeight(latest[0].Value, latest[0].Unit, unit)
+		g.StartValue = &start
+	}
+	if err := s.goals.SetWeightGoal(ctx, userID, g); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+// GetWeightGoal returns the user's weight goal.
+func (s *GoalService) GetWeightGoal(ctx context.Context, userID int64) (*domain.WeightGoal, error) {
+	g, err := s.goals.GetWeightGoal(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrNoGoal
+	}
+	return g, nil
+}
+
+// DeleteWeightGoal removes the user's weight goal.
+func (s *GoalService) DeleteWeightGoal(ctx context.Context, userID int64) error {
+	found, err := s.goals.DeleteWeightGoal(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNoGoal
+	}
+	return nil
+}
+
+// GetWeightGoalProgress measures the user's recent weigh-ins against their
+// weight goal, reporting weights in unit, or in the goal's unit if unit is
+// empty. Local days are interpreted in loc. An unknown unit gives an error
+// matching ErrInvalidGoal.
+func (s *GoalService) GetWeightGoalProgress(ctx context.Context, userID int64, unit string, loc *time.Location) (*WeightGoalProgress, error) {
+	if unit != "" && unit != "kg" && unit != "lb" {
+		return nil, invalidGoal(errors.New("unit must be \"kg\" or \"lb\""))
+	}
+	g, err := s.GetWeightGoal(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if unit == "" {
+		unit = g.Unit
+	}
+	entries, err := s.weights.ListRecentWeightEvents(ctx, userID, trendSampleLimit, loc)
+	if err != nil {
+		return nil, err
+	}
+	return weightGoalProgress(*g, unit, entries, time.Now(), loc), nil
+}
+
+// weightGoalProgress computes the progress towards g from entries, newest
+// first, as of now.
+func weightGoalProgress(g domain.WeightGoal, unit string, entries []domain.WeightEntry, now time.Time, loc *time.Location) *WeightGoalProgress {
+	p := &WeightGoalProgress{Goal: g, Unit: unit, Target: g.Target(unit)}
+	if start, ok := goalStart(g, entries); ok {
+		p.Direction = g.Direction(start, goalTolerance)
+	}
+	if len(entries) == 0 {
+		return p
+	}
+
+	latest := entries[0]
+	current := domain.ConvertWeight(latest.Value, latest.Unit, unit)
+	remaining := p.Target - current
+	tolerance := domain.ConvertWeight(goalTolerance, g.Unit, unit)
+	switch {
+	case p.Direction == domain.GoalLose && remaining >= 0,
+		p.Direction == domain.GoalGain && remaining <= 0:
+		remaining = 0
+		p.Reached = true
+	default:
+		p.Reached = math.Abs(remaining) <= tolerance
+	}
+	p.Current = &current
+	p.Remaining = &remaining
+
+	today, _ := time.ParseInLocation("2006-01-02", now.In(loc).Format("2006-01-02"), loc)
+	var deadline time.Time
+	if g.TargetDate != "" {
+		deadline, _ = time.ParseInLocation("2006-01-02", g.TargetDate, loc)
+		if days := daysBetween(today, deadline); days > 0 && !p.Reached {
+			required := remaining / float64(days) * 7
+			p.RequiredPerWeek = &required
+		}
+	}
+
+	var projected time.Time
+	if perDay, ok := weightTrend(entries, unit); ok {
+		perWeek := perDay * 7
+		p.RatePerWeek = &perWeek
+		if !p.Reached && perDay != 0 && (remaining > 0) == (perDay > 0) {
+			// The epsilon keeps rounding noise in the fit from adding a day.
+			if days := math.Ceil(remaining/perDay - 1e-9); days <= maxProjectionDays {
+				from := latest.CreatedAt.In(loc)
+				projected = time.Date(from.Year(), from.Month(), from.Day()+int(days), 0, 0, 0, 0, loc)
+				day := projected.Format("2006-01-02")
+				p.ProjectedDate = &day
+			}
+		}
+	}
+
+	if g.TargetDate != "" {
+		var onPace bool
+		switch {
+		case p.Reached:
+			onPace = true
+		case p.ProjectedDate != nil:
+			onPace = !projected.After(deadline)
+		case p.RatePerWeek == nil:
+			return p
+		}
+		p.OnPace = &onPace
+	}
+	return p
+}
+
+// goalStart returns the weight, in the goal's unit, that g started from: its
+// StartValue, or else the earliest of the entries, newest first, logged since
+// the goal was set. It reports false if neither is known.
+func goalStart(g domain.WeightGoal, entries []domain.WeightEntry) (float64, bool) {
+	if g.StartValue != nil {
+		return *g.StartValue, true
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if e := entries[i]; !e.CreatedAt.Before(g.CreatedAt) {
+			return domain.ConvertWeight(e.Value, e.Unit, g.Unit), true
+		}
+	}
+	return 0, false
+}
+
+// weightTrend fits a least-squares line through the entries, newest first,
+// within trendWindow of the latest one and returns its slope in unit per day.
+// It reports false if fewer than two entries spanning at least a day remain.
+func weightTrend(entries []domain.WeightEntry, unit string) (float64, bool) {
+	latest := entries[0].CreatedAt
+	var n, sumX, sumY, sumXY, sumXX float64
+	var earliest time.Time
+	for _, e := range entries {
+		if latest.Sub(e.CreatedAt) > trendWindow {
+			break
+		}
+		x := e.CreatedAt.Sub(latest).Hours() / 24
+		y := domain.ConvertWeight(e.Value, e.Unit, unit)
+		n++
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+		earliest = e.CreatedAt
+	}
+	if n < 2 || latest.Sub(earliest) < 24*time.Hour {
+		return 0, false
+	}
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX), true
+}
+
+// daysBetween returns the number of calendar days from a to b, both local
+// midnights.
+func daysBetween(a, b time.Time) int {
+	return int(math.Round(b.Sub(a).Hours() / 24))
+}
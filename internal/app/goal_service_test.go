@@ -0,0 +1,199 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+type mockGoalRepo struct {
+	goal *domain.WeightGoal
+}
+
+func (m *mockGoalRepo) GetWeightGoal(ctx context.Context, userID int64) (*domain.WeightGoal, error) {
+	return m.goal, nil
+}
+
+func (m *mockGoalRepo) SetWeightGoal(ctx context.Context, userID int64, g domain.WeightGoal) error {
+	m.goal = &g
+	return nil
+}
+
+func (m *mockGoalRepo) DeleteWeightGoal(ctx context.Context, userID int64) (bool, error) {
+	found := m.goal != nil
+	m.goal = nil
+	return found, nil
+}
+
+// dailyWeights returns one kg entry per day for the last len(values) days,
+// newest first, ending now with the last value.
+func dailyWeights(values ...float64) []domain.WeightEntry {
+	now := time.Now().UTC()
+	out := make([]domain.WeightEntry, 0, len(values))
+	for i := len(values) - 1; i >= 0; i-- {
+		daysAgo := len(values) - 1 - i
+		out = append(out, domain.WeightEntry{ID: int64(i + 1), Value: values[i], Unit: "kg", CreatedAt: now.AddDate(0, 0, -daysAgo)})
+	}
+	return out
+}
+
+func TestSetWeightGoal_Validation(t *testing.T) {
+	svc := app.NewGoalService(&mockGoalRepo{}, &mockWeightRepo{})
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	tests := []struct {
+		name   string
+		target float64
+		unit   string
+		date   string
+	}{
+		{"non-positive target", 0, "kg", ""},
+		{"bad unit", 70, "st", ""},
+		{"bad date", 70, "kg", "next week"},
+		{"past date", 70, "kg", yesterday},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.SetWeightGoal(context.Background(), 1, tc.target, tc.unit, tc.date, time.UTC); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+	if _, err := svc.SetWeightGoal(context.Background(), 1, 70, "kg", tomorrow, time.UTC); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWeightGoalProgress_OnPace(t *testing.T) {
+	deadline := time.Now().AddDate(0, 0, 60).Format("2006-01-02")
+	goals := &mockGoalRepo{goal: &domain.WeightGoal{TargetValue: 75, Unit: "kg", TargetDate: deadline}}
+	weights := &mockWeightRepo{
+		listFn: func(_ context.Context, _ int64, _ int, _ *time.Location) ([]domain.WeightEntry, error) {
+			// Losing 0.25 kg a day, down to 79 kg today.
+			return dailyWeights(81, 80.75, 80.5, 80.25, 80, 79.75, 79.5, 79.25, 79), nil
+		},
+	}
+	svc := app.NewGoalService(goals, weights)
+	p, err := svc.GetWeightGoalProgress(context.Background(), 1, "", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Current == nil || *p.Current != 79 || *p.Remaining != -4 || p.Reached {
+		t.Fatalf("unexpected progress: %+v", p)
+	}
+	if p.RatePerWeek == nil || math.Abs(*p.RatePerWeek+1.75) > 1e-6 {
+		t.Fatalf("expected -1.75 kg/week, got %v", p.RatePerWeek)
+	}
+	want := time.Now().UTC().AddDate(0, 0, 16).Format("2006-01-02")
+	if p.ProjectedDate == nil || *p.ProjectedDate != want {
+		t.Errorf("expected projected date %s, got %v", want, p.ProjectedDate)
+	}
+	if p.OnPace == nil || !*p.OnPace {
+		t.Errorf("expected to be on pace, got %v", p.OnPace)
+	}
+	if p.RequiredPerWeek == nil || math.Abs(*p.RequiredPerWeek+4.0/60*7) > 1e-6 {
+		t.Errorf("unexpected required rate %v", p.RequiredPerWeek)
+	}
+
+	lb, err := svc.GetWeightGoalProgress(context.Background(), 1, "lb", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(lb.Target-165.35) > 0.01 || math.Abs(*lb.RatePerWeek+3.86) > 0.01 {
+		t.Errorf("expected progress in lb, got target %v rate %v", lb.Target, *lb.RatePerWeek)
+	}
+}
+
+func TestWeightGoalProgress_WrongDirection(t *testing.T) {
+	deadline := time.Now().AddDate(0, 0, 30).Format("2006-01-02")
+	goals := &mockGoalRepo{goal: &domain.WeightGoal{TargetValue: 75, Unit: "kg", TargetDate: deadline}}
+	weights := &mockWeightRepo{
+		listFn: func(_ context.Context, _ int64, _ int, _ *time.Location) ([]domain.WeightEntry, error) {
+			return dailyWeights(78, 78.5, 79), nil
+		},
+	}
+	p, err := app.NewGoalService(goals, weights).GetWeightGoalProgress(context.Background(), 1, "", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.ProjectedDate != nil {
+		t.Errorf("expected no projection while gaining, got %v", *p.ProjectedDate)
+	}
+	if p.OnPace == nil || *p.OnPace {
+		t.Errorf("expected to be off pace, got %v", p.OnPace)
+	}
+}
+
+func TestWeightGoalProgress_NoData(t *testing.T) {
+	svc := app.NewGoalService(&mockGoalRepo{}, &mockWeightRepo{})
+	if _, err := svc.GetWeightGoalProgress(context.Background(), 1, "", time.UTC); !errors.Is(err, app.ErrNoGoal) {
+		t.Fatalf("expected ErrNoGoal, got %v", err)
+	}
+
+	goals := &mockGoalRepo{goal: &domain.WeightGoal{TargetValue: 75, Unit: "kg", TargetDate: "2099-01-01"}}
+	p, err := app.NewGoalService(goals, &mockWeightRepo{}).GetWeightGoalProgress(context.Background(), 1, "", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Current != nil || p.RatePerWeek != nil || p.OnPace != nil {
+		t.Errorf("expected an empty progress report, got %+v", p)
+	}
+}
+
+func TestSetWeightGoal_StartValue(t *testing.T) {
+	goals := &mockGoalRepo{}
+	weights := &mockWeightRepo{
+		listFn: func(_ context.Context, _ int64, _ int, _ *time.Location) ([]domain.WeightEntry, error) {
+			return []domain.WeightEntry{{ID: 1, Value: 180, Unit: "lb", CreatedAt: time.Now()}}, nil
+		},
+	}
+	g, err := app.NewGoalService(goals, weights).SetWeightGoal(context.Background(), 1, 75, "kg", "", time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.StartValue == nil || math.Abs(*g.StartValue-81.65) > 0.01 || goals.goal.StartValue == nil {
+		t.Fatalf("expected the latest weigh-in in kg as the start, got %v", g.StartValue)
+	}
+	if dir := g.Direction(*g.StartValue, 0.1); dir != domain.GoalLose {
+		t.Errorf("expected a loss goal, got %q", dir)
+	}
+}
+
+func TestWeightGoalProgress_Overshoot(t *testing.T) {
+	deadline := time.Now().AddDate(0, 0, 30).Format("2006-01-02")
+	start, gainStart := 80.0, 60.0
+	tests := []struct {
+		name    string
+		goal    domain.WeightGoal
+		weights []domain.WeightEntry
+		dir     string
+	}{
+		{"loss", domain.WeightGoal{TargetValue: 75, Unit: "kg", TargetDate: deadline, StartValue: &start}, dailyWeights(76, 75.5, 74.2), domain.GoalLose},
+		{"gain", domain.WeightGoal{TargetValue: 65, Unit: "kg", TargetDate: deadline, StartValue: &gainStart}, dailyWeights(64, 64.8, 66), domain.GoalGain},
+		{"loss without start", domain.WeightGoal{TargetValue: 75, Unit: "kg", TargetDate: deadline}, dailyWeights(76, 75.5, 74.2), domain.GoalLose},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			weights := &mockWeightRepo{
+				listFn: func(_ context.Context, _ int64, _ int, _ *time.Location) ([]domain.WeightEntry, error) {
+					return tc.weights, nil
+				},
+			}
+			p, err := app.NewGoalService(&mockGoalRepo{goal: &tc.goal}, weights).GetWeightGoalProgress(context.Background(), 1, "", time.UTC)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Direction != tc.dir || !p.Reached || *p.Remaining != 0 {
+				t.Errorf("expected a passed %s goal to be reached, got %+v", tc.dir, p)
+			}
+			if p.OnPace == nil || !*p.OnPace || p.ProjectedDate != nil || p.RequiredPerWeek != nil {
+				t.Errorf("expected on pace without a projection, got %+v", p)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Apple Health record types imported by ImportAppleHealth.
+const (
+	appleBodyMass     = "HKQuantityTypeIdentifierBodyMass"
+	appleDietaryWater = "HKQuantityTypeIdentifierDietaryWater"
+)
+
+// appleDateLayout is the layout of the dates in an Apple Health export.
+const appleDateLayout = "2006-01-02 15:04:05 -0700"
+
+// appleWaterLiters converts Apple Health volume units to liters.
+var appleWaterLiters = map[string]float64{
+	"mL":        0.001,
+	"cL":        0.01,
+	"dL":        0.1,
+	"L":         1,
+	"fl_oz_us":  0.0295735295625,
+	"fl_oz_imp": 0.0284130625,
+	"cup_us":    0.2365882365,
+	"cup_imp":   0.284130625,
+	"pt_us":     0.473176473,
+	"pt_imp":    0.56826125,
+}
+
+// ImportAppleHealth reads the body mass and dietary water records of the
+// export.xml file of an Apple Health export from r and stores those not yet
+// in the user's history in a single batch. The file is parsed as a stream, so
+// its size is not limited by memory. Weights in grams or stones are
+// converted to kilograms or pounds and water to liters; each record is placed
+// at its start date. Records that cannot be converted or fail validation are
+// skipped, with errors giving their line in the file. Errors for an invalid
+// file match ErrInvalidImport.
+func (s *ImportService) ImportAppleHealth(ctx context.Context, userID int64, r io.Reader, opts HealthImportOptions) (*HealthImportResult, error) {
+	h, err := s.newHealthImport(ctx, userID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := xml.NewDecoder(bufio.NewReaderSize(r, 1<<16))
+	foundRoot := false
+	for {
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, invalidImport(fmt.Errorf("invalid export.xml: %w", err))
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "HealthData":
+			foundRoot = true
+		case "Record":
+			line, _ := dec.InputPos()
+			addAppleRecord(h, line, start.Attr)
+		}
+	}
+	if !foundRoot {
+		return nil, invalidImport(fmt.Errorf("not an Apple Health export: no HealthData element"))
+	}
+	return s.commitHealthImport(ctx, userID, h)
+}
+
+// addAppleRecord adds the weight or water of a Record element with the given
+// attributes, ignoring other record types.
+func addAppleRecord(h *healthImport, line int, attrs []xml.Attr) {
+	var typ, unit, value, startDate string
+	for _, a := range attrs {
+		switch a.Name.Local {
+		case "type":
+			typ = a.Value
+		case "unit":
+			unit = a.Value
+		case "value":
+			value = a.Value
+		case "startDate":
+			startDate = a.Value
+		}
+	}
+	if typ != appleBodyMass && typ != appleDietaryWater {
+		return
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		h.skipRecord(line, fmt.Errorf("invalid value %q", value))
+		return
+	}
+	at, err := time.Parse(appleDateLayout, startDate)
+	if err != nil {
+		h.skipRecord(line, fmt.Errorf("invalid startDate %q", startDate))
+		return
+	}
+
+	if typ == appleDietaryWater {
+		factor, ok := appleWaterLiters[unit]
+		if !ok {
+			h.skipRecord(line, fmt.Errorf("unsupported water unit %q", unit))
+			return
+		}
+		h.addWater(line, v*factor, at)
+		return
+	}
+	switch unit {
+	case "kg", "lb":
+	case "g":
+		v, unit = v/1000, "kg"
+	case "st":
+		v, unit = v*14, "lb"
+	default:
+		h.skipRecord(line, fmt.Errorf("unsupported weight unit %q", unit))
+		return
+	}
+	h.addWeight(line, v, unit, at)
+}
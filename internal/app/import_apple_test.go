@@ -0,0 +1,108 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+const appleExport = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE HealthData [
+<!ELEMENT HealthData (ExportDate,Me,(Record|Workout)*)>
+<!ATTLIST HealthData locale CDATA #REQUIRED>
+]>
+<HealthData locale="en_US">
+ <ExportDate value="2024-03-10 09:00:00 -0800"/>
+ <Me HKCharacteristicTypeIdentifierBiologicalSex="HKBiologicalSexNotSet"/>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Scale" unit="lb" creationDate="2024-03-01 07:31:00 -0800" startDate="2024-03-01 07:30:00 -0800" endDate="2024-03-01 07:30:00 -0800" value="180.4"/>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Scale" unit="lb" creationDate="2024-03-01 07:31:00 -0800" startDate="2024-03-01 07:30:00 -0800" endDate="2024-03-01 07:30:00 -0800" value="180.4"/>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Health" unit="g" creationDate="2024-03-02 07:31:00 -0800" startDate="2024-03-02 07:30:00 -0800" endDate="2024-03-02 07:30:00 -0800" value="81500">
+  <MetadataEntry key="HKWasUserEntered" value="1"/>
+ </Record>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Health" unit="st" startDate="2024-03-03 07:30:00 -0800" value="12.5"/>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Health" unit="oz" startDate="2024-03-04 07:30:00 -0800" value="2880"/>
+ <Record type="HKQuantityTypeIdentifierDietaryWater" sourceName="WaterMinder" unit="mL" startDate="2024-03-01 10:00:00 -0800" value="500"/>
+ <Record type="HKQuantityTypeIdentifierDietaryWater" sourceName="WaterMinder" unit="fl_oz_us" startDate="2024-03-01 12:00:00 -0800" value="16"/>
+ <Record type="HKQuantityTypeIdentifierDietaryWater" sourceName="WaterMinder" unit="mL" startDate="2099-03-01 12:00:00 -0800" value="250"/>
+ <Record type="HKQuantityTypeIdentifierStepCount" sourceName="iPhone" unit="count" startDate="2024-03-01 12:00:00 -0800" value="1200"/>
+ <Workout workoutActivityType="HKWorkoutActivityTypeWalking" duration="30"/>
+</HealthData>
+`
+
+func TestImportAppleHealth(t *testing.T) {
+	pst := time.FixedZone("PST", -8*3600)
+	// The water at 10:00 on March 1st was logged before.
+	wa := &mockWaterRepo{
+		queryFn: func(_ context.Context, _ int64, _ domain.EventQuery) ([]domain.WaterEvent, error) {
+			return []domain.WaterEvent{{ID: 1, DeltaLiters: 0.5, CreatedAt: time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)}}, nil
+		},
+	}
+	var gotWeights []domain.WeightEntry
+	var gotWater []domain.WaterEvent
+	calls := 0
+	repo := &mockImportRepo{
+		importFn: func(_ context.Context, _ int64, weights []domain.WeightEntry, water []domain.WaterEvent) error {
+			calls++
+			gotWeights, gotWater = weights, water
+			return nil
+		},
+	}
+	svc := app.NewImportService(repo, &mockWeightRepo{}, wa)
+
+	res, err := svc.ImportAppleHealth(context.Background(), 1, strings.NewReader(appleExport), app.HealthImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 0 || res.Committed {
+		t.Fatal("expected a dry run to store nothing")
+	}
+
+	res, err = svc.ImportAppleHealth(context.Background(), 1, strings.NewReader(appleExport), app.HealthImportOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 || !res.Committed {
+		t.Fatalf("expected one batch, got %d calls", calls)
+	}
+	if res.Records != 8 || res.Weights != 3 || res.Water != 1 || res.Duplicates != 2 || res.Skipped != 2 {
+		t.Errorf("unexpected summary %+v", res)
+	}
+	if len(res.Errors) != 2 || res.Errors[0].Row != 15 || !strings.Contains(res.Errors[0].Error, `"oz"`) {
+		t.Errorf("expected the ounce record on line 15 and the future record to be reported, got %+v", res.Errors)
+	}
+	if res.From == nil || !res.From.Equal(time.Date(2024, 3, 1, 7, 30, 0, 0, pst)) || !res.To.Equal(time.Date(2024, 3, 3, 7, 30, 0, 0, pst)) {
+		t.Errorf("unexpected span %v..%v", res.From, res.To)
+	}
+
+	want := []domain.WeightEntry{
+		{Value: 180.4, Unit: "lb", CreatedAt: time.Date(2024, 3, 1, 7, 30, 0, 0, pst)},
+		{Value: 81.5, Unit: "kg", CreatedAt: time.Date(2024, 3, 2, 7, 30, 0, 0, pst)},
+		{Value: 175, Unit: "lb", CreatedAt: time.Date(2024, 3, 3, 7, 30, 0, 0, pst)},
+	}
+	for i, w := range want {
+		if g := gotWeights[i]; g.Value != w.Value || g.Unit != w.Unit || !g.CreatedAt.Equal(w.CreatedAt) {
+			t.Errorf("weight %d: expected %+v, got %+v", i, w, g)
+		}
+	}
+	if len(gotWater) != 1 || gotWater[0].DeltaLiters < 0.473 || gotWater[0].DeltaLiters > 0.474 {
+		t.Errorf("expected 16 US fl oz as liters, got %+v", gotWater)
+	}
+}
+
+func TestImportAppleHealth_Invalid(t *testing.T) {
+	svc := app.NewImportService(&mockImportRepo{}, &mockWeightRepo{}, &mockWaterRepo{})
+	for _, file := range []string{
+		"",
+		`<HealthData><Record type="HKQuantityTypeIdentifierBodyMass"`,
+		`<?xml version="1.0"?><Something/>`,
+	} {
+		if _, err := svc.ImportAppleHealth(context.Background(), 1, strings.NewReader(file), app.HealthImportOptions{}); !errors.Is(err, app.ErrInvalidImport) {
+			t.Errorf("expected an invalid import error for %q, got %v", file, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Google Fit data types imported by ImportGoogleHealth. Fit stores weights in
+// kilograms and hydration in liters.
+const (
+	fitWeight    = "com.google.weight"
+	fitHydration = "com.google.hydration"
+)
+
+// googleExport holds the parts of a Google Fit or Health Connect JSON export
+// read by ImportGoogleHealth. A Google Takeout Fit file lists its data points
+// under "Data Points"; a Health Connect export lists records by type.
+type googleExport struct {
+	DataPoints      []fitDataPoint           `json:"Data Points"`
+	WeightRecord    []healthConnectWeight    `json:"WeightRecord"`
+	HydrationRecord []healthConnectHydration `json:"HydrationRecord"`
+}
+
+// fitDataPoint is a data point of a Google Takeout Fit data file.
+type fitDataPoint struct {
+	DataTypeName   string `json:"dataTypeName"`
+	StartTimeNanos int64  `json:"startTimeNanos"`
+	FitValue       []struct {
+		Value struct {
+			FpVal *float64 `json:"fpVal"`
+		} `json:"value"`
+	} `json:"fitValue"`
+}
+
+// healthConnectWeight is a Health Connect WeightRecord, with its mass in one
+// of the units of the Mass class.
+type healthConnectWeight struct {
+	Time   string `json:"time"`
+	Weight struct {
+		InKilograms *float64 `json:"inKilograms"`
+		InGrams     *float64 `json:"inGrams"`
+		InPounds    *float64 `json:"inPounds"`
+	} `json:"weight"`
+}
+
+// healthConnectHydration is a Health Connect HydrationRecord, with its volume
+// in one of the units of the Volume class.
+type healthConnectHydration struct {
+	StartTime string `json:"startTime"`
+	Volume    struct {
+		InLiters        *float64 `json:"inLiters"`
+		InMilliliters   *float64 `json:"inMilliliters"`
+		InFluidOuncesUs *float64 `json:"inFluidOuncesUs"`
+	} `json:"volume"`
+}
+
+// ImportGoogleHealth reads the weight and hydration data of a Google Fit or
+// Health Connect JSON export from r and stores those not yet in the user's
+// history in a single batch. It accepts a data file from the Fit folder of a
+// Google Takeout archive, such as one for com.google.weight or
+// com.google.hydration, or a Health Connect export with WeightRecord and
+// HydrationRecord lists. Each event is placed at its original start time.
+// Data points that cannot be converted or fail validation are skipped, with
+// errors giving their 1-based position in their list. Errors for an invalid
+// file match ErrInvalidImport.
+func (s *ImportService) ImportGoogleHealth(ctx context.Context, userID int64, r io.Reader, opts HealthImportOptions) (*HealthImportResult, error) {
+	var export googleExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, invalidImport(fmt.Errorf("invalid JSON export: %w", err))
+	}
+	if export.DataPoints == nil && export.WeightRecord == nil && export.HydrationRecord == nil {
+		return nil, invalidImport(fmt.Errorf("not a Google Fit or Health Connect export: no \"Data Points\", WeightRecord or HydrationRecord list"))
+	}
+
+	h, err := s.newHealthImport(ctx, userID, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range export.DataPoints {
+		addFitDataPoint(h, i+1, p)
+	}
+	for i, rec := range export.WeightRecord {
+		addHealthConnectWeight(h, i+1, rec)
+	}
+	for i, rec := range export.HydrationRecord {
+		addHealthConnectHydration(h, i+1, rec)
+	}
+	return s.commitHealthImport(ctx, userID, h)
+}
+
+// addFitDataPoint adds the weight or hydration of a Fit data point, ignoring
+// other data types.
+func addFitDataPoint(h *healthImport, pos int, p fitDataPoint) {
+	if p.DataTypeName != fitWeight && p.DataTypeName != fitHydration {
+		return
+	}
+	if len(p.FitValue) == 0 || p.FitValue[0].Value.FpVal == nil {
+		h.skipRecord(pos, fmt.Errorf("data point has no value"))
+		return
+	}
+	v := *p.FitValue[0].Value.FpVal
+	var at time.Time
+	if p.StartTimeNanos > 0 {
+		at = time.Unix(0, p.StartTimeNanos).UTC()
+	}
+	if p.DataTypeName == fitHydration {
+		h.addWater(pos, v, at)
+		return
+	}
+	h.addWeight(pos, v, "kg", at)
+}
+
+// addHealthConnectWeight adds a Health Connect weight record, in kilograms
+// unless it only gives the mass in pounds.
+func addHealthConnectWeight(h *healthImport, pos int, rec healthConnectWeight) {
+	at, err := parseHealthConnectTime(rec.Time)
+	if err != nil {
+		h.skipRecord(pos, fmt.Errorf("WeightRecord: %w", err))
+		return
+	}
+	m := rec.Weight
+	switch {
+	case m.InKilograms != nil:
+		h.addWeight(pos, *m.InKilograms, "kg", at)
+	case m.InGrams != nil:
+		h.addWeight(pos, *m.InGrams/1000, "kg", at)
+	case m.InPounds != nil:
+		h.addWeight(pos, *m.InPounds, "lb", at)
+	default:
+		h.skipRecord(pos, fmt.Errorf("WeightRecord: weight has no supported unit"))
+	}
+}
+
+// addHealthConnectHydration adds a Health Connect hydration record.
+func addHealthConnectHydration(h *healthImport, pos int, rec healthConnectHydration) {
+	at, err := parseHealthConnectTime(rec.StartTime)
+	if err != nil {
+		h.skipRecord(pos, fmt.Errorf("HydrationRecord: %w", err))
+		return
+	}
+	v := rec.Volume
+	switch {
+	case v.InLiters != nil:
+		h.addWater(pos, *v.InLiters, at)
+	case v.InMilliliters != nil:
+		h.addWater(pos, *v.InMilliliters/1000, at)
+	case v.InFluidOuncesUs != nil:
+		h.addWater(pos, *v.InFluidOuncesUs*appleWaterLiters["fl_oz_us"], at)
+	default:
+		h.skipRecord(pos, fmt.Errorf("HydrationRecord: volume has no supported unit"))
+	}
+}
+
+// parseHealthConnectTime parses an instant of a Health Connect record, which
+// is given in RFC 3339 format.
+func parseHealthConnectTime(s string) (time.Time, error) {
+	at, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", s)
+	}
+	return at, nil
+}
@@ -0,0 +1,130 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+const fitExport = `{
+  "Data Source": "raw:com.google.weight:com.google.android.apps.fitness:user_input",
+  "Data Points": [
+    {"fitValue": [{"value": {"fpVal": 80.5}}], "originDataSourceId": "", "endTimeNanos": 1709278200000000000, "dataTypeName": "com.google.weight", "startTimeNanos": 1709278200000000000, "modifiedTimeMillis": 1709278201000, "rawTimestampNanos": 0},
+    {"fitValue": [{"value": {"fpVal": 80.1}}], "dataTypeName": "com.google.weight", "startTimeNanos": 1709364600000000000},
+    {"fitValue": [{"value": {"fpVal": 0.25}}], "dataTypeName": "com.google.hydration", "startTimeNanos": 1709290800000000000},
+    {"fitValue": [{"value": {"intVal": 1200}}], "dataTypeName": "com.google.step_count.delta", "startTimeNanos": 1709290800000000000},
+    {"fitValue": [], "dataTypeName": "com.google.hydration", "startTimeNanos": 1709290800000000000}
+  ]
+}`
+
+const healthConnectExport = `{
+  "WeightRecord": [
+    {"time": "2024-03-02T07:30:00+01:00", "weight": {"inKilograms": 80.1}},
+    {"time": "yesterday", "weight": {"inKilograms": 80.1}},
+    {"time": "2024-03-03T07:30:00Z", "weight": {"inPounds": 176.2}},
+    {"time": "2024-03-04T07:30:00Z", "weight": {}}
+  ],
+  "HydrationRecord": [
+    {"startTime": "2024-03-02T09:00:00Z", "endTime": "2024-03-02T09:00:00Z", "volume": {"inMilliliters": 330}},
+    {"startTime": "2024-03-02T12:00:00Z", "volume": {"inFluidOuncesUs": 16}}
+  ]
+}`
+
+func TestImportGoogleHealth_Fit(t *testing.T) {
+	// The weigh-in at 07:30 UTC on March 1st was logged before.
+	wr := &mockWeightRepo{
+		queryFn: func(_ context.Context, _ int64, _ domain.EventQuery, _ *time.Location) ([]domain.WeightEntry, error) {
+			return []domain.WeightEntry{{ID: 1, Value: 80.5, Unit: "kg", CreatedAt: time.Date(2024, 3, 1, 7, 30, 0, 0, time.UTC)}}, nil
+		},
+	}
+	var gotWeights []domain.WeightEntry
+	var gotWater []domain.WaterEvent
+	repo := &mockImportRepo{
+		importFn: func(_ context.Context, _ int64, weights []domain.WeightEntry, water []domain.WaterEvent) error {
+			gotWeights, gotWater = weights, water
+			return nil
+		},
+	}
+	svc := app.NewImportService(repo, wr, &mockWaterRepo{})
+
+	res, err := svc.ImportGoogleHealth(context.Background(), 1, strings.NewReader(fitExport), app.HealthImportOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Committed || res.Records != 4 || res.Weights != 1 || res.Water != 1 || res.Duplicates != 1 || res.Skipped != 1 {
+		t.Errorf("unexpected summary %+v", res)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Row != 5 {
+		t.Errorf("expected the data point without a value to be reported, got %+v", res.Errors)
+	}
+	if len(gotWeights) != 1 || gotWeights[0].Value != 80.1 || gotWeights[0].Unit != "kg" ||
+		!gotWeights[0].CreatedAt.Equal(time.Date(2024, 3, 2, 7, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected weights %+v", gotWeights)
+	}
+	if len(gotWater) != 1 || gotWater[0].DeltaLiters != 0.25 ||
+		!gotWater[0].CreatedAt.Equal(time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected water %+v", gotWater)
+	}
+}
+
+func TestImportGoogleHealth_HealthConnect(t *testing.T) {
+	calls := 0
+	var gotWeights []domain.WeightEntry
+	var gotWater []domain.WaterEvent
+	repo := &mockImportRepo{
+		importFn: func(_ context.Context, _ int64, weights []domain.WeightEntry, water []domain.WaterEvent) error {
+			calls++
+			gotWeights, gotWater = weights, water
+			return nil
+		},
+	}
+	svc := app.NewImportService(repo, &mockWeightRepo{}, &mockWaterRepo{})
+
+	res, err := svc.ImportGoogleHealth(context.Background(), 1, strings.NewReader(healthConnectExport), app.HealthImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 0 || res.Committed || !res.DryRun {
+		t.Fatal("expected a dry run to store nothing")
+	}
+	if res.Records != 6 || res.Weights != 2 || res.Water != 2 || res.Skipped != 2 {
+		t.Errorf("unexpected summary %+v", res)
+	}
+	if len(res.Errors) != 2 || res.Errors[0].Row != 2 || !strings.Contains(res.Errors[0].Error, `"yesterday"`) || res.Errors[1].Row != 4 {
+		t.Errorf("expected the records with a bad time and no unit to be reported, got %+v", res.Errors)
+	}
+
+	if _, err := svc.ImportGoogleHealth(context.Background(), 1, strings.NewReader(healthConnectExport), app.HealthImportOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 || len(gotWeights) != 2 || len(gotWater) != 2 {
+		t.Fatalf("expected one batch of 2 weights and 2 water events, got %d calls", calls)
+	}
+	if w := gotWeights[0]; w.Value != 80.1 || w.Unit != "kg" || !w.CreatedAt.Equal(time.Date(2024, 3, 2, 6, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected weight %+v", w)
+	}
+	if w := gotWeights[1]; w.Value != 176.2 || w.Unit != "lb" {
+		t.Errorf("expected a weight in pounds, got %+v", w)
+	}
+	if gotWater[0].DeltaLiters != 0.33 || gotWater[1].DeltaLiters < 0.473 || gotWater[1].DeltaLiters > 0.474 {
+		t.Errorf("expected water in liters, got %+v", gotWater)
+	}
+}
+
+func TestImportGoogleHealth_Invalid(t *testing.T) {
+	svc := app.NewImportService(&mockImportRepo{}, &mockWeightRepo{}, &mockWaterRepo{})
+	for _, file := range []string{
+		"",
+		`{"Data Points": [`,
+		`{"name": "something else"}`,
+	} {
+		if _, err := svc.ImportGoogleHealth(context.Background(), 1, strings.NewReader(file), app.HealthImportOptions{}); !errors.Is(err, app.ErrInvalidImport) {
+			t.Errorf("expected an invalid import error for %q, got %v", file, err)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// HealthImportOptions controls an import from a health app export.
+type HealthImportOptions struct {
+	// DryRun reads and checks the export without storing anything.
+	DryRun bool
+}
+
+// HealthImportResult summarizes an import from a health app export.
+type HealthImportResult struct {
+	DryRun bool `json:"dryRun"`
+	// Committed reports whether new events were stored.
+	Committed bool `json:"committed"`
+	// Records counts the weight and hydration records found.
+	Records int `json:"records"`
+	// Weights and Water count the new events, which are stored unless the
+	// import is a dry run.
+	Weights int `json:"weights"`
+	Water   int `json:"water"`
+	// Duplicates counts records matching an existing or earlier event.
+	Duplicates int `json:"duplicates"`
+	// Skipped counts records that could not be imported, the first of which
+	// are listed in Errors.
+	Skipped int              `json:"skipped"`
+	Errors  []ImportRowError `json:"errors"`
+	// From and To are the times of the earliest and latest new event.
+	From *time.Time `json:"from"`
+	To   *time.Time `json:"to"`
+}
+
+// healthImport collects the new events of an import from a health app,
+// skipping those already in the user's history.
+type healthImport struct {
+	res     *HealthImportResult
+	seen    eventSet
+	now     time.Time
+	weights []domain.WeightEntry
+	water   []domain.WaterEvent
+}
+
+// newHealthImport starts an import for the user.
+func (s *ImportService) newHealthImport(ctx context.Context, userID int64, opts HealthImportOptions) (*healthImport, error) {
+	weights, water, err := eventHistory(ctx, s.weightRepo, s.waterRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &healthImport{
+		res:  &HealthImportResult{DryRun: opts.DryRun, Errors: []ImportRowError{}},
+		seen: newEventSet(weights, water),
+		now:  time.Now(),
+	}, nil
+}
+
+// addWeight adds a weigh-in read at the given position of the export.
+func (h *healthImport) addWeight(pos int, value float64, unit string, at time.Time) {
+	h.res.Records++
+	if err := h.check(at); err != nil {
+		h.skip(pos, err)
+		return
+	}
+	if err := validateWeight(value, unit); err != nil {
+		h.skip(pos, err)
+		return
+	}
+	e := domain.WeightEntry{Value: value, Unit: unit, CreatedAt: at}
+	if !h.seen.addWeight(e) {
+		h.res.Duplicates++
+		return
+	}
+	h.weights = append(h.weights, e)
+	h.extend(at)
+}
+
+// addWater adds a water intake event read at the given position of the
+// export.
+func (h *healthImport) addWater(pos int, liters float64, at time.Time) {
+	h.res.Records++
+	if err := h.check(at); err != nil {
+		h.skip(pos, err)
+		return
+	}
+	if err := validateWaterDelta(liters); err != nil {
+		h.skip(pos, err)
+		return
+	}
+	e := domain.WaterEvent{DeltaLiters: liters, CreatedAt: at}
+	if !h.seen.addWater(e) {
+		h.res.Duplicates++
+		return
+	}
+	h.water = append(h.water, e)
+	h.extend(at)
+}
+
+// skip records a weight or hydration record that cannot be imported.
+func (h *healthImport) skip(pos int, err error) {
+	h.res.Skipped++
+	if len(h.res.Errors) < maxImportErrors {
+		h.res.Errors = append(h.res.Errors, ImportRowError{Row: pos, Error: err.Error()})
+	}
+}
+
+// skipRecord counts a record that cannot be read at all as skipped.
+func (h *healthImport) skipRecord(pos int, err error) {
+	h.res.Records++
+	h.skip(pos, err)
+}
+
+func (h *healthImport) check(at time.Time) error {
+	if at.IsZero() {
+		return fmt.Errorf("record has no time")
+	}
+	if at.After(h.now.Add(clockSkew)) {
+		return fmt.Errorf("entry cannot be in the future")
+	}
+	return nil
+}
+
+func (h *healthImport) extend(at time.Time) {
+	at = at.UTC()
+	if h.res.From == nil || at.Before(*h.res.From) {
+		h.res.From = &at
+	}
+	if h.res.To == nil || at.After(*h.res.To) {
+		h.res.To = &at
+	}
+}
+
+// commit stores the new events in a single batch unless the import is a dry
+// run, and returns the summary.
+func (s *ImportService) commitHealthImport(ctx context.Context, userID int64, h *healthImport) (*HealthImportResult, error) {
+	h.res.Weights, h.res.Water = len(h.weights), len(h.water)
+	if h.res.DryRun || len(h.weights)+len(h.water) == 0 {
+		return h.res, nil
+	}
+	if err := s.repo.ImportEvents(ctx, userID, h.weights, h.water); err != nil {
+		return nil, err
+	}
+	h.res.Committed = true
+	return h.res, nil
+}
@@ -0,0 +1,493 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+const (
+	// maxImportRows caps the data rows of a single import.
+	maxImportRows = 100000
+	// maxImportErrors caps the row errors listed in an ImportResult.
+	maxImportErrors = 100
+	// importPreviewRows is the number of rows shown in an ImportResult preview.
+	importPreviewRows = 20
+)
+
+// ErrInvalidImport indicates that an import was rejected as a whole because
+// the file or its options are invalid, as opposed to failing to read or
+// store the user's data. The errors matching it keep their own message.
+var ErrInvalidImport = errors.New("invalid import")
+
+// invalidImport returns err marked as matching ErrInvalidImport.
+func invalidImport(err error) error {
+	return classError{class: ErrInvalidImport, err: err}
+}
+
+// ImportService encapsulates bulk import use cases. Imports from health apps
+// read the user's history to skip events that are already present; CSV
+// imports read it to check that weigh-ins are plausible.
+type ImportService struct {
+	repo           domain.ImportRepository
+	weightRepo     domain.WeightRepository
+	waterRepo      domain.WaterRepository
+	outlierPercent float64
+}
+
+// NewImportService creates an ImportService backed by the given repositories.
+func NewImportService(repo domain.ImportRepository, wr domain.WeightRepository, wa domain.WaterRepository) *ImportService {
+	return &ImportService{repo: repo, weightRepo: wr, waterRepo: wa, outlierPercent: DefaultOutlierPercent}
+}
+
+// WithOutlierThreshold sets the deviation, in percent, beyond which an
+// imported weigh-in is implausible. Zero disables the check.
+func (s *ImportService) WithOutlierThreshold(percent float64) *ImportService {
+	s.outlierPercent = percent
+	return s
+}
+
+// CSVMapping names the header of each CSV column to import. Empty fields
+// fall back to a column named after the field ("date", "time", "weight" or
+// "value", "unit" and "water"), matched case-insensitively, which may be
+// absent; a named column must exist.
+type CSVMapping struct {
+	// Date holds the day, or a date and time if there is no Time column.
+	Date string
+	Time string
+	// Value holds the weight.
+	Value string
+	// Unit holds the weight unit.
+	Unit string
+	// Water holds a water intake delta in liters.
+	Water string
+}
+
+// CSVImportOptions controls how a CSV file is read and stored.
+type CSVImportOptions struct {
+	Mapping CSVMapping
+	// DefaultUnit is the weight unit of rows without one; empty requires
+	// every weight to have a unit.
+	DefaultUnit string
+	// DayFirst reads numeric dates such as 03/04/2024 as day, month, year
+	// rather than month, day, year.
+	DayFirst bool
+	// DryRun validates the rows without storing anything.
+	DryRun bool
+	// SkipInvalid stores the valid rows even if others have errors. Otherwise
+	// nothing is stored unless every row is valid.
+	SkipInvalid bool
+	// Confirm accepts weigh-ins that are implausible compared to the user's
+	// weigh-ins and the other rows, which are otherwise invalid.
+	Confirm bool
+}
+
+// ImportResult reports the outcome of an import.
+type ImportResult struct {
+	DryRun bool `json:"dryRun"`
+	// Committed reports whether the valid events were stored.
+	Committed bool `json:"committed"`
+	// Rows is the number of data rows read.
+	Rows int `json:"rows"`
+	// Weights and Water count the valid events of each kind.
+	Weights int `json:"weights"`
+	Water   int `json:"water"`
+	// Invalid is the number of rows with an error.
+	Invalid int `json:"invalid"`
+	// Errors lists the first row errors.
+	Errors []ImportRowError `json:"errors"`
+	// Preview lists the events parsed from the first valid rows.
+	Preview []ImportedRow `json:"preview"`
+}
+
+// ImportRowError describes why a row was rejected. Row is the line number of
+// the row in the file, counting the header as line 1.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportedRow is the data parsed from a valid row.
+type ImportedRow struct {
+	Row  int       `json:"row"`
+	Time time.Time `json:"time"`
+	// Day is the local day of Time.
+	Day         string   `json:"day"`
+	Weight      *float64 `json:"weight,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+	WaterLiters *float64 `json:"waterLiters,omitempty"`
+}
+
+// ImportCSV reads weigh-ins and water intake from the CSV in r, which must
+// start with a header row, and stores them for the user unless opts.DryRun is
+// set. Dates and times without a zone are interpreted in loc; rows without a
+// time are placed at local noon. Each row may hold a weight, a water delta or
+// both, validated as when they are recorded individually, except that rows
+// may be of any age. Unless opts.Confirm is set, a weight is implausible, as
+// when recorded individually, if it deviates from the plausible weigh-ins in
+// the window before it, stored or imported, by more than the outlier
+// threshold. The events are stored in a single batch. Errors for an invalid
+// file or options match ErrInvalidImport.
+func (s *ImportService) ImportCSV(ctx context.Context, userID int64, r io.Reader, opts CSVImportOptions, loc *time.Location) (*ImportResult, error) {
+	if opts.DefaultUnit != "" && opts.DefaultUnit != "kg" && opts.DefaultUnit != "lb" {
+		return nil, invalidImport(errors.New("defaultUnit must be \"kg\" or \"lb\""))
+	}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, invalidImport(errors.New("csv is empty"))
+	}
+	if err != nil {
+		return nil, invalidImport(fmt.Errorf("invalid csv: %w", err))
+	}
+	cols, err := opts.Mapping.columns(header)
+	if err != nil {
+		return nil, invalidImport(err)
+	}
+
+	res := &ImportResult{DryRun: opts.DryRun, Errors: []ImportRowError{}, Preview: []ImportedRow{}}
+	var rows []ImportedRow
+	now := time.Now()
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, invalidImport(fmt.Errorf("invalid csv: %w", err))
+		}
+		res.Rows++
+		if res.Rows > maxImportRows {
+			return nil, invalidImport(fmt.Errorf("csv must not exceed %d rows", maxImportRows))
+		}
+		line, _ := cr.FieldPos(0)
+
+		row, err := cols.parse(record, opts, now, loc)
+		if err != nil {
+			res.reject(line, err)
+			continue
+		}
+		row.Row = line
+		rows = append(rows, row)
+	}
+	if !opts.Confirm {
+		if rows, err = s.dropImplausible(ctx, userID, rows, res); err != nil {
+			return nil, err
+		}
+	}
+
+	var weights []domain.WeightEntry
+	var water []domain.WaterEvent
+	for _, row := range rows {
+		if row.Weight != nil {
+			weights = append(weights, domain.WeightEntry{Value: *row.Weight, Unit: row.Unit, CreatedAt: row.Time})
+		}
+		if row.WaterLiters != nil {
+			water = append(water, domain.WaterEvent{DeltaLiters: *row.WaterLiters, CreatedAt: row.Time})
+		}
+		if len(res.Preview) < importPreviewRows {
+			res.Preview = append(res.Preview, row)
+		}
+	}
+	res.Weights = len(weights)
+	res.Water = len(water)
+
+	if opts.DryRun || (res.Invalid > 0 && !opts.SkipInvalid) || len(weights)+len(water) == 0 {
+		return res, nil
+	}
+	if err := s.repo.ImportEvents(ctx, userID, weights, water); err != nil {
+		return nil, err
+	}
+	res.Committed = true
+	return res, nil
+}
+
+// reject counts an invalid row, keeping the errors of the first rows listed
+// in row order.
+func (res *ImportResult) reject(row int, err error) {
+	res.Invalid++
+	i, _ := slices.BinarySearchFunc(res.Errors, row, func(e ImportRowError, row int) int {
+		return cmp.Compare(e.Row, row)
+	})
+	if i >= maxImportErrors {
+		return
+	}
+	res.Errors = slices.Insert(res.Errors, i, ImportRowError{Row: row, Error: err.Error()})
+	if len(res.Errors) > maxImportErrors {
+		res.Errors = res.Errors[:maxImportErrors]
+	}
+}
+
+// dropImplausible rejects the rows whose weight deviates from the plausible
+// weigh-ins in the outlierWindow before it by more than the outlier threshold,
+// and returns the other rows. The reference of a row includes both the user's
+// stored weigh-ins and the weights of the other rows.
+func (s *ImportService) dropImplausible(ctx context.Context, userID int64, rows []ImportedRow, res *ImportResult) ([]ImportedRow, error) {
+	if s.outlierPercent <= 0 {
+		return rows, nil
+	}
+	// weighIn is a weigh-in with the index of its row, or -1 if it is stored.
+	type weighIn struct {
+		entry domain.WeightEntry
+		row   int
+	}
+	var imported []weighIn
+	for i, row := range rows {
+		if row.Weight != nil {
+			imported = append(imported, weighIn{domain.WeightEntry{Value: *row.Weight, Unit: row.Unit, CreatedAt: row.Time}, i})
+		}
+	}
+	if len(imported) == 0 {
+		return rows, nil
+	}
+
+	first, last := imported[0].entry.CreatedAt, imported[0].entry.CreatedAt
+	for _, w := range imported {
+		if at := w.entry.CreatedAt; at.Before(first) {
+			first = at
+		} else if at.After(last) {
+			last = at
+		}
+	}
+	q := domain.EventQuery{From: first.Add(-outlierWindow), To: last, ToInclusive: true, Limit: outlierScanPage}
+	stored, err := listAll(q, func(q domain.EventQuery) ([]domain.WeightEntry, error) {
+		return s.weightRepo.ListWeightEvents(ctx, userID, q, time.UTC)
+	}, func(e domain.WeightEntry) domain.EventCursor {
+		return domain.EventCursor{CreatedAt: e.CreatedAt, ID: e.ID}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Stored weigh-ins go first so that they precede rows at the same time.
+	all := make([]weighIn, 0, len(stored)+len(imported))
+	for _, e := range stored {
+		all = append(all, weighIn{e, -1})
+	}
+	all = append(all, imported...)
+	slices.SortStableFunc(all, func(a, b weighIn) int {
+		return a.entry.CreatedAt.Compare(b.entry.CreatedAt)
+	})
+
+	implausible := make(map[int]bool)
+	sweep := outlierSweep{percent: s.outlierPercent}
+	for _, w := range all {
+		if o, ok := sweep.check(w.entry); !ok && w.row >= 0 {
+			implausible[w.row] = true
+			res.reject(rows[w.row].Row, o.err())
+		}
+	}
+	if len(implausible) == 0 {
+		return rows, nil
+	}
+	kept := make([]ImportedRow, 0, len(rows)-len(implausible))
+	for i, row := range rows {
+		if !implausible[i] {
+			kept = append(kept, row)
+		}
+	}
+	return kept, nil
+}
+
+// csvColumns holds the index of each mapped column, or -1 if it is absent.
+type csvColumns struct {
+	date, time, value, unit, water int
+}
+
+// columns locates the mapped columns in header.
+func (m CSVMapping) columns(header []string) (csvColumns, error) {
+	find := func(name string, defaults ...string) (int, error) {
+		names := defaults
+		if name != "" {
+			names = []string{name}
+		}
+		for _, n := range names {
+			for i, h := range header {
+				if strings.EqualFold(strings.TrimSpace(h), n) {
+					return i, nil
+				}
+			}
+		}
+		if name != "" {
+			return -1, fmt.Errorf("column %q not found in header", name)
+		}
+		return -1, nil
+	}
+
+	var c csvColumns
+	var err error
+	if c.date, err = find(m.Date, "date"); err != nil {
+		return c, err
+	}
+	if c.time, err = find(m.Time, "time"); err != nil {
+		return c, err
+	}
+	if c.value, err = find(m.Value, "weight", "value"); err != nil {
+		return c, err
+	}
+	if c.unit, err = find(m.Unit, "unit"); err != nil {
+		return c, err
+	}
+	if c.water, err = find(m.Water, "water"); err != nil {
+		return c, err
+	}
+	if c.date < 0 {
+		return c, errors.New("a date column is required")
+	}
+	if c.value < 0 && c.water < 0 {
+		return c, errors.New("a weight or water column is required")
+	}
+	return c, nil
+}
+
+// parse validates a data row.
+func (c csvColumns) parse(record []string, opts CSVImportOptions, now time.Time, loc *time.Location) (ImportedRow, error) {
+	cell := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var row ImportedRow
+	at, err := parseImportTime(cell(c.date), cell(c.time), opts.DayFirst, loc)
+	if err != nil {
+		return row, err
+	}
+	if at.After(now.Add(clockSkew)) {
+		return row, errors.New("entry cannot be in the future")
+	}
+	row.Time = at
+	row.Day = at.In(loc).Format("2006-01-02")
+
+	if v := cell(c.value); v != "" {
+		value, err := parseImportNumber(v)
+		if err != nil {
+			return row, fmt.Errorf("invalid weight %q", v)
+		}
+		unit := normalizeWeightUnit(cell(c.unit))
+		if unit == "" {
+			unit = opts.DefaultUnit
+		}
+		if err := validateWeight(value, unit); err != nil {
+			return row, err
+		}
+		row.Weight, row.Unit = &value, unit
+	}
+	if v := cell(c.water); v != "" {
+		liters, err := parseImportNumber(v)
+		if err != nil {
+			return row, fmt.Errorf("invalid water %q", v)
+		}
+		if err := validateWaterDelta(liters); err != nil {
+			return row, err
+		}
+		row.WaterLiters = &liters
+	}
+	if row.Weight == nil && row.WaterLiters == nil {
+		return row, errors.New("row has neither a weight nor water")
+	}
+	return row, nil
+}
+
+// Layouts accepted by parseImportTime. Numeric day-month-year layouts are
+// tried in the order selected by CSVImportOptions.DayFirst.
+var (
+	importDateTimeLayouts = []string{
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+	}
+	importDateLayouts = []string{
+		"2006-01-02",
+		"2006/01/02",
+		"2006.01.02",
+		"Jan 2, 2006",
+		"January 2, 2006",
+		"2 Jan 2006",
+		"2 January 2006",
+		"02-Jan-2006",
+	}
+	importMonthFirstLayouts = []string{"1/2/2006", "1-2-2006", "1.2.2006"}
+	importDayFirstLayouts   = []string{"2/1/2006", "2-1-2006", "2.1.2006"}
+	importTimeLayouts       = []string{"15:04", "15:04:05", "3:04 PM", "3:04PM", "3:04:05 PM", "3:04:05PM"}
+)
+
+// parseImportTime combines a date cell and an optional time cell into an
+// instant in loc. Without a time cell the date may carry a time itself,
+// optionally as an RFC 3339 timestamp; a bare date is placed at local noon.
+func parseImportTime(date, clock string, dayFirst bool, loc *time.Location) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, errors.New("date is empty")
+	}
+	if clock == "" {
+		if t, err := time.Parse(time.RFC3339, date); err == nil {
+			return t, nil
+		}
+		for _, layout := range importDateTimeLayouts {
+			if t, err := time.ParseInLocation(layout, date, loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+
+	layouts := append([]string{}, importDateLayouts...)
+	if dayFirst {
+		layouts = append(layouts, importDayFirstLayouts...)
+	} else {
+		layouts = append(layouts, importMonthFirstLayouts...)
+	}
+	var day time.Time
+	var err error
+	for _, layout := range layouts {
+		if day, err = time.ParseInLocation(layout, date, loc); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognized date %q", date)
+	}
+	if clock == "" {
+		return time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, loc), nil
+	}
+
+	for _, layout := range importTimeLayouts {
+		if t, err := time.Parse(layout, strings.ToUpper(clock)); err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", clock)
+}
+
+// parseImportNumber parses a decimal number, accepting a decimal comma.
+func parseImportNumber(s string) (float64, error) {
+	if !strings.Contains(s, ".") {
+		s = strings.Replace(s, ",", ".", 1)
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// normalizeWeightUnit maps common spellings of a weight unit to "kg" or
+// "lb", returning any other value unchanged so validation can reject it.
+func normalizeWeightUnit(unit string) string {
+	switch u := strings.ToLower(unit); u {
+	case "kg", "kgs", "kilogram", "kilograms":
+		return "kg"
+	case "lb", "lbs", "pound", "pounds":
+		return "lb"
+	default:
+		return u
+	}
+}
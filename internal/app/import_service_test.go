@@ -0,0 +1,245 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+type mockImportRepo struct {
+	importFn  func(ctx context.Context, userID int64, weights []domain.WeightEntry, water []domain.WaterEvent) error
+	restoreFn func(ctx context.Context, userID int64, weights []domain.WeightEntry, water []domain.WaterEvent, p domain.ProfileUpdate) (bool, error)
+}
+
+func (m *mockImportRepo) ImportEvents(ctx context.Context, userID int64, weights []domain.WeightEntry, water []domain.WaterEvent) error {
+	if m.importFn != nil {
+		return m.importFn(ctx, userID, weights, water)
+	}
+	return nil
+}
+
+func (m *mockImportRepo) RestoreAccount(ctx context.Context, userID int64, weights []domain.WeightEntry, water []domain.WaterEvent, p domain.ProfileUpdate) (bool, error) {
+	if m.restoreFn != nil {
+		return m.restoreFn(ctx, userID, weights, water, p)
+	}
+	return true, nil
+}
+
+func TestImportCSV(t *testing.T) {
+	const file = `Datum,Uhrzeit,Gewicht,Einheit,Wasser
+2024-03-01,07:30,80.5,kg,
+03.02.2024,7:45 pm,"177,5",lbs,0.5
+"Mar 10, 2024",,81,,
+2024-03-04T06:00:00Z,,,,-0.25
+`
+	var gotWeights []domain.WeightEntry
+	var gotWater []domain.WaterEvent
+	calls := 0
+	repo := &mockImportRepo{
+		importFn: func(_ context.Context, _ int64, weights []domain.WeightEntry, water []domain.WaterEvent) error {
+			calls++
+			gotWeights, gotWater = weights, water
+			return nil
+		},
+	}
+
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	opts := app.CSVImportOptions{
+		Mapping:     app.CSVMapping{Date: "Datum", Time: "Uhrzeit", Value: "Gewicht", Unit: "Einheit", Water: "Wasser"},
+		DefaultUnit: "kg",
+		DayFirst:    true,
+		DryRun:      true,
+	}
+	svc := app.NewImportService(repo, &mockWeightRepo{}, &mockWaterRepo{})
+	res, err := svc.ImportCSV(context.Background(), 1, strings.NewReader(file), opts, ny)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 0 || res.Committed {
+		t.Fatal("expected a dry run to store nothing")
+	}
+	if res.Rows != 4 || res.Weights != 3 || res.Water != 2 || res.Invalid != 0 || len(res.Preview) != 4 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	opts.DryRun = false
+	if res, err = svc.ImportCSV(context.Background(), 1, strings.NewReader(file), opts, ny); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 || !res.Committed {
+		t.Fatalf("expected a single batch to be stored, got %d calls", calls)
+	}
+	wantWeights := []domain.WeightEntry{
+		{Value: 80.5, Unit: "kg", CreatedAt: time.Date(2024, 3, 1, 7, 30, 0, 0, ny)},
+		{Value: 177.5, Unit: "lb", CreatedAt: time.Date(2024, 2, 3, 19, 45, 0, 0, ny)},
+		// Rows without a time are placed at local noon, also on the day
+		// clocks spring forward.
+		{Value: 81, Unit: "kg", CreatedAt: time.Date(2024, 3, 10, 12, 0, 0, 0, ny)},
+	}
+	for i, want := range wantWeights {
+		got := gotWeights[i]
+		if got.Value != want.Value || got.Unit != want.Unit || !got.CreatedAt.Equal(want.CreatedAt) {
+			t.Errorf("weight %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+	if len(gotWater) != 2 || gotWater[1].DeltaLiters != -0.25 || !gotWater[1].CreatedAt.Equal(time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected water events %+v", gotWater)
+	}
+	if res.Preview[3].Row != 5 || res.Preview[3].Day != "2024-03-04" {
+		t.Errorf("unexpected preview row %+v", res.Preview[3])
+	}
+}
+
+func TestImportCSV_InvalidRows(t *testing.T) {
+	future := time.Now().AddDate(0, 0, 2).Format("2006-01-02")
+	file := "date,weight,unit\n" +
+		"2024-03-01,80,kg\n" +
+		"2024-03-02,80,stone\n" +
+		"someday,80,kg\n" +
+		future + ",80,kg\n" +
+		"2024-03-03,,\n" +
+		"2024-03-04,-1,kg\n"
+
+	calls := 0
+	var stored int
+	repo := &mockImportRepo{
+		importFn: func(_ context.Context, _ int64, weights []domain.WeightEntry, _ []domain.WaterEvent) error {
+			calls++
+			stored = len(weights)
+			return nil
+		},
+	}
+	svc := app.NewImportService(repo, &mockWeightRepo{}, &mockWaterRepo{})
+	res, err := svc.ImportCSV(context.Background(), 1, strings.NewReader(file), app.CSVImportOptions{}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 0 || res.Committed {
+		t.Error("expected nothing stored while rows are invalid")
+	}
+	if res.Invalid != 5 || res.Weights != 1 || len(res.Errors) != 5 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	for i, row := range []int{3, 4, 5, 6, 7} {
+		if res.Errors[i].Row != row {
+			t.Errorf("error %d: expected row %d, got %+v", i, row, res.Errors[i])
+		}
+	}
+
+	res, err = svc.ImportCSV(context.Background(), 1, strings.NewReader(file), app.CSVImportOptions{SkipInvalid: true}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 || stored != 1 || !res.Committed {
+		t.Errorf("expected the valid row to be stored, got %d calls storing %d", calls, stored)
+	}
+}
+
+func TestImportCSV_Implausible(t *testing.T) {
+	// The rows are out of order; 804 kg is a mistyped 80.4 kg.
+	const file = "date,weight,unit\n" +
+		"2024-03-04,79.9,kg\n" +
+		"2024-03-03,804,kg\n" +
+		"2024-03-02,80.4,kg\n"
+	wr := &mockWeightRepo{
+		queryFn: func(_ context.Context, _ int64, q domain.EventQuery, _ *time.Location) ([]domain.WeightEntry, error) {
+			if want := time.Date(2024, 2, 17, 12, 0, 0, 0, time.UTC); !q.From.Equal(want) {
+				t.Errorf("expected the history from %v, got %v", want, q.From)
+			}
+			return []domain.WeightEntry{{ID: 1, Value: 80, Unit: "kg", CreatedAt: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)}}, nil
+		},
+	}
+	var stored []domain.WeightEntry
+	repo := &mockImportRepo{
+		importFn: func(_ context.Context, _ int64, weights []domain.WeightEntry, _ []domain.WaterEvent) error {
+			stored = weights
+			return nil
+		},
+	}
+	svc := app.NewImportService(repo, wr, &mockWaterRepo{})
+
+	res, err := svc.ImportCSV(context.Background(), 1, strings.NewReader(file), app.CSVImportOptions{}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Committed || res.Invalid != 1 || res.Weights != 2 || len(res.Errors) != 1 || res.Errors[0].Row != 3 ||
+		!strings.Contains(res.Errors[0].Error, app.ErrImplausibleWeight.Error()) {
+		t.Fatalf("expected the implausible row to be rejected, got %+v", res)
+	}
+
+	res, err = svc.ImportCSV(context.Background(), 1, strings.NewReader(file), app.CSVImportOptions{SkipInvalid: true}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Committed || len(stored) != 2 || stored[0].Value != 79.9 || stored[1].Value != 80.4 {
+		t.Errorf("expected the plausible rows stored, got %+v", stored)
+	}
+
+	res, err = svc.ImportCSV(context.Background(), 1, strings.NewReader(file), app.CSVImportOptions{Confirm: true}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.Committed || res.Invalid != 0 || len(stored) != 3 {
+		t.Errorf("expected a confirmed import to store every row, got %+v", res)
+	}
+
+	res, err = app.NewImportService(repo, wr, &mockWaterRepo{}).WithOutlierThreshold(0).
+		ImportCSV(context.Background(), 1, strings.NewReader(file), app.CSVImportOptions{}, time.UTC)
+	if err != nil || res.Invalid != 0 {
+		t.Errorf("expected no check without a threshold, got %+v, %v", res, err)
+	}
+}
+
+func TestImportCSV_RepositoryError(t *testing.T) {
+	const file = "date,weight,unit\n2024-03-02,80.4,kg\n"
+	failing := errors.New("connection refused")
+	for _, tc := range []struct {
+		name string
+		wr   *mockWeightRepo
+		repo *mockImportRepo
+	}{
+		{"history", &mockWeightRepo{
+			queryFn: func(context.Context, int64, domain.EventQuery, *time.Location) ([]domain.WeightEntry, error) {
+				return nil, failing
+			},
+		}, &mockImportRepo{}},
+		{"import", &mockWeightRepo{}, &mockImportRepo{
+			importFn: func(context.Context, int64, []domain.WeightEntry, []domain.WaterEvent) error {
+				return failing
+			},
+		}},
+	} {
+		_, err := app.NewImportService(tc.repo, tc.wr, &mockWaterRepo{}).
+			ImportCSV(context.Background(), 1, strings.NewReader(file), app.CSVImportOptions{}, time.UTC)
+		if !errors.Is(err, failing) || errors.Is(err, app.ErrInvalidImport) {
+			t.Errorf("%s: expected the repository error, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestImportCSV_Header(t *testing.T) {
+	svc := app.NewImportService(&mockImportRepo{}, &mockWeightRepo{}, &mockWaterRepo{})
+	for _, tc := range []struct {
+		name string
+		file string
+		opts app.CSVImportOptions
+	}{
+		{"empty", "", app.CSVImportOptions{}},
+		{"no date", "day,weight\n2024-03-01,80\n", app.CSVImportOptions{}},
+		{"no values", "date,notes\n2024-03-01,hi\n", app.CSVImportOptions{}},
+		{"missing mapped column", "date,weight\n", app.CSVImportOptions{Mapping: app.CSVMapping{Water: "Wasser"}}},
+		{"bad default unit", "date,weight\n", app.CSVImportOptions{DefaultUnit: "st"}},
+	} {
+		if _, err := svc.ImportCSV(context.Background(), 1, strings.NewReader(tc.file), tc.opts, time.UTC); !errors.Is(err, app.ErrInvalidImport) {
+			t.Errorf("%s: expected an invalid import error, got %v", tc.name, err)
+		}
+	}
+}
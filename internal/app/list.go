@@ -0,0 +1,164 @@
+package app
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// maxListLimit caps the page size of entry listings.
+const maxListLimit = 500
+
+// ErrInvalidListOptions indicates list options that do not form a query,
+// such as a malformed bound or cursor. The errors matching it keep their own
+// message.
+var ErrInvalidListOptions = errors.New("invalid list options")
+
+// invalidList returns err marked as matching ErrInvalidListOptions.
+func invalidList(err error) error {
+	return classError{class: ErrInvalidListOptions, err: err}
+}
+
+// ListOptions filters and pages a newest-first entry listing.
+type ListOptions struct {
+	// From and To bound the listing, inclusive. Each is either a local day
+	// ("2006-01-02") or an RFC 3339 timestamp; empty means unbounded.
+	From string
+	To   string
+	// Cursor continues a previous listing; it is the NextCursor of that page.
+	Cursor string
+	// Limit is the page size, clamped to [1, 500].
+	Limit int
+}
+
+// query converts the options to a repository query interpreted in loc. The
+// limit is one larger than requested so callers can detect a further page.
+// Errors match ErrInvalidListOptions.
+func (o ListOptions) query(loc *time.Location) (domain.EventQuery, error) {
+	var q domain.EventQuery
+	var err error
+	if q.From, _, err = parseListBound(o.From, loc, false); err != nil {
+		return q, invalidList(fmt.Errorf("invalid from: %w", err))
+	}
+	if q.To, q.ToInclusive, err = parseListBound(o.To, loc, true); err != nil {
+		return q, invalidList(fmt.Errorf("invalid to: %w", err))
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && (q.To.Before(q.From) || !q.ToInclusive && q.To.Equal(q.From)) {
+		return q, invalidList(errors.New("from must be before to"))
+	}
+	if o.Cursor != "" {
+		c, err := decodeCursor(o.Cursor)
+		if err != nil {
+			return q, invalidList(err)
+		}
+		q.After = &c
+	}
+
+	limit := o.Limit
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	q.Limit = limit + 1
+	return q, nil
+}
+
+// parseListBound parses a list bound and reports whether it is a timestamp.
+// A day as an upper bound covers the whole day, so it resolves to the start
+// of the following day, an exclusive bound; timestamps as an upper bound are
+// inclusive.
+func parseListBound(s string, loc *time.Location, upper bool) (time.Time, bool, error) {
+	if s == "" {
+		return time.Time{}, false, nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		if upper {
+			return day.AddDate(0, 0, 1), false, nil
+		}
+		return day, false, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, false, errors.New("must be YYYY-MM-DD or an RFC 3339 timestamp")
+	}
+	return t, true, nil
+}
+
+// encodeCursor returns an opaque token for the position after c.
+func encodeCursor(c domain.EventCursor) string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a token produced by encodeCursor.
+func decodeCursor(s string) (domain.EventCursor, error) {
+	bad := errors.New("invalid cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return domain.EventCursor{}, bad
+	}
+	ts, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return domain.EventCursor{}, bad
+	}
+	nanos, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return domain.EventCursor{}, bad
+	}
+	eventID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return domain.EventCursor{}, bad
+	}
+	return domain.EventCursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: eventID}, nil
+}
+
+// nextPage drops the look-ahead item fetched by ListOptions.query and
+// returns the page along with the cursor of the following page, or "" if
+// this is the last page.
+func nextPage[T any](items []T, limit int, pos func(T) domain.EventCursor) ([]T, string) {
+	if len(items) < limit {
+		return items, ""
+	}
+	items = items[:limit-1]
+	return items, encodeCursor(pos(items[len(items)-1]))
+}
+
+// listAll collects every event returned by list, which is called with
+// successive newest-first pages of q, starting from q itself.
+func listAll[T any](q domain.EventQuery, list func(domain.EventQuery) ([]T, error), pos func(T) domain.EventCursor) ([]T, error) {
+	var all []T
+	err := eachPage(q, list, pos, func(page []T) error {
+		all = append(all, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+// eachPage passes the pages that listAll would collect to fn one at a time,
+// stopping at the first error.
+func eachPage[T any](q domain.EventQuery, list func(domain.EventQuery) ([]T, error), pos func(T) domain.EventCursor, fn func([]T) error) error {
+	for {
+		page, err := list(q)
+		if err != nil {
+			return err
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		if len(page) < q.Limit {
+			return nil
+		}
+		c := pos(page[len(page)-1])
+		q.After = &c
+	}
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// Plausible range for a body measurement, in centimetres.
+const (
+	minMeasurementCm = 5
+	maxMeasurementCm = 300
+)
+
+// maxMeasurementsPerDay caps the measurements returned for a single day.
+const maxMeasurementsPerDay = 100
+
+// MeasurementService encapsulates body-measurement use cases.
+type MeasurementService struct {
+	repo domain.MeasurementRepository
+}
+
+// NewMeasurementService creates a MeasurementService backed by the given
+// repository.
+func NewMeasurementService(repo domain.MeasurementRepository) *MeasurementService {
+	return &MeasurementService{repo: repo}
+}
+
+// GetToday returns the measurements taken on the given local day in loc,
+// newest first.
+func (s *MeasurementService) GetToday(ctx context.Context, userID int64, today string, loc *time.Location) ([]domain.Measurement, error) {
+	start, err := time.ParseInLocation("2006-01-02", today, loc)
+	if err != nil {
+		return nil, err
+	}
+	q := domain.EventQuery{From: start, To: start.AddDate(0, 0, 1), Limit: maxMeasurementsPerDay}
+	return s.repo.ListMeasurements(ctx, userID, "", q, loc)
+}
+
+// RecordMeasurement validates and stores a new measurement of site at the time
+// described by at, returning the stored measurement with its ID and local day.
+func (s *MeasurementService) RecordMeasurement(ctx context.Context, userID int64, site string, value float64, unit string, at EntryTime, loc *time.Location) (*domain.Measurement, error) {
+	if err := validateMeasurement(site, value, unit); err != nil {
+		return nil, err
+	}
+	createdAt, err := at.resolve(time.Now(), loc)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.repo.AddMeasurement(ctx, userID, site, value, unit, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Measurement{
+		ID:        id,
+		UserID:    userID,
+		Day:       createdAt.In(loc).Format("2006-01-02"),
+		Site:      site,
+		Value:     value,
+		Unit:      unit,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// ListRecent returns a newest-first page of measurements selected by opts,
+// restricted to site unless it is empty, with days computed in loc, and the
+// cursor of the next page if any. An unknown site or invalid options give an
+// error matching ErrInvalidListOptions.
+func (s *MeasurementService) ListRecent(ctx context.Context, userID int64, site string, opts ListOptions, loc *time.Location) ([]domain.Measurement, string, error) {
+	if site != "" && !domain.IsMeasurementSite(site) {
+		return nil, "", invalidList(errSite)
+	}
+	q, err := opts.query(loc)
+	if err != nil {
+		return nil, "", err
+	}
+	items, err := s.repo.ListMeasurements(ctx, userID, site, q, loc)
+	if err != nil {
+		return nil, "", err
+	}
+	items, next := nextPage(items, q.Limit, func(m domain.Measurement) domain.EventCursor {
+		return domain.EventCursor{CreatedAt: m.CreatedAt, ID: m.ID}
+	})
+	return items, next, nil
+}
+
+// UndoLast deletes the most recent measurement.
+func (s *MeasurementService) UndoLast(ctx context.Context, userID int64) (bool, int64, error) {
+	items, err := s.repo.ListMeasurements(ctx, userID, "", domain.EventQuery{Limit: 1}, time.UTC)
+	if err != nil {
+		return false, 0, err
+	}
+	if len(items) == 0 {
+		return false, 0, nil
+	}
+	if _, err := s.repo.DeleteMeasurement(ctx, userID, items[0].ID); err != nil {
+		return false, 0, err
+	}
+	return true, items[0].ID, nil
+}
+
+// MeasurementPatch holds the fields to change on an existing measurement. Nil
+// fields are left unchanged.
+type MeasurementPatch struct {
+	Site  *string
+	Value *float64
+	Unit  *string
+	At    *time.Time
+}
+
+// GetMeasurement returns the user's measurement with the given ID.
+func (s *MeasurementService) GetMeasurement(ctx context.Context, userID, id int64, loc *time.Location) (*domain.Measurement, error) {
+	m, err := s.repo.GetMeasurement(ctx, userID, id, loc)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrEntryNotFound
+	}
+	return m, nil
+}
+
+// UpdateMeasurement applies patch to the user's measurement with the given ID
+// and returns the updated measurement.
+func (s *MeasurementService) UpdateMeasurement(ctx context.Context, userID, id int64, patch MeasurementPatch, loc *time.Location) (*domain.Measurement, error) {
+	m, err := s.GetMeasurement(ctx, userID, id, loc)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Site != nil {
+		m.Site = *patch.Site
+	}
+	if patch.Value != nil {
+		m.Value = *patch.Value
+	}
+	if patch.Unit != nil {
+		m.Unit = *patch.Unit
+	}
+	if err := validateMeasurement(m.Site, m.Value, m.Unit); err != nil {
+		return nil, err
+	}
+	if patch.At != nil {
+		if m.CreatedAt, err = (EntryTime{At: patch.At}).resolve(time.Now(), loc); err != nil {
+			return nil, err
+		}
+	}
+
+	found, err := s.repo.UpdateMeasurement(ctx, userID, id, m.Site, m.Value, m.Unit, m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrEntryNotFound
+	}
+	m.Day = m.CreatedAt.In(loc).Format("2006-01-02")
+	return m, nil
+}
+
+// DeleteMeasurement removes the user's measurement with the given ID.
+func (s *MeasurementService) DeleteMeasurement(ctx context.Context, userID, id int64) error {
+	found, err := s.repo.DeleteMeasurement(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+var errSite = fmt.Errorf("site must be one of %s", strings.Join(domain.MeasurementSites, ", "))
+
+func validateMeasurement(site string, value float64, unit string) error {
+	if !domain.IsMeasurementSite(site) {
+		return errSite
+	}
+	if unit != "cm" && unit != "in" {
+		return errors.New("unit must be \"cm\" or \"in\"")
+	}
+	if cm := domain.ConvertLength(value, unit, "cm"); cm < minMeasurementCm || cm > maxMeasurementCm {
+		return fmt.Errorf("value must be between %d and %d cm", minMeasurementCm, maxMeasurementCm)
+	}
+	return nil
+}
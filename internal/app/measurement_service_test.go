@@ -0,0 +1,158 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+type mockMeasurementRepo struct {
+	addFn    func(ctx context.Context, userID int64, site string, value float64, unit string, createdAt time.Time) (int64, error)
+	getFn    func(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.Measurement, error)
+	updateFn func(ctx context.Context, userID int64, id int64, site string, value float64, unit string, createdAt time.Time) (bool, error)
+	delFn    func(ctx context.Context, userID int64, id int64) (bool, error)
+	listFn   func(ctx context.Context, userID int64, site string, q domain.EventQuery, loc *time.Location) ([]domain.Measurement, error)
+	latestFn func(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]map[string]domain.Measurement, error)
+}
+
+func (m *mockMeasurementRepo) AddMeasurement(ctx context.Context, userID int64, site string, value float64, unit string, createdAt time.Time) (int64, error) {
+	if m.addFn != nil {
+		return m.addFn(ctx, userID, site, value, unit, createdAt)
+	}
+	return 1, nil
+}
+
+func (m *mockMeasurementRepo) GetMeasurement(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.Measurement, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID, id, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockMeasurementRepo) UpdateMeasurement(ctx context.Context, userID int64, id int64, site string, value float64, unit string, createdAt time.Time) (bool, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, userID, id, site, value, unit, createdAt)
+	}
+	return true, nil
+}
+
+func (m *mockMeasurementRepo) DeleteMeasurement(ctx context.Context, userID int64, id int64) (bool, error) {
+	if m.delFn != nil {
+		return m.delFn(ctx, userID, id)
+	}
+	return true, nil
+}
+
+func (m *mockMeasurementRepo) ListMeasurements(ctx context.Context, userID int64, site string, q domain.EventQuery, loc *time.Location) ([]domain.Measurement, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, site, q, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockMeasurementRepo) LatestMeasurementsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]map[string]domain.Measurement, error) {
+	if m.latestFn != nil {
+		return m.latestFn(ctx, userID, fromDay, toDay, loc)
+	}
+	return nil, nil
+}
+
+func TestRecordMeasurement_Validation(t *testing.T) {
+	svc := app.NewMeasurementService(&mockMeasurementRepo{})
+	tests := []struct {
+		name  string
+		site  string
+		value float64
+		unit  string
+	}{
+		{"unknown site", "ankle", 25, "cm"},
+		{"bad unit", "waist", 80, "mm"},
+		{"too small", "neck", 1, "in"},
+		{"too large", "waist", 150, "in"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.RecordMeasurement(context.Background(), 1, tc.site, tc.value, tc.unit, app.EntryTime{}, time.UTC); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestRecordMeasurement_Success(t *testing.T) {
+	var storedSite string
+	repo := &mockMeasurementRepo{
+		addFn: func(_ context.Context, _ int64, site string, _ float64, _ string, _ time.Time) (int64, error) {
+			storedSite = site
+			return 3, nil
+		},
+	}
+	svc := app.NewMeasurementService(repo)
+	got, err := svc.RecordMeasurement(context.Background(), 1, "waist", 33.5, "in", app.EntryTime{}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ID != 3 || got.Day == "" || got.Unit != "in" || storedSite != "waist" {
+		t.Errorf("unexpected measurement: %+v (stored site %q)", got, storedSite)
+	}
+}
+
+func TestMeasurementListRecent_Site(t *testing.T) {
+	var gotSite string
+	repo := &mockMeasurementRepo{
+		listFn: func(_ context.Context, _ int64, site string, _ domain.EventQuery, _ *time.Location) ([]domain.Measurement, error) {
+			gotSite = site
+			return nil, nil
+		},
+	}
+	svc := app.NewMeasurementService(repo)
+	if _, _, err := svc.ListRecent(context.Background(), 1, "hips", app.ListOptions{Limit: 10}, time.UTC); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotSite != "hips" {
+		t.Errorf("expected site filter hips, got %q", gotSite)
+	}
+	if _, _, err := svc.ListRecent(context.Background(), 1, "ankle", app.ListOptions{Limit: 10}, time.UTC); err == nil {
+		t.Error("expected error for unknown site")
+	}
+}
+
+func TestUpdateMeasurement(t *testing.T) {
+	repo := &mockMeasurementRepo{
+		getFn: func(_ context.Context, _ int64, id int64, _ *time.Location) (*domain.Measurement, error) {
+			return &domain.Measurement{ID: id, Site: "chest", Value: 130, Unit: "cm", CreatedAt: time.Now()}, nil
+		},
+	}
+	svc := app.NewMeasurementService(repo)
+
+	value, unit := 40.0, "in"
+	got, err := svc.UpdateMeasurement(context.Background(), 1, 2, app.MeasurementPatch{Value: &value, Unit: &unit}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Site != "chest" || got.Value != 40 || got.Unit != "in" {
+		t.Errorf("unexpected measurement: %+v", got)
+	}
+
+	// 130 in is far beyond any plausible chest measurement.
+	if _, err := svc.UpdateMeasurement(context.Background(), 1, 2, app.MeasurementPatch{Unit: &unit}, time.UTC); err == nil {
+		t.Fatal("expected validation error after unit change")
+	}
+}
+
+func TestMeasurementByID_NotFound(t *testing.T) {
+	repo := &mockMeasurementRepo{
+		delFn: func(_ context.Context, _ int64, _ int64) (bool, error) { return false, nil },
+	}
+	svc := app.NewMeasurementService(repo)
+	if _, err := svc.GetMeasurement(context.Background(), 1, 5, time.UTC); !errors.Is(err, app.ErrEntryNotFound) {
+		t.Errorf("GetMeasurement: expected ErrEntryNotFound, got %v", err)
+	}
+	if err := svc.DeleteMeasurement(context.Background(), 1, 5); !errors.Is(err, app.ErrEntryNotFound) {
+		t.Errorf("DeleteMeasurement: expected ErrEntryNotFound, got %v", err)
+	}
+}
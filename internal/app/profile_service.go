@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// ProfileService manages per-user preferences.
+type ProfileService struct {
+	users domain.UserRepository
+}
+
+// NewProfileService creates a ProfileService backed by the given repository.
+func NewProfileService(users domain.UserRepository) *ProfileService {
+	return &ProfileService{users: users}
+}
+
+// ErrInvalidProfile indicates a preference that cannot be stored. The errors
+// matching it keep their own message.
+var ErrInvalidProfile = errors.New("invalid profile")
+
+// maxWaterGoalLiters bounds the daily water goal a user may set.
+const maxWaterGoalLiters = 10
+
+// ValidateProfile checks the preferences set in p: an IANA time zone, or empty
+// for the server's local zone; a daily water goal of at most
+// maxWaterGoalLiters, or zero for the default; and a weight policy, or empty
+// for the default. Errors match ErrInvalidProfile.
+func ValidateProfile(p domain.ProfileUpdate) error {
+	if p.Timezone != nil {
+		if err := validateTimezone(*p.Timezone); err != nil {
+			return classError{class: ErrInvalidProfile, err: err}
+		}
+	}
+	if p.WaterGoalLiters != nil {
+		if err := validateWaterGoal(*p.WaterGoalLiters); err != nil {
+			return classError{class: ErrInvalidProfile, err: err}
+		}
+	}
+	if p.WeightPolicy != nil {
+		if _, err := resolveWeightPolicy(*p.WeightPolicy); err != nil {
+			return classError{class: ErrInvalidProfile, err: err}
+		}
+	}
+	return nil
+}
+
+// UpdateProfile validates the preferences set in p, stores them in a single
+// update, so that either all or none of them change, and returns the updated
+// user. Invalid preferences give an error matching ErrInvalidProfile.
+func (s *ProfileService) UpdateProfile(ctx context.Context, userID int64, p domain.ProfileUpdate) (*domain.User, error) {
+	if err := ValidateProfile(p); err != nil {
+		return nil, err
+	}
+	user, err := s.users.UpdateProfile(ctx, userID, p)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func validateTimezone(timezone string) error {
+	if timezone == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", timezone)
+	}
+	return nil
+}
+
+func validateWaterGoal(liters float64) error {
+	if liters < 0 || liters > maxWaterGoalLiters {
+		return fmt.Errorf("waterGoalLiters must be between 0 and %d", maxWaterGoalLiters)
+	}
+	return nil
+}
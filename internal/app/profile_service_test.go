@@ -0,0 +1,141 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+// recordProfile returns a mockUserRepo that stores each update in *stored.
+func recordProfile(t *testing.T, stored **domain.ProfileUpdate) *mockUserRepo {
+	t.Helper()
+	return &mockUserRepo{
+		updateProfileFn: func(_ context.Context, id int64, p domain.ProfileUpdate) error {
+			if id != 7 {
+				t.Errorf("expected user 7, got %d", id)
+			}
+			*stored = &p
+			return nil
+		},
+	}
+}
+
+func TestProfileService_Timezone(t *testing.T) {
+	tests := []struct {
+		name    string
+		tz      string
+		wantErr bool
+	}{
+		{"valid zone", "Europe/Berlin", false},
+		{"reset to default", "", false},
+		{"unknown zone", "Nowhere/Special", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stored *domain.ProfileUpdate
+			svc := app.NewProfileService(recordProfile(t, &stored))
+			user, err := svc.UpdateProfile(context.Background(), 7, domain.ProfileUpdate{Timezone: &tc.tz})
+			if tc.wantErr {
+				if !errors.Is(err, app.ErrInvalidProfile) {
+					t.Fatalf("expected an invalid profile error, got %v", err)
+				}
+				if stored != nil {
+					t.Fatal("repository should not be called for invalid zone")
+				}
+				return
+			}
+			if err != nil || user == nil || user.ID != 7 {
+				t.Fatalf("expected the updated user, got %+v, %v", user, err)
+			}
+			if stored == nil || *stored.Timezone != tc.tz || stored.WaterGoalLiters != nil || stored.WeightPolicy != nil {
+				t.Fatalf("expected only %q stored, got %+v", tc.tz, stored)
+			}
+		})
+	}
+}
+
+func TestProfileService_WaterGoal(t *testing.T) {
+	tests := []struct {
+		name    string
+		liters  float64
+		wantErr bool
+	}{
+		{"valid goal", 3.2, false},
+		{"reset to default", 0, false},
+		{"negative", -1, true},
+		{"too large", 25, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stored *domain.ProfileUpdate
+			_, err := app.NewProfileService(recordProfile(t, &stored)).UpdateProfile(context.Background(), 7, domain.ProfileUpdate{WaterGoalLiters: &tc.liters})
+			if tc.wantErr {
+				if err == nil || stored != nil {
+					t.Fatalf("expected error without storing, got err=%v stored=%v", err, stored)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if stored == nil || *stored.WaterGoalLiters != tc.liters {
+				t.Fatalf("expected %v stored, got %+v", tc.liters, stored)
+			}
+		})
+	}
+}
+
+func TestProfileService_WeightPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy  domain.WeightPolicy
+		wantErr bool
+	}{
+		{domain.WeightPolicyMin, false},
+		{"", false},
+		{"median", true},
+	} {
+		var stored *domain.ProfileUpdate
+		_, err := app.NewProfileService(recordProfile(t, &stored)).UpdateProfile(context.Background(), 7, domain.ProfileUpdate{WeightPolicy: &tc.policy})
+		if tc.wantErr {
+			if err == nil || stored != nil {
+				t.Errorf("%q: expected error without storing, got err=%v stored=%v", tc.policy, err, stored)
+			}
+			continue
+		}
+		if err != nil || stored == nil || *stored.WeightPolicy != tc.policy {
+			t.Errorf("%q: expected it stored, got err=%v stored=%+v", tc.policy, err, stored)
+		}
+	}
+}
+
+func TestProfileService_UpdateIsAtomic(t *testing.T) {
+	tz, goal, policy := "Europe/Berlin", 2.5, domain.WeightPolicy("median")
+	var stored *domain.ProfileUpdate
+	svc := app.NewProfileService(recordProfile(t, &stored))
+
+	// An invalid last field leaves the valid ones before it unsaved.
+	_, err := svc.UpdateProfile(context.Background(), 7, domain.ProfileUpdate{Timezone: &tz, WaterGoalLiters: &goal, WeightPolicy: &policy})
+	if !errors.Is(err, app.ErrInvalidProfile) || stored != nil {
+		t.Fatalf("expected error without storing, got err=%v stored=%+v", err, stored)
+	}
+
+	policy = domain.WeightPolicyMean
+	if _, err := svc.UpdateProfile(context.Background(), 7, domain.ProfileUpdate{Timezone: &tz, WaterGoalLiters: &goal, WeightPolicy: &policy}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored == nil || *stored.Timezone != tz || *stored.WaterGoalLiters != goal || *stored.WeightPolicy != policy {
+		t.Fatalf("expected all fields in one update, got %+v", stored)
+	}
+
+	failing := &mockUserRepo{
+		updateProfileFn: func(context.Context, int64, domain.ProfileUpdate) error {
+			return errors.New("connection refused")
+		},
+	}
+	if _, err := app.NewProfileService(failing).UpdateProfile(context.Background(), 7, domain.ProfileUpdate{Timezone: &tz}); err == nil || errors.Is(err, app.ErrInvalidProfile) {
+		t.Fatalf("expected the repository error, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// maxSleepDuration bounds the length of a single sleep session.
+const maxSleepDuration = 24 * time.Hour
+
+// maxSessionsPerDay caps the sessions returned for a single day and the
+// neighbours examined when checking for overlaps.
+const maxSessionsPerDay = 50
+
+// ErrSleepOverlap indicates that a sleep session overlaps another session of
+// the same user.
+var ErrSleepOverlap = errors.New("sleep session overlaps an existing session")
+
+// SleepService encapsulates sleep-tracking use cases.
+type SleepService struct {
+	repo domain.SleepRepository
+}
+
+// NewSleepService creates a SleepService backed by the given repository.
+func NewSleepService(repo domain.SleepRepository) *SleepService {
+	return &SleepService{repo: repo}
+}
+
+// GetToday returns the sessions that ended on the given local day in loc,
+// newest first.
+func (s *SleepService) GetToday(ctx context.Context, userID int64, today string, loc *time.Location) ([]domain.SleepSession, error) {
+	start, err := time.ParseInLocation("2006-01-02", today, loc)
+	if err != nil {
+		return nil, err
+	}
+	q := domain.EventQuery{From: start, To: start.AddDate(0, 0, 1), Limit: maxSessionsPerDay}
+	sessions, err := s.repo.ListSleepSessions(ctx, userID, q, loc)
+	if err != nil {
+		return nil, err
+	}
+	return withDurations(sessions), nil
+}
+
+// RecordSession validates and stores a new sleep session, attributing it to
+// the local day in loc on which it ended.
+func (s *SleepService) RecordSession(ctx context.Context, userID int64, session domain.SleepSession, loc *time.Location) (*domain.SleepSession, error) {
+	if err := validateSleep(session, time.Now(), loc); err != nil {
+		return nil, err
+	}
+	if err := s.checkOverlap(ctx, userID, 0, session, loc); err != nil {
+		return nil, err
+	}
+	id, err := s.repo.AddSleepSession(ctx, userID, session)
+	if err != nil {
+		return nil, err
+	}
+	session.ID = id
+	session.UserID = userID
+	session.Day = session.WakeDay(loc)
+	session.DurationMinutes = session.Duration().Minutes()
+	return &session, nil
+}
+
+// ListRecent returns a newest-first page of sessions selected by opts, which
+// bound the wake time, and the cursor of the next page if any.
+func (s *SleepService) ListRecent(ctx context.Context, userID int64, opts ListOptions, loc *time.Location) ([]domain.SleepSession, string, error) {
+	q, err := opts.query(loc)
+	if err != nil {
+		return nil, "", err
+	}
+	sessions, err := s.repo.ListSleepSessions(ctx, userID, q, loc)
+	if err != nil {
+		return nil, "", err
+	}
+	sessions, next := nextPage(sessions, q.Limit, func(e domain.SleepSession) domain.EventCursor {
+		return domain.EventCursor{CreatedAt: e.WakeTime, ID: e.ID}
+	})
+	return withDurations(sessions), next, nil
+}
+
+// UndoLast deletes the session with the latest wake time.
+func (s *SleepService) UndoLast(ctx context.Context, userID int64) (bool, int64, error) {
+	sessions, err := s.repo.ListSleepSessions(ctx, userID, domain.EventQuery{Limit: 1}, time.UTC)
+	if err != nil {
+		return false, 0, err
+	}
+	if len(sessions) == 0 {
+		return false, 0, nil
+	}
+	if _, err := s.repo.DeleteSleepSession(ctx, userID, sessions[0].ID); err != nil {
+		return false, 0, err
+	}
+	return true, sessions[0].ID, nil
+}
+
+// SleepPatch holds the fields to change on an existing session. Nil fields
+// are left unchanged.
+type SleepPatch struct {
+	Bedtime       *time.Time
+	WakeTime      *time.Time
+	Quality       *int
+	Interruptions *int
+}
+
+// GetSession returns the user's sleep session with the given ID.
+func (s *SleepService) GetSession(ctx context.Context, userID, id int64, loc *time.Location) (*domain.SleepSession, error) {
+	session, err := s.repo.GetSleepSession(ctx, userID, id, loc)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrEntryNotFound
+	}
+	session.DurationMinutes = session.Duration().Minutes()
+	return session, nil
+}
+
+// UpdateSession applies patch to the user's sleep session with the given ID
+// and returns the updated session.
+func (s *SleepService) UpdateSession(ctx context.Context, userID, id int64, patch SleepPatch, loc *time.Location) (*domain.SleepSession, error) {
+	session, err := s.GetSession(ctx, userID, id, loc)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Bedtime != nil {
+		session.Bedtime = *patch.Bedtime
+	}
+	if patch.WakeTime != nil {
+		session.WakeTime = *patch.WakeTime
+	}
+	if patch.Quality != nil {
+		session.Quality = patch.Quality
+	}
+	if patch.Interruptions != nil {
+		session.Interruptions = *patch.Interruptions
+	}
+	if err := validateSleep(*session, time.Now(), loc); err != nil {
+		return nil, err
+	}
+	if err := s.checkOverlap(ctx, userID, id, *session, loc); err != nil {
+		return nil, err
+	}
+
+	found, err := s.repo.UpdateSleepSession(ctx, userID, id, *session)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrEntryNotFound
+	}
+	session.Day = session.WakeDay(loc)
+	session.DurationMinutes = session.Duration().Minutes()
+	return session, nil
+}
+
+// DeleteSession removes the user's sleep session with the given ID.
+func (s *SleepService) DeleteSession(ctx context.Context, userID, id int64) error {
+	found, err := s.repo.DeleteSleepSession(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// checkOverlap returns ErrSleepOverlap if session overlaps any of the user's
+// other sessions, ignoring the session with ID self.
+func (s *SleepService) checkOverlap(ctx context.Context, userID, self int64, session domain.SleepSession, loc *time.Location) error {
+	// Any overlapping session must wake after this bedtime and, being at most
+	// maxSleepDuration long, before this wake time plus that duration.
+	q := domain.EventQuery{
+		From:  session.Bedtime,
+		To:    session.WakeTime.Add(maxSleepDuration),
+		Limit: maxSessionsPerDay,
+	}
+	others, err := s.repo.ListSleepSessions(ctx, userID, q, loc)
+	if err != nil {
+		return err
+	}
+	for _, o := range others {
+		if o.ID != self && o.Bedtime.Before(session.WakeTime) && o.WakeTime.After(session.Bedtime) {
+			return ErrSleepOverlap
+		}
+	}
+	return nil
+}
+
+// withDurations sets the duration of each session in place.
+func withDurations(sessions []domain.SleepSession) []domain.SleepSession {
+	for i := range sessions {
+		sessions[i].DurationMinutes = sessions[i].Duration().Minutes()
+	}
+	return sessions
+}
+
+func validateSleep(s domain.SleepSession, now time.Time, loc *time.Location) error {
+	if s.Bedtime.IsZero() || s.WakeTime.IsZero() {
+		return errors.New("bedtime and wakeTime are required")
+	}
+	if !s.WakeTime.After(s.Bedtime) {
+		return errors.New("wakeTime must be after bedtime")
+	}
+	if s.Duration() > maxSleepDuration {
+		return errors.New("sleep session cannot be longer than 24 hours")
+	}
+	if _, err := (EntryTime{At: &s.WakeTime}).resolve(now, loc); err != nil {
+		return err
+	}
+	if s.Quality != nil && (*s.Quality < 1 || *s.Quality > 5) {
+		return errors.New("quality must be between 1 and 5")
+	}
+	if s.Interruptions < 0 {
+		return errors.New("interruptions must be >= 0")
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+type mockSleepRepo struct {
+	addFn     func(ctx context.Context, userID int64, s domain.SleepSession) (int64, error)
+	getFn     func(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.SleepSession, error)
+	updateFn  func(ctx context.Context, userID int64, id int64, s domain.SleepSession) (bool, error)
+	delFn     func(ctx context.Context, userID int64, id int64) (bool, error)
+	listFn    func(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.SleepSession, error)
+	minutesFn func(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error)
+}
+
+func (m *mockSleepRepo) AddSleepSession(ctx context.Context, userID int64, s domain.SleepSession) (int64, error) {
+	if m.addFn != nil {
+		return m.addFn(ctx, userID, s)
+	}
+	return 1, nil
+}
+
+func (m *mockSleepRepo) GetSleepSession(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.SleepSession, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID, id, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockSleepRepo) UpdateSleepSession(ctx context.Context, userID int64, id int64, s domain.SleepSession) (bool, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, userID, id, s)
+	}
+	return true, nil
+}
+
+func (m *mockSleepRepo) DeleteSleepSession(ctx context.Context, userID int64, id int64) (bool, error) {
+	if m.delFn != nil {
+		return m.delFn(ctx, userID, id)
+	}
+	return true, nil
+}
+
+func (m *mockSleepRepo) ListSleepSessions(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.SleepSession, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, q, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockSleepRepo) SleepMinutesForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error) {
+	if m.minutesFn != nil {
+		return m.minutesFn(ctx, userID, fromDay, toDay, loc)
+	}
+	return nil, nil
+}
+
+// lastNight returns a bedtime and wake time spanning the most recent local
+// midnight in loc.
+func lastNight(loc *time.Location) (time.Time, time.Time) {
+	now := time.Now().In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	return midnight.Add(-90 * time.Minute), midnight.Add(-90*time.Minute + 7*time.Hour)
+}
+
+func TestRecordSession_AttributedToWakeDay(t *testing.T) {
+	loc := time.FixedZone("UTC+9", 9*3600)
+	bed, wake := lastNight(loc)
+	if wake.After(time.Now()) {
+		bed, wake = bed.AddDate(0, 0, -1), wake.AddDate(0, 0, -1)
+	}
+
+	svc := app.NewSleepService(&mockSleepRepo{})
+	got, err := svc.RecordSession(context.Background(), 1, domain.SleepSession{Bedtime: bed, WakeTime: wake}, loc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := wake.In(loc).Format("2006-01-02"); got.Day != want {
+		t.Errorf("expected day %s (wake day), got %s", want, got.Day)
+	}
+	if bed.In(loc).Format("2006-01-02") == got.Day {
+		t.Error("session spanning midnight should not be attributed to the bedtime day")
+	}
+	if got.DurationMinutes != 420 {
+		t.Errorf("expected 420 minutes, got %v", got.DurationMinutes)
+	}
+}
+
+func TestRecordSession_Validation(t *testing.T) {
+	now := time.Now()
+	bad := 6
+	tests := []struct {
+		name string
+		s    domain.SleepSession
+	}{
+		{"missing times", domain.SleepSession{}},
+		{"wake before bed", domain.SleepSession{Bedtime: now.Add(-time.Hour), WakeTime: now.Add(-2 * time.Hour)}},
+		{"too long", domain.SleepSession{Bedtime: now.Add(-30 * time.Hour), WakeTime: now.Add(-time.Hour)}},
+		{"in the future", domain.SleepSession{Bedtime: now.Add(time.Hour), WakeTime: now.Add(8 * time.Hour)}},
+		{"bad quality", domain.SleepSession{Bedtime: now.Add(-8 * time.Hour), WakeTime: now.Add(-time.Hour), Quality: &bad}},
+		{"negative interruptions", domain.SleepSession{Bedtime: now.Add(-8 * time.Hour), WakeTime: now.Add(-time.Hour), Interruptions: -1}},
+	}
+	svc := app.NewSleepService(&mockSleepRepo{})
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.RecordSession(context.Background(), 1, tc.s, time.UTC); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestRecordSession_Overlap(t *testing.T) {
+	now := time.Now()
+	existing := domain.SleepSession{ID: 4, Bedtime: now.Add(-10 * time.Hour), WakeTime: now.Add(-3 * time.Hour)}
+	repo := &mockSleepRepo{
+		listFn: func(_ context.Context, _ int64, _ domain.EventQuery, _ *time.Location) ([]domain.SleepSession, error) {
+			return []domain.SleepSession{existing}, nil
+		},
+	}
+	svc := app.NewSleepService(repo)
+
+	overlapping := domain.SleepSession{Bedtime: now.Add(-4 * time.Hour), WakeTime: now.Add(-time.Hour)}
+	if _, err := svc.RecordSession(context.Background(), 1, overlapping, time.UTC); !errors.Is(err, app.ErrSleepOverlap) {
+		t.Fatalf("expected ErrSleepOverlap, got %v", err)
+	}
+
+	nap := domain.SleepSession{Bedtime: now.Add(-2 * time.Hour), WakeTime: now.Add(-time.Hour)}
+	if _, err := svc.RecordSession(context.Background(), 1, nap, time.UTC); err != nil {
+		t.Fatalf("unexpected error for adjacent nap: %v", err)
+	}
+}
+
+func TestUpdateSession_IgnoresSelfOverlap(t *testing.T) {
+	now := time.Now()
+	existing := domain.SleepSession{ID: 4, Bedtime: now.Add(-10 * time.Hour), WakeTime: now.Add(-3 * time.Hour)}
+	repo := &mockSleepRepo{
+		getFn: func(_ context.Context, _ int64, _ int64, _ *time.Location) (*domain.SleepSession, error) {
+			s := existing
+			return &s, nil
+		},
+		listFn: func(_ context.Context, _ int64, _ domain.EventQuery, _ *time.Location) ([]domain.SleepSession, error) {
+			return []domain.SleepSession{existing}, nil
+		},
+	}
+	svc := app.NewSleepService(repo)
+
+	wake := now.Add(-2 * time.Hour)
+	got, err := svc.UpdateSession(context.Background(), 1, 4, app.SleepPatch{WakeTime: &wake}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.DurationMinutes != 480 {
+		t.Errorf("expected 480 minutes, got %v", got.DurationMinutes)
+	}
+}
+
+func TestSleepSessionByID_NotFound(t *testing.T) {
+	repo := &mockSleepRepo{
+		delFn: func(_ context.Context, _ int64, _ int64) (bool, error) { return false, nil },
+	}
+	svc := app.NewSleepService(repo)
+	if _, err := svc.GetSession(context.Background(), 1, 5, time.UTC); !errors.Is(err, app.ErrEntryNotFound) {
+		t.Errorf("GetSession: expected ErrEntryNotFound, got %v", err)
+	}
+	if err := svc.DeleteSession(context.Background(), 1, 5); !errors.Is(err, app.ErrEntryNotFound) {
+		t.Errorf("DeleteSession: expected ErrEntryNotFound, got %v", err)
+	}
+}
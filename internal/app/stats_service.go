@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// StatsService encapsulates summary statistics use cases.
+type StatsService struct {
+	weightRepo domain.WeightRepository
+	waterRepo  domain.WaterRepository
+}
+
+// NewStatsService creates a StatsService backed by the given repositories.
+func NewStatsService(wr domain.WeightRepository, wa domain.WaterRepository) *StatsService {
+	return &StatsService{weightRepo: wr, waterRepo: wa}
+}
+
+// StatsQuery selects the range and unit of a Summary. The range is given as
+// in ChartQuery, up to about twenty years.
+type StatsQuery struct {
+	Days int
+	From string
+	To   string
+	// Unit is the weight unit, "kg" or "lb".
+	Unit string
+	// WeightPolicy selects each day's weight; empty means
+	// domain.DefaultWeightPolicy.
+	WeightPolicy domain.WeightPolicy
+}
+
+// Summary holds statistics for each metric over a range of local days. Days
+// after today are left out of the range.
+type Summary struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Days int    `json:"days"`
+	Unit string `json:"unit"`
+	// Weight summarizes the weight of each day with one, selected by the
+	// query's weight policy, in Unit.
+	Weight Stats `json:"weight"`
+	// Water summarizes the water total of every day, in liters.
+	Water Stats `json:"water"`
+}
+
+// Stats describes a series of daily values. All but Count are nil when the
+// series is empty.
+type Stats struct {
+	Count  int      `json:"count"`
+	Min    *float64 `json:"min"`
+	Max    *float64 `json:"max"`
+	Mean   *float64 `json:"mean"`
+	Median *float64 `json:"median"`
+	// StdDev is the sample standard deviation, zero for a single value.
+	StdDev *float64     `json:"stdDev"`
+	First  *StatsSample `json:"first"`
+	Last   *StatsSample `json:"last"`
+	// Change is Last minus First.
+	Change *float64 `json:"change"`
+}
+
+// StatsSample is a single day's value within Stats.
+type StatsSample struct {
+	Day   string  `json:"day"`
+	Value float64 `json:"value"`
+}
+
+// GetSummary returns statistics for the range selected by q in loc. Each
+// series for the whole range is fetched with one repository call.
+func (s *StatsService) GetSummary(ctx context.Context, userID int64, q StatsQuery, loc *time.Location) (*Summary, error) {
+	if q.Unit != "kg" && q.Unit != "lb" {
+		return nil, errors.New("unit must be \"kg\" or \"lb\"")
+	}
+	policy, err := resolveWeightPolicy(q.WeightPolicy)
+	if err != nil {
+		return nil, err
+	}
+	first, days, err := resolveDayRange(q.Days, q.From, q.To, maxBucketDays, loc)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	days = min(days, daysBetween(first, today)+1)
+	sum := &Summary{Unit: q.Unit}
+	if days < 1 {
+		return sum, nil
+	}
+	last := first.AddDate(0, 0, days-1)
+	sum.From = first.Format("2006-01-02")
+	sum.To = last.Format("2006-01-02")
+	sum.Days = days
+
+	water, err := s.waterRepo.WaterTotalsForLocalDays(ctx, userID, sum.From, sum.To, loc)
+	if err != nil {
+		return nil, err
+	}
+	weights, err := s.weightRepo.WeightsForLocalDays(ctx, userID, sum.From, sum.To, policy, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	var weightSamples, waterSamples []StatsSample
+	for i := 0; i < days; i++ {
+		day := first.AddDate(0, 0, i).Format("2006-01-02")
+		waterSamples = append(waterSamples, StatsSample{Day: day, Value: water[day]})
+		if e, ok := weights[day]; ok {
+			weightSamples = append(weightSamples, StatsSample{Day: day, Value: domain.ConvertWeight(e.Value, e.Unit, q.Unit)})
+		}
+	}
+	sum.Weight = newStats(weightSamples)
+	sum.Water = newStats(waterSamples)
+	return sum, nil
+}
+
+// newStats describes samples, which are in chronological order.
+func newStats(samples []StatsSample) Stats {
+	n := len(samples)
+	if n == 0 {
+		return Stats{}
+	}
+
+	values := make([]float64, n)
+	var total float64
+	for i, s := range samples {
+		values[i] = s.Value
+		total += s.Value
+	}
+	mid := median(values)
+	mean := total / float64(n)
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	var stdDev float64
+	if n > 1 {
+		stdDev = math.Sqrt(squares / float64(n-1))
+	}
+	first, last := samples[0], samples[n-1]
+	change := last.Value - first.Value
+
+	return Stats{
+		Count:  n,
+		Min:    &values[0],
+		Max:    &values[n-1],
+		Mean:   &mean,
+		Median: &mid,
+		StdDev: &stdDev,
+		First:  &first,
+		Last:   &last,
+		Change: &change,
+	}
+}
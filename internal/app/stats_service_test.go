@@ -0,0 +1,111 @@
+package app_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+func TestGetSummary(t *testing.T) {
+	weights := map[string]domain.WeightEntry{
+		"2024-03-01": {Value: 80, Unit: "kg"},
+		"2024-03-02": {Value: 82, Unit: "kg"},
+		"2024-03-04": {Value: 79, Unit: "kg"},
+		"2024-03-05": {Value: 81, Unit: "kg"},
+	}
+	totals := map[string]float64{"2024-03-01": 2, "2024-03-03": 3, "2024-03-05": 1}
+	var gotFrom, gotTo string
+	wr := &mockWeightRepo{
+		rangeFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+			gotFrom, gotTo = from, to
+			return weights, nil
+		},
+	}
+	wa := &mockWaterRepo{
+		totalsFn: func(_ context.Context, _ int64, _, _ string, _ *time.Location) (map[string]float64, error) {
+			return totals, nil
+		},
+	}
+
+	svc := app.NewStatsService(wr, wa)
+	sum, err := svc.GetSummary(context.Background(), 1, app.StatsQuery{From: "2024-03-01", To: "2024-03-05", Unit: "kg"}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotFrom != "2024-03-01" || gotTo != "2024-03-05" || sum.Days != 5 {
+		t.Fatalf("unexpected range %s..%s (%d days)", gotFrom, gotTo, sum.Days)
+	}
+
+	w := sum.Weight
+	if w.Count != 4 || *w.Min != 79 || *w.Max != 82 || *w.Mean != 80.5 || *w.Median != 80.5 {
+		t.Errorf("unexpected weight stats %+v", w)
+	}
+	if want := math.Sqrt(5.0 / 3); math.Abs(*w.StdDev-want) > 1e-9 {
+		t.Errorf("expected stdDev %v, got %v", want, *w.StdDev)
+	}
+	if w.First.Day != "2024-03-01" || w.Last.Day != "2024-03-05" || *w.Change != 1 {
+		t.Errorf("unexpected first/last/change %+v %+v %v", w.First, w.Last, *w.Change)
+	}
+
+	// Days without water count as zero.
+	water := sum.Water
+	if water.Count != 5 || *water.Mean != 1.2 || *water.Median != 1 || *water.Min != 0 || *water.Change != -1 {
+		t.Errorf("unexpected water stats %+v", water)
+	}
+}
+
+func TestGetSummary_ConvertsUnit(t *testing.T) {
+	wr := &mockWeightRepo{
+		rangeFn: func(_ context.Context, _ int64, _, to string, _ *time.Location) (map[string]domain.WeightEntry, error) {
+			return map[string]domain.WeightEntry{to: {Value: 100, Unit: "kg"}}, nil
+		},
+	}
+	svc := app.NewStatsService(wr, &mockWaterRepo{})
+	sum, err := svc.GetSummary(context.Background(), 1, app.StatsQuery{Days: 7, Unit: "lb"}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum.Weight.Count != 1 || *sum.Weight.Mean < 220.46 || *sum.Weight.Mean > 220.47 || *sum.Weight.StdDev != 0 {
+		t.Errorf("expected a single ~220.46 lb weigh-in, got %+v", sum.Weight)
+	}
+	if sum.Days != 7 || sum.Water.Count != 7 {
+		t.Errorf("expected 7 days of water, got %+v", sum)
+	}
+}
+
+func TestGetSummary_Empty(t *testing.T) {
+	svc := app.NewStatsService(&mockWeightRepo{}, &mockWaterRepo{})
+	sum, err := svc.GetSummary(context.Background(), 1, app.StatsQuery{Days: 30, Unit: "kg"}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum.Weight.Count != 0 || sum.Weight.Mean != nil || sum.Weight.First != nil {
+		t.Errorf("expected empty weight stats, got %+v", sum.Weight)
+	}
+
+	future := time.Now().AddDate(0, 0, 10).Format("2006-01-02")
+	sum, err = svc.GetSummary(context.Background(), 1, app.StatsQuery{From: future, To: future, Unit: "kg"}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sum.Days != 0 || sum.Water.Count != 0 {
+		t.Errorf("expected future days to be left out, got %+v", sum)
+	}
+}
+
+func TestGetSummary_Invalid(t *testing.T) {
+	svc := app.NewStatsService(&mockWeightRepo{}, &mockWaterRepo{})
+	for _, q := range []app.StatsQuery{
+		{Days: 30, Unit: "st"},
+		{From: "2024-03-05", To: "2024-03-01", Unit: "kg"},
+		{From: "2000-01-01", To: "2024-01-01", Unit: "kg"},
+	} {
+		if _, err := svc.GetSummary(context.Background(), 1, q, time.UTC); err == nil {
+			t.Errorf("expected an error for %+v", q)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Trend smoothing methods.
+const (
+	// TrendEMA is Hacker's Diet style exponential smoothing: each weigh-in
+	// moves the trend Alpha of the way towards it.
+	TrendEMA = "ema"
+	// TrendSMA is the mean of the weigh-ins in the trailing Window days.
+	TrendSMA = "sma"
+)
+
+const (
+	// DefaultTrendAlpha is the smoothing factor used by The Hacker's Diet.
+	DefaultTrendAlpha = 0.1
+	// DefaultTrendWindow is the default moving-average window in days.
+	DefaultTrendWindow = 7
+	// maxTrendWindow bounds the moving-average window.
+	maxTrendWindow = 90
+	// maxTrendWarmup bounds the days fetched before a range to seed its trend.
+	maxTrendWarmup = 365
+)
+
+// TrendOptions selects how the smoothed weight series is computed. The zero
+// value disables it.
+type TrendOptions struct {
+	// Method is TrendEMA, TrendSMA or empty for no trend.
+	Method string
+	// Alpha is the TrendEMA smoothing factor in (0, 1].
+	Alpha float64
+	// Window is the TrendSMA window in days.
+	Window int
+}
+
+func (o TrendOptions) validate() error {
+	switch o.Method {
+	case "":
+	case TrendEMA:
+		if o.Alpha <= 0 || o.Alpha > 1 {
+			return errors.New("alpha must be in (0, 1]")
+		}
+	case TrendSMA:
+		if o.Window < 1 || o.Window > maxTrendWindow {
+			return fmt.Errorf("window must be between 1 and %d", maxTrendWindow)
+		}
+	default:
+		return errors.New("trend must be \"ema\" or \"sma\"")
+	}
+	return nil
+}
+
+// warmupDays returns how many days before a range must be read so that the
+// trend on its first day reflects earlier weigh-ins. For TrendEMA this is
+// where older weigh-ins carry less than 1% weight.
+func (o TrendOptions) warmupDays() int {
+	switch o.Method {
+	case TrendEMA:
+		if o.Alpha >= 1 {
+			return 0
+		}
+		return min(maxTrendWarmup, int(math.Ceil(math.Log(0.01)/math.Log(1-o.Alpha))))
+	case TrendSMA:
+		return o.Window - 1
+	default:
+		return 0
+	}
+}
+
+// smooth returns the trend for each day given that day's weigh-in, or nil
+// where there is none, in chronological order. Days without a weigh-in carry
+// the trend forward; days before the first weigh-in have no trend.
+func (o TrendOptions) smooth(values []*float64) []*float64 {
+	out := make([]*float64, len(values))
+	var cur *float64
+	for i, v := range values {
+		switch o.Method {
+		case TrendEMA:
+			if v != nil {
+				next := *v
+				if cur != nil {
+					next = *cur + o.Alpha*(*v-*cur)
+				}
+				cur = &next
+			}
+		case TrendSMA:
+			var sum float64
+			var n int
+			for j := max(0, i-o.Window+1); j <= i; j++ {
+				if values[j] != nil {
+					sum += *values[j]
+					n++
+				}
+			}
+			if n > 0 {
+				mean := sum / float64(n)
+				cur = &mean
+			}
+		default:
+			return out
+		}
+		out[i] = cur
+	}
+	return out
+}
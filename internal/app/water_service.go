@@ -18,22 +18,137 @@ func NewWaterService(repo domain.WaterRepository) *WaterService {
 	return &WaterService{repo: repo}
 }
 
-// GetTodayTotal returns the total water intake in liters for the given local day.
-func (s *WaterService) GetTodayTotal(ctx context.Context, userID int64, today string) (float64, error) {
-	return s.repo.WaterTotalForLocalDay(ctx, userID, today)
+// GetTodayTotal returns the total water intake in liters for the given local
+// day in loc.
+func (s *WaterService) GetTodayTotal(ctx context.Context, userID int64, today string, loc *time.Location) (float64, error) {
+	return s.repo.WaterTotalForLocalDay(ctx, userID, today, loc)
 }
 
-// RecordEvent validates and stores a water intake event.
-func (s *WaterService) RecordEvent(ctx context.Context, userID int64, deltaLiters float64) (int64, error) {
-	if deltaLiters == 0 || deltaLiters < -10 || deltaLiters > 10 {
-		return 0, errors.New("deltaLiters must be non-zero and within [-10, 10]")
+// WaterProgress describes a day's intake against the user's goal.
+type WaterProgress struct {
+	TotalLiters float64 `json:"totalLiters"`
+	GoalLiters  float64 `json:"goalLiters"`
+	// Percent is TotalLiters as a percentage of GoalLiters; it may exceed 100.
+	Percent         float64 `json:"percent"`
+	RemainingLiters float64 `json:"remainingLiters"`
+}
+
+// newWaterProgress compares total against goal.
+func newWaterProgress(total, goal float64) WaterProgress {
+	p := WaterProgress{TotalLiters: total, GoalLiters: goal}
+	if goal > 0 {
+		p.Percent = total / goal * 100
+	}
+	if total < goal {
+		p.RemainingLiters = goal - total
+	}
+	return p
+}
+
+// GetTodayProgress returns the intake for the given local day in loc measured
+// against goal liters.
+func (s *WaterService) GetTodayProgress(ctx context.Context, userID int64, today string, goal float64, loc *time.Location) (WaterProgress, error) {
+	total, err := s.repo.WaterTotalForLocalDay(ctx, userID, today, loc)
+	if err != nil {
+		return WaterProgress{}, err
+	}
+	return newWaterProgress(total, goal), nil
+}
+
+// streakWindowDays is how far back GetStreaks looks for met goals.
+const streakWindowDays = 365
+
+// WaterStreak counts consecutive days on which the water goal was met.
+type WaterStreak struct {
+	// Current is the run ending today, or yesterday while today's goal is
+	// still unmet.
+	Current int `json:"current"`
+	// Longest is the longest run within the last year.
+	Longest int `json:"longest"`
+}
+
+// GetStreaks returns the user's current and longest runs of days, ending on
+// the given local day in loc, on which intake reached goal liters. The whole
+// window is fetched with one repository call.
+func (s *WaterService) GetStreaks(ctx context.Context, userID int64, today string, goal float64, loc *time.Location) (WaterStreak, error) {
+	end, err := time.ParseInLocation("2006-01-02", today, loc)
+	if err != nil {
+		return WaterStreak{}, err
+	}
+	first := end.AddDate(0, 0, -(streakWindowDays - 1))
+	totals, err := s.repo.WaterTotalsForLocalDays(ctx, userID, first.Format("2006-01-02"), today, loc)
+	if err != nil {
+		return WaterStreak{}, err
+	}
+
+	met := make([]bool, streakWindowDays)
+	for i := range met {
+		met[i] = waterGoalMet(totals[first.AddDate(0, 0, i).Format("2006-01-02")], goal)
+	}
+	return waterStreaks(met), nil
+}
+
+// waterStreaks computes the streaks of met, which holds one flag per day in
+// chronological order ending today.
+func waterStreaks(met []bool) WaterStreak {
+	var st WaterStreak
+	run := 0
+	for _, ok := range met {
+		if !ok {
+			run = 0
+			continue
+		}
+		run++
+		if run > st.Longest {
+			st.Longest = run
+		}
+	}
+
+	i := len(met) - 1
+	if i >= 0 && !met[i] {
+		// Today is still in progress, so an unmet goal does not break the streak yet.
+		i--
+	}
+	for ; i >= 0 && met[i]; i-- {
+		st.Current++
+	}
+	return st
+}
+
+// waterGoalMet reports whether total reaches goal, tolerating the rounding
+// error of summing many small intakes.
+func waterGoalMet(total, goal float64) bool {
+	return goal > 0 && total >= goal-1e-9
+}
+
+// RecordEvent validates and stores a water intake event at the time described
+// by at, interpreting local days in loc.
+func (s *WaterService) RecordEvent(ctx context.Context, userID int64, deltaLiters float64, at EntryTime, loc *time.Location) (int64, error) {
+	if err := validateWaterDelta(deltaLiters); err != nil {
+		return 0, err
+	}
+	createdAt, err := at.resolve(time.Now(), loc)
+	if err != nil {
+		return 0, err
 	}
-	return s.repo.AddWaterEvent(ctx, userID, deltaLiters, time.Now())
+	return s.repo.AddWaterEvent(ctx, userID, deltaLiters, createdAt)
 }
 
-// ListRecent returns the most recent water events up to limit.
-func (s *WaterService) ListRecent(ctx context.Context, userID int64, limit int) ([]domain.WaterEvent, error) {
-	return s.repo.ListRecentWaterEvents(ctx, userID, limit)
+// ListRecent returns a newest-first page of water events selected by opts,
+// with day bounds interpreted in loc, and the cursor of the next page if any.
+func (s *WaterService) ListRecent(ctx context.Context, userID int64, opts ListOptions, loc *time.Location) ([]domain.WaterEvent, string, error) {
+	q, err := opts.query(loc)
+	if err != nil {
+		return nil, "", err
+	}
+	items, err := s.repo.ListWaterEvents(ctx, userID, q)
+	if err != nil {
+		return nil, "", err
+	}
+	items, next := nextPage(items, q.Limit, func(e domain.WaterEvent) domain.EventCursor {
+		return domain.EventCursor{CreatedAt: e.CreatedAt, ID: e.ID}
+	})
+	return items, next, nil
 }
 
 // UndoLast deletes the most recent water event.
@@ -45,8 +160,75 @@ func (s *WaterService) UndoLast(ctx context.Context, userID int64) (bool, int64,
 	if len(items) == 0 {
 		return false, 0, nil
 	}
-	if err := s.repo.DeleteWaterEvent(ctx, userID, items[0].ID); err != nil {
+	if _, err := s.repo.DeleteWaterEvent(ctx, userID, items[0].ID); err != nil {
 		return false, 0, err
 	}
 	return true, items[0].ID, nil
 }
+
+// WaterPatch holds the fields to change on an existing water event. Nil fields
+// are left unchanged.
+type WaterPatch struct {
+	DeltaLiters *float64
+	At          *time.Time
+}
+
+// GetEvent returns the user's water event with the given ID.
+func (s *WaterService) GetEvent(ctx context.Context, userID, id int64) (*domain.WaterEvent, error) {
+	event, err := s.repo.GetWaterEvent(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrEntryNotFound
+	}
+	return event, nil
+}
+
+// UpdateEvent applies patch to the user's water event with the given ID and
+// returns the updated event.
+func (s *WaterService) UpdateEvent(ctx context.Context, userID, id int64, patch WaterPatch) (*domain.WaterEvent, error) {
+	event, err := s.GetEvent(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if patch.DeltaLiters != nil {
+		if err := validateWaterDelta(*patch.DeltaLiters); err != nil {
+			return nil, err
+		}
+		event.DeltaLiters = *patch.DeltaLiters
+	}
+	if patch.At != nil {
+		if event.CreatedAt, err = (EntryTime{At: patch.At}).resolve(time.Now(), time.UTC); err != nil {
+			return nil, err
+		}
+	}
+
+	found, err := s.repo.UpdateWaterEvent(ctx, userID, id, event.DeltaLiters, event.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrEntryNotFound
+	}
+	return event, nil
+}
+
+// DeleteEvent removes the user's water event with the given ID.
+func (s *WaterService) DeleteEvent(ctx context.Context, userID, id int64) error {
+	found, err := s.repo.DeleteWaterEvent(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+func validateWaterDelta(deltaLiters float64) error {
+	if deltaLiters == 0 || deltaLiters < -10 || deltaLiters > 10 {
+		return errors.New("deltaLiters must be non-zero and within [-10, 10]")
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package app_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -10,10 +11,15 @@ import (
 )
 
 type mockWaterRepo struct {
-	addFn   func(ctx context.Context, userID int64, d float64, t time.Time) (int64, error)
-	delFn   func(ctx context.Context, userID int64, id int64) error
-	listFn  func(ctx context.Context, userID int64, limit int) ([]domain.WaterEvent, error)
-	totalFn func(ctx context.Context, userID int64, day string) (float64, error)
+	addFn    func(ctx context.Context, userID int64, d float64, t time.Time) (int64, error)
+	delFn    func(ctx context.Context, userID int64, id int64) (bool, error)
+	getFn    func(ctx context.Context, userID int64, id int64) (*domain.WaterEvent, error)
+	updateFn func(ctx context.Context, userID int64, id int64, d float64, t time.Time) (bool, error)
+	listFn   func(ctx context.Context, userID int64, limit int) ([]domain.WaterEvent, error)
+	queryFn  func(ctx context.Context, userID int64, q domain.EventQuery) ([]domain.WaterEvent, error)
+	totalFn  func(ctx context.Context, userID int64, day string, loc *time.Location) (float64, error)
+	totalsFn func(ctx context.Context, userID int64, from, to string, loc *time.Location) (map[string]float64, error)
+	hourlyFn func(ctx context.Context, userID int64, from, to string, loc *time.Location) (domain.WeekdayHourTotals, error)
 }
 
 func (m *mockWaterRepo) AddWaterEvent(ctx context.Context, userID int64, d float64, t time.Time) (int64, error) {
@@ -23,11 +29,25 @@ func (m *mockWaterRepo) AddWaterEvent(ctx context.Context, userID int64, d float
 	return 0, nil
 }
 
-func (m *mockWaterRepo) DeleteWaterEvent(ctx context.Context, userID int64, id int64) error {
+func (m *mockWaterRepo) DeleteWaterEvent(ctx context.Context, userID int64, id int64) (bool, error) {
 	if m.delFn != nil {
 		return m.delFn(ctx, userID, id)
 	}
-	return nil
+	return true, nil
+}
+
+func (m *mockWaterRepo) GetWaterEvent(ctx context.Context, userID int64, id int64) (*domain.WaterEvent, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID, id)
+	}
+	return nil, nil
+}
+
+func (m *mockWaterRepo) UpdateWaterEvent(ctx context.Context, userID int64, id int64, d float64, t time.Time) (bool, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, userID, id, d, t)
+	}
+	return true, nil
 }
 
 func (m *mockWaterRepo) ListRecentWaterEvents(ctx context.Context, userID int64, limit int) ([]domain.WaterEvent, error) {
@@ -37,13 +57,34 @@ func (m *mockWaterRepo) ListRecentWaterEvents(ctx context.Context, userID int64,
 	return nil, nil
 }
 
-func (m *mockWaterRepo) WaterTotalForLocalDay(ctx context.Context, userID int64, day string) (float64, error) {
+func (m *mockWaterRepo) ListWaterEvents(ctx context.Context, userID int64, q domain.EventQuery) ([]domain.WaterEvent, error) {
+	if m.queryFn != nil {
+		return m.queryFn(ctx, userID, q)
+	}
+	return nil, nil
+}
+
+func (m *mockWaterRepo) WaterTotalForLocalDay(ctx context.Context, userID int64, day string, loc *time.Location) (float64, error) {
 	if m.totalFn != nil {
-		return m.totalFn(ctx, userID, day)
+		return m.totalFn(ctx, userID, day, loc)
 	}
 	return 0, nil
 }
 
+func (m *mockWaterRepo) WaterTotalsForLocalDays(ctx context.Context, userID int64, from, to string, loc *time.Location) (map[string]float64, error) {
+	if m.totalsFn != nil {
+		return m.totalsFn(ctx, userID, from, to, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockWaterRepo) WaterTotalsByWeekdayHour(ctx context.Context, userID int64, from, to string, loc *time.Location) (domain.WeekdayHourTotals, error) {
+	if m.hourlyFn != nil {
+		return m.hourlyFn(ctx, userID, from, to, loc)
+	}
+	return domain.WeekdayHourTotals{}, nil
+}
+
 func TestRecordWaterEvent_Validation(t *testing.T) {
 	svc := app.NewWaterService(&mockWaterRepo{})
 
@@ -57,7 +98,7 @@ func TestRecordWaterEvent_Validation(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := svc.RecordEvent(context.Background(), 1, tc.delta)
+			_, err := svc.RecordEvent(context.Background(), 1, tc.delta, app.EntryTime{}, time.UTC)
 			if err == nil {
 				t.Fatal("expected validation error")
 			}
@@ -70,7 +111,7 @@ func TestRecordWaterEvent_Success(t *testing.T) {
 		addFn: func(_ context.Context, _ int64, _ float64, _ time.Time) (int64, error) { return 42, nil },
 	}
 	svc := app.NewWaterService(repo)
-	id, err := svc.RecordEvent(context.Background(), 1, 0.25)
+	id, err := svc.RecordEvent(context.Background(), 1, 0.25, app.EntryTime{}, time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -100,11 +141,11 @@ func TestUndoLastWater_Success(t *testing.T) {
 		listFn: func(_ context.Context, _ int64, _ int) ([]domain.WaterEvent, error) {
 			return []domain.WaterEvent{{ID: 7, DeltaLiters: 0.5}}, nil
 		},
-		delFn: func(_ context.Context, _ int64, id int64) error {
+		delFn: func(_ context.Context, _ int64, id int64) (bool, error) {
 			if id != 7 {
 				t.Fatalf("expected delete id 7, got %d", id)
 			}
-			return nil
+			return true, nil
 		},
 	}
 	svc := app.NewWaterService(repo)
@@ -119,7 +160,7 @@ func TestUndoLastWater_Success(t *testing.T) {
 
 func TestGetTodayTotal(t *testing.T) {
 	repo := &mockWaterRepo{
-		totalFn: func(_ context.Context, _ int64, day string) (float64, error) {
+		totalFn: func(_ context.Context, _ int64, day string, _ *time.Location) (float64, error) {
 			if day != "2026-02-08" {
 				t.Fatalf("unexpected day: %s", day)
 			}
@@ -127,7 +168,7 @@ func TestGetTodayTotal(t *testing.T) {
 		},
 	}
 	svc := app.NewWaterService(repo)
-	total, err := svc.GetTodayTotal(context.Background(), 1, "2026-02-08")
+	total, err := svc.GetTodayTotal(context.Background(), 1, "2026-02-08", time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -135,3 +176,117 @@ func TestGetTodayTotal(t *testing.T) {
 		t.Fatalf("expected 2.5, got %v", total)
 	}
 }
+
+func TestGetTodayProgress(t *testing.T) {
+	repo := &mockWaterRepo{
+		totalFn: func(_ context.Context, _ int64, _ string, _ *time.Location) (float64, error) {
+			return 1.5, nil
+		},
+	}
+	svc := app.NewWaterService(repo)
+	p, err := svc.GetTodayProgress(context.Background(), 1, "2026-02-08", 2, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.TotalLiters != 1.5 || p.GoalLiters != 2 || p.Percent != 75 || p.RemainingLiters != 0.5 {
+		t.Errorf("unexpected progress: %+v", p)
+	}
+
+	repo.totalFn = func(_ context.Context, _ int64, _ string, _ *time.Location) (float64, error) { return 2.5, nil }
+	if p, _ = svc.GetTodayProgress(context.Background(), 1, "2026-02-08", 2, time.UTC); p.RemainingLiters != 0 || p.Percent != 125 {
+		t.Errorf("expected goal exceeded with nothing remaining, got %+v", p)
+	}
+}
+
+func TestGetStreaks(t *testing.T) {
+	tests := []struct {
+		name         string
+		totals       map[string]float64
+		current, max int
+	}{
+		{"no data", nil, 0, 0},
+		{
+			"today in progress",
+			map[string]float64{"2026-02-06": 2, "2026-02-07": 2.1, "2026-02-08": 0.5},
+			2, 2,
+		},
+		{
+			"broken earlier",
+			map[string]float64{
+				"2026-02-01": 2, "2026-02-02": 2, "2026-02-03": 2,
+				"2026-02-05": 2, "2026-02-06": 2, "2026-02-07": 2, "2026-02-08": 2, "2026-02-04": 1.9,
+			},
+			4, 4,
+		},
+		{
+			"broken yesterday",
+			map[string]float64{"2026-02-01": 2, "2026-02-02": 2, "2026-02-03": 2, "2026-02-08": 3},
+			1, 3,
+		},
+		{
+			"rounding",
+			map[string]float64{"2026-02-08": 0.1 + 0.2 + 0.3 + 0.4 + 0.5 + 0.5},
+			1, 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &mockWaterRepo{
+				totalsFn: func(_ context.Context, _ int64, from, to string, _ *time.Location) (map[string]float64, error) {
+					if from != "2025-02-09" || to != "2026-02-08" {
+						t.Fatalf("unexpected window %s..%s", from, to)
+					}
+					return tc.totals, nil
+				},
+			}
+			got, err := app.NewWaterService(repo).GetStreaks(context.Background(), 1, "2026-02-08", 2, time.UTC)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Current != tc.current || got.Longest != tc.max {
+				t.Errorf("expected current=%d longest=%d, got %+v", tc.current, tc.max, got)
+			}
+		})
+	}
+}
+
+func TestUpdateWaterEvent(t *testing.T) {
+	repo := &mockWaterRepo{
+		getFn: func(_ context.Context, _ int64, id int64) (*domain.WaterEvent, error) {
+			return &domain.WaterEvent{ID: id, DeltaLiters: 0.5, CreatedAt: time.Now().Add(-time.Hour)}, nil
+		},
+		updateFn: func(_ context.Context, _ int64, _ int64, d float64, _ time.Time) (bool, error) {
+			if d != 0.75 {
+				t.Errorf("expected delta 0.75, got %v", d)
+			}
+			return true, nil
+		},
+	}
+	svc := app.NewWaterService(repo)
+	delta := 0.75
+	got, err := svc.UpdateEvent(context.Background(), 1, 4, app.WaterPatch{DeltaLiters: &delta})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.DeltaLiters != 0.75 {
+		t.Fatalf("expected 0.75, got %v", got.DeltaLiters)
+	}
+
+	bad := 20.0
+	if _, err := svc.UpdateEvent(context.Background(), 1, 4, app.WaterPatch{DeltaLiters: &bad}); err == nil {
+		t.Fatal("expected validation error")
+	}
+}
+
+func TestWaterEventByID_NotFound(t *testing.T) {
+	repo := &mockWaterRepo{
+		delFn: func(_ context.Context, _ int64, _ int64) (bool, error) { return false, nil },
+	}
+	svc := app.NewWaterService(repo)
+	if _, err := svc.UpdateEvent(context.Background(), 1, 4, app.WaterPatch{}); !errors.Is(err, app.ErrEntryNotFound) {
+		t.Fatalf("expected ErrEntryNotFound from update, got %v", err)
+	}
+	if err := svc.DeleteEvent(context.Background(), 1, 4); !errors.Is(err, app.ErrEntryNotFound) {
+		t.Fatalf("expected ErrEntryNotFound from delete, got %v", err)
+	}
+}
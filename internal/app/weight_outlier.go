@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+// ErrImplausibleWeight indicates that a weigh-in deviates from the user's
+// recent weigh-ins by more than the outlier threshold, such as a mistyped
+// 1800 for 180.0. Recording it again with confirmation accepts it.
+var ErrImplausibleWeight = errors.New("weight is implausible compared to recent weigh-ins")
+
+const (
+	// DefaultOutlierPercent is the default outlier threshold: the largest
+	// deviation from the reference weight, in percent, that is accepted
+	// without confirmation.
+	DefaultOutlierPercent = 10
+	// outlierWindow is how far before a weigh-in the weigh-ins forming its
+	// reference are taken from.
+	outlierWindow = 14 * 24 * time.Hour
+	// outlierScanPage is the page size used to read the history for
+	// FindOutliers.
+	outlierScanPage = 500
+)
+
+// WeightOutlier is a weigh-in flagged by FindOutliers.
+type WeightOutlier struct {
+	Entry domain.WeightEntry `json:"entry"`
+	// Reference is the median of the preceding weigh-ins, in the entry's unit.
+	Reference float64 `json:"reference"`
+	// DeviationPercent is the signed deviation of the entry from Reference.
+	DeviationPercent float64 `json:"deviationPercent"`
+}
+
+// WithOutlierThreshold sets the deviation, in percent, beyond which a weigh-in
+// is implausible. Zero disables the check.
+func (s *WeightService) WithOutlierThreshold(percent float64) *WeightService {
+	s.outlierPercent = percent
+	return s
+}
+
+// checkPlausible returns an error wrapping ErrImplausibleWeight if value in
+// unit, weighed at at, deviates from the median of the user's weigh-ins in
+// the outlierWindow before it by more than the outlier threshold. The event
+// with ID skipID is left out of the reference. Without earlier weigh-ins in
+// the window any value is plausible.
+func (s *WeightService) checkPlausible(ctx context.Context, userID int64, value float64, unit string, at time.Time, skipID int64, loc *time.Location) error {
+	if s.outlierPercent <= 0 {
+		return nil
+	}
+	q := domain.EventQuery{From: at.Add(-outlierWindow), To: at, Limit: outlierScanPage}
+	recent, err := s.repo.ListWeightEvents(ctx, userID, q, loc)
+	if err != nil {
+		return err
+	}
+	var values []float64
+	for _, e := range recent {
+		if e.ID != skipID {
+			values = append(values, domain.ConvertWeight(e.Value, e.Unit, unit))
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	ref := median(values)
+	if dev := deviationPercent(value, ref); math.Abs(dev) > s.outlierPercent {
+		return WeightOutlier{Entry: domain.WeightEntry{Value: value, Unit: unit}, Reference: ref, DeviationPercent: dev}.err()
+	}
+	return nil
+}
+
+// err describes the outlier as an error wrapping ErrImplausibleWeight.
+func (o WeightOutlier) err() error {
+	e := o.Entry
+	return fmt.Errorf("%w: %.1f %s is %+.0f%% off the recent %.1f %s", ErrImplausibleWeight, e.Value, e.Unit, o.DeviationPercent, o.Reference, e.Unit)
+}
+
+// FindOutliers scans the user's whole weight history, oldest first, and
+// returns the weigh-ins that deviate from the median of the plausible
+// weigh-ins in the outlierWindow before them by more than the outlier
+// threshold, newest first.
+func (s *WeightService) FindOutliers(ctx context.Context, userID int64, loc *time.Location) ([]WeightOutlier, error) {
+	if s.outlierPercent <= 0 {
+		return []WeightOutlier{}, nil
+	}
+	history, err := listAll(domain.EventQuery{Limit: outlierScanPage}, func(q domain.EventQuery) ([]domain.WeightEntry, error) {
+		return s.repo.ListWeightEvents(ctx, userID, q, loc)
+	}, func(e domain.WeightEntry) domain.EventCursor {
+		return domain.EventCursor{CreatedAt: e.CreatedAt, ID: e.ID}
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(history)
+
+	outliers := []WeightOutlier{}
+	sweep := outlierSweep{percent: s.outlierPercent}
+	for _, e := range history {
+		if o, ok := sweep.check(e); !ok {
+			outliers = append(outliers, o)
+		}
+	}
+	slices.Reverse(outliers)
+	return outliers, nil
+}
+
+// outlierSweep checks weigh-ins, fed to it oldest first, against the median
+// of the plausible ones among them in the outlierWindow before each.
+type outlierSweep struct {
+	percent   float64
+	plausible []domain.WeightEntry
+}
+
+// check reports whether e is plausible, in which case it becomes part of the
+// reference of later weigh-ins, or else describes how far off it is.
+func (w *outlierSweep) check(e domain.WeightEntry) (WeightOutlier, bool) {
+	// Drop weigh-ins that have left the window of this one.
+	for len(w.plausible) > 0 && e.CreatedAt.Sub(w.plausible[0].CreatedAt) > outlierWindow {
+		w.plausible = w.plausible[1:]
+	}
+	if len(w.plausible) > 0 {
+		values := make([]float64, len(w.plausible))
+		for i, p := range w.plausible {
+			values[i] = domain.ConvertWeight(p.Value, p.Unit, e.Unit)
+		}
+		ref := median(values)
+		if dev := deviationPercent(e.Value, ref); math.Abs(dev) > w.percent {
+			return WeightOutlier{Entry: e, Reference: ref, DeviationPercent: dev}, false
+		}
+	}
+	w.plausible = append(w.plausible, e)
+	return WeightOutlier{}, true
+}
+
+// deviationPercent returns how far value is from ref, in percent of ref.
+func deviationPercent(value, ref float64) float64 {
+	return (value - ref) / ref * 100
+}
+
+// median returns the median of values, which must not be empty. values is
+// sorted in place.
+func median(values []float64) float64 {
+	slices.Sort(values)
+	n := len(values)
+	if n%2 == 0 {
+		return (values[n/2-1] + values[n/2]) / 2
+	}
+	return values[n/2]
+}
@@ -0,0 +1,159 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"vitals/internal/app"
+	"vitals/internal/domain"
+)
+
+// weightHistory returns a mock whose ListWeightEvents serves entries, which
+// are oldest first, honoring the query's bounds, cursor and limit.
+func weightHistory(entries []domain.WeightEntry) *mockWeightRepo {
+	return &mockWeightRepo{
+		queryFn: func(_ context.Context, _ int64, q domain.EventQuery, _ *time.Location) ([]domain.WeightEntry, error) {
+			var out []domain.WeightEntry
+			for _, e := range slices.Backward(entries) {
+				switch {
+				case !q.From.IsZero() && e.CreatedAt.Before(q.From),
+					!q.To.IsZero() && !e.CreatedAt.Before(q.To),
+					q.After != nil && !e.CreatedAt.Before(q.After.CreatedAt):
+					continue
+				}
+				if len(out) == q.Limit {
+					break
+				}
+				out = append(out, e)
+			}
+			return out, nil
+		},
+	}
+}
+
+// dailyHistory returns one weigh-in per day, the last one an hour ago, with
+// the given values in kg.
+func dailyHistory(values ...float64) []domain.WeightEntry {
+	last := time.Now().Add(-time.Hour)
+	out := make([]domain.WeightEntry, len(values))
+	for i, v := range values {
+		out[i] = domain.WeightEntry{
+			ID:        int64(i + 1),
+			Value:     v,
+			Unit:      "kg",
+			CreatedAt: last.AddDate(0, 0, i-len(values)+1),
+		}
+	}
+	return out
+}
+
+func TestRecordWeight_Implausible(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   float64
+		unit    string
+		confirm bool
+		wantErr bool
+	}{
+		{"plausible", 81.5, "kg", false, false},
+		{"typo", 810, "kg", false, true},
+		{"wrong unit", 80, "lb", false, true},
+		{"plausible in other unit", 178, "lb", false, false},
+		{"confirmed typo", 810, "kg", true, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := weightHistory(dailyHistory(80, 81, 80.5))
+			var added bool
+			repo.addFn = func(context.Context, int64, float64, string, domain.BodyComposition, time.Time) (int64, error) {
+				added = true
+				return 1, nil
+			}
+			svc := app.NewWeightService(repo)
+			_, _, err := svc.RecordWeight(context.Background(), 1, tc.value, tc.unit, domain.BodyComposition{}, app.EntryTime{}, tc.confirm, time.UTC)
+			if tc.wantErr {
+				if !errors.Is(err, app.ErrImplausibleWeight) || added {
+					t.Fatalf("expected ErrImplausibleWeight without storing, got %v (added=%v)", err, added)
+				}
+				return
+			}
+			if err != nil || !added {
+				t.Fatalf("expected the weigh-in to be stored, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRecordWeight_ThresholdConfigurable(t *testing.T) {
+	repo := weightHistory(dailyHistory(80))
+	ctx := context.Background()
+
+	strict := app.NewWeightService(repo).WithOutlierThreshold(2)
+	if _, _, err := strict.RecordWeight(ctx, 1, 82, "kg", domain.BodyComposition{}, app.EntryTime{}, false, time.UTC); !errors.Is(err, app.ErrImplausibleWeight) {
+		t.Errorf("expected a 2.5%% jump to fail a 2%% threshold, got %v", err)
+	}
+	off := app.NewWeightService(repo).WithOutlierThreshold(0)
+	if _, _, err := off.RecordWeight(ctx, 1, 800, "kg", domain.BodyComposition{}, app.EntryTime{}, false, time.UTC); err != nil {
+		t.Errorf("expected no check with a zero threshold, got %v", err)
+	}
+	fresh := app.NewWeightService(&mockWeightRepo{})
+	if _, _, err := fresh.RecordWeight(ctx, 1, 800, "kg", domain.BodyComposition{}, app.EntryTime{}, false, time.UTC); err != nil {
+		t.Errorf("expected any first weigh-in to be accepted, got %v", err)
+	}
+}
+
+func TestUpdateEntry_Implausible(t *testing.T) {
+	history := dailyHistory(80, 81, 80.5)
+	repo := weightHistory(history)
+	repo.getFn = func(_ context.Context, _ int64, id int64, _ *time.Location) (*domain.WeightEntry, error) {
+		e := history[id-1]
+		return &e, nil
+	}
+	svc := app.NewWeightService(repo)
+	ctx := context.Background()
+
+	typo := 8050.0
+	if _, err := svc.UpdateEntry(ctx, 1, 3, app.WeightPatch{Value: &typo}, time.UTC); !errors.Is(err, app.ErrImplausibleWeight) {
+		t.Fatalf("expected ErrImplausibleWeight, got %v", err)
+	}
+	if _, err := svc.UpdateEntry(ctx, 1, 3, app.WeightPatch{Value: &typo, Confirm: true}, time.UTC); err != nil {
+		t.Fatalf("expected a confirmed change to pass, got %v", err)
+	}
+	// Fixing an earlier typo is checked against the entries before it only.
+	history[1].Value = 810
+	fix := 81.0
+	if _, err := svc.UpdateEntry(ctx, 1, 2, app.WeightPatch{Value: &fix}, time.UTC); err != nil {
+		t.Fatalf("expected the fix to pass, got %v", err)
+	}
+}
+
+func TestFindOutliers(t *testing.T) {
+	history := dailyHistory(80, 81, 810, 80.5, 8.05, 80, 120, 119, 121)
+	history[5].Unit = "lb" // 80 lb among kg weigh-ins
+	svc := app.NewWeightService(weightHistory(history))
+	outliers, err := svc.FindOutliers(context.Background(), 1, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var ids []int64
+	for _, o := range outliers {
+		ids = append(ids, o.Entry.ID)
+	}
+	// The jump to 120 kg is flagged with each weigh-in after it, as the
+	// reference only moves with plausible weigh-ins.
+	want := []int64{9, 8, 7, 6, 5, 3}
+	if !slices.Equal(ids, want) {
+		t.Fatalf("expected outliers %v, got %v", want, ids)
+	}
+	typo := outliers[len(outliers)-1]
+	if typo.Reference != 80.5 || typo.DeviationPercent < 906 || typo.DeviationPercent > 907 {
+		t.Errorf("unexpected reference %v and deviation %v", typo.Reference, typo.DeviationPercent)
+	}
+	if unit := outliers[3]; unit.Reference < 177 || unit.Reference > 179 {
+		t.Errorf("expected the reference in lb, got %v", unit.Reference)
+	}
+}
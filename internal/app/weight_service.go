@@ -8,52 +8,248 @@ import (
 	"vitals/internal/domain"
 )
 
+// ErrEntryNotFound indicates that the requested entry does not exist or
+// belongs to another user.
+var ErrEntryNotFound = errors.New("entry not found")
+
+// ErrInvalidWeightPolicy indicates a weight policy that is not one of the
+// domain.WeightPolicy constants.
+var ErrInvalidWeightPolicy = errors.New("weight policy must be \"last\", \"first\", \"min\" or \"mean\"")
+
 // WeightService encapsulates weight-tracking use cases.
 type WeightService struct {
-	repo domain.WeightRepository
+	repo           domain.WeightRepository
+	outlierPercent float64
 }
 
-// NewWeightService creates a WeightService backed by the given repository.
+// NewWeightService creates a WeightService backed by the given repository,
+// with the default outlier threshold.
 func NewWeightService(repo domain.WeightRepository) *WeightService {
-	return &WeightService{repo: repo}
+	return &WeightService{repo: repo, outlierPercent: DefaultOutlierPercent}
 }
 
-// GetTodayWeight returns the latest weight entry for the given local day.
-func (s *WeightService) GetTodayWeight(ctx context.Context, userID int64, today string) (*domain.WeightEntry, error) {
-	return s.repo.LatestWeightForLocalDay(ctx, userID, today)
+// GetTodayWeight returns the weight entry representing the given local day in
+// loc under policy, or under domain.DefaultWeightPolicy if it is empty.
+func (s *WeightService) GetTodayWeight(ctx context.Context, userID int64, today string, policy domain.WeightPolicy, loc *time.Location) (*domain.WeightEntry, error) {
+	policy, err := resolveWeightPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.WeightForLocalDay(ctx, userID, today, policy, loc)
 }
 
-// RecordWeight validates and stores a new weight measurement, returning the
-// latest entry for today after the insert.
-func (s *WeightService) RecordWeight(ctx context.Context, userID int64, value float64, unit string) (*domain.WeightEntry, string, error) {
-	if value <= 0 {
-		return nil, "", errors.New("value must be > 0")
+// RecordWeight validates and stores a new weight measurement, with optional
+// body composition, at the time described by at, returning the stored entry
+// with its ID and its local day in loc. Unless confirm is set, a value
+// implausibly far from the recent weigh-ins is rejected with
+// ErrImplausibleWeight.
+func (s *WeightService) RecordWeight(ctx context.Context, userID int64, value float64, unit string, comp domain.BodyComposition, at EntryTime, confirm bool, loc *time.Location) (*domain.WeightEntry, string, error) {
+	if err := validateWeight(value, unit); err != nil {
+		return nil, "", err
 	}
-	if unit != "kg" && unit != "lb" {
-		return nil, "", errors.New("unit must be \"kg\" or \"lb\"")
+	if err := validateComposition(value, comp); err != nil {
+		return nil, "", err
+	}
+	createdAt, err := at.resolve(time.Now(), loc)
+	if err != nil {
+		return nil, "", err
+	}
+	if !confirm {
+		if err := s.checkPlausible(ctx, userID, value, unit, createdAt, 0, loc); err != nil {
+			return nil, "", err
+		}
 	}
-	now := time.Now()
-	today := now.In(time.Local).Format("2006-01-02")
-	if _, err := s.repo.AddWeightEvent(ctx, userID, value, unit, now); err != nil {
-		return nil, today, err
+	day := createdAt.In(loc).Format("2006-01-02")
+	id, err := s.repo.AddWeightEvent(ctx, userID, value, unit, comp, createdAt)
+	if err != nil {
+		return nil, day, err
 	}
-	entry, err := s.repo.LatestWeightForLocalDay(ctx, userID, today)
-	return entry, today, err
+	return &domain.WeightEntry{
+		ID:              id,
+		UserID:          userID,
+		Day:             day,
+		Value:           value,
+		Unit:            unit,
+		BodyComposition: comp,
+		CreatedAt:       createdAt,
+	}, day, nil
 }
 
-// ListRecent returns the most recent weight events up to limit.
-func (s *WeightService) ListRecent(ctx context.Context, userID int64, limit int) ([]domain.WeightEntry, error) {
-	return s.repo.ListRecentWeightEvents(ctx, userID, limit)
+// ListRecent returns a newest-first page of weight events selected by opts,
+// with days computed in loc, and the cursor of the next page if any.
+func (s *WeightService) ListRecent(ctx context.Context, userID int64, opts ListOptions, loc *time.Location) ([]domain.WeightEntry, string, error) {
+	q, err := opts.query(loc)
+	if err != nil {
+		return nil, "", err
+	}
+	items, err := s.repo.ListWeightEvents(ctx, userID, q, loc)
+	if err != nil {
+		return nil, "", err
+	}
+	items, next := nextPage(items, q.Limit, func(e domain.WeightEntry) domain.EventCursor {
+		return domain.EventCursor{CreatedAt: e.CreatedAt, ID: e.ID}
+	})
+	return items, next, nil
 }
 
-// UndoLast deletes the most recent weight event and returns the new latest
-// entry for today.
-func (s *WeightService) UndoLast(ctx context.Context, userID int64) (bool, *domain.WeightEntry, string, error) {
-	today := time.Now().In(time.Local).Format("2006-01-02")
+// UndoLast deletes the most recent weight event and returns the entry now
+// representing today in loc under policy, as GetTodayWeight does.
+func (s *WeightService) UndoLast(ctx context.Context, userID int64, policy domain.WeightPolicy, loc *time.Location) (bool, *domain.WeightEntry, string, error) {
+	today := time.Now().In(loc).Format("2006-01-02")
+	policy, err := resolveWeightPolicy(policy)
+	if err != nil {
+		return false, nil, today, err
+	}
 	deleted, err := s.repo.DeleteLatestWeightEvent(ctx, userID)
 	if err != nil {
 		return false, nil, today, err
 	}
-	entry, _ := s.repo.LatestWeightForLocalDay(ctx, userID, today)
+	entry, err := s.repo.WeightForLocalDay(ctx, userID, today, policy, loc)
+	if err != nil {
+		return deleted, nil, today, err
+	}
 	return deleted, entry, today, nil
 }
+
+// WeightPatch holds the fields to change on an existing weight entry. Nil
+// fields are left unchanged.
+type WeightPatch struct {
+	Value *float64
+	Unit  *string
+	At    *time.Time
+	// Composition metrics that are set replace the entry's corresponding
+	// metrics; the rest are left unchanged.
+	Composition domain.BodyComposition
+	// Confirm accepts a new value or unit that is implausibly far from the
+	// recent weigh-ins.
+	Confirm bool
+}
+
+// GetEntry returns the user's weight entry with the given ID.
+func (s *WeightService) GetEntry(ctx context.Context, userID, id int64, loc *time.Location) (*domain.WeightEntry, error) {
+	entry, err := s.repo.GetWeightEvent(ctx, userID, id, loc)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrEntryNotFound
+	}
+	return entry, nil
+}
+
+// UpdateEntry applies patch to the user's weight entry with the given ID and
+// returns the updated entry. A changed value or unit is checked like a new
+// weigh-in unless patch.Confirm is set.
+func (s *WeightService) UpdateEntry(ctx context.Context, userID, id int64, patch WeightPatch, loc *time.Location) (*domain.WeightEntry, error) {
+	entry, err := s.GetEntry(ctx, userID, id, loc)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Value != nil {
+		entry.Value = *patch.Value
+	}
+	if patch.Unit != nil {
+		entry.Unit = *patch.Unit
+	}
+	entry.BodyComposition = mergeComposition(entry.BodyComposition, patch.Composition)
+	if err := validateWeight(entry.Value, entry.Unit); err != nil {
+		return nil, err
+	}
+	if err := validateComposition(entry.Value, entry.BodyComposition); err != nil {
+		return nil, err
+	}
+	if patch.At != nil {
+		if entry.CreatedAt, err = (EntryTime{At: patch.At}).resolve(time.Now(), loc); err != nil {
+			return nil, err
+		}
+	}
+	if (patch.Value != nil || patch.Unit != nil) && !patch.Confirm {
+		if err := s.checkPlausible(ctx, userID, entry.Value, entry.Unit, entry.CreatedAt, id, loc); err != nil {
+			return nil, err
+		}
+	}
+
+	found, err := s.repo.UpdateWeightEvent(ctx, userID, id, entry.Value, entry.Unit, entry.BodyComposition, entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrEntryNotFound
+	}
+	entry.Day = entry.CreatedAt.In(loc).Format("2006-01-02")
+	return entry, nil
+}
+
+// DeleteEntry removes the user's weight entry with the given ID.
+func (s *WeightService) DeleteEntry(ctx context.Context, userID, id int64) error {
+	found, err := s.repo.DeleteWeightEvent(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrEntryNotFound
+	}
+	return nil
+}
+
+// resolveWeightPolicy validates policy, defaulting an empty one to
+// domain.DefaultWeightPolicy.
+func resolveWeightPolicy(policy domain.WeightPolicy) (domain.WeightPolicy, error) {
+	if policy == "" {
+		return domain.DefaultWeightPolicy, nil
+	}
+	if !policy.Valid() {
+		return "", ErrInvalidWeightPolicy
+	}
+	return policy, nil
+}
+
+func validateWeight(value float64, unit string) error {
+	if value <= 0 {
+		return errors.New("value must be > 0")
+	}
+	if unit != "kg" && unit != "lb" {
+		return errors.New("unit must be \"kg\" or \"lb\"")
+	}
+	return nil
+}
+
+// mergeComposition returns base with every metric that is set in patch
+// replaced.
+func mergeComposition(base, patch domain.BodyComposition) domain.BodyComposition {
+	if patch.BodyFatPercent != nil {
+		base.BodyFatPercent = patch.BodyFatPercent
+	}
+	if patch.MuscleMass != nil {
+		base.MuscleMass = patch.MuscleMass
+	}
+	if patch.WaterPercent != nil {
+		base.WaterPercent = patch.WaterPercent
+	}
+	if patch.BoneMass != nil {
+		base.BoneMass = patch.BoneMass
+	}
+	if patch.VisceralFat != nil {
+		base.VisceralFat = patch.VisceralFat
+	}
+	return base
+}
+
+func validateComposition(weight float64, c domain.BodyComposition) error {
+	if c.BodyFatPercent != nil && (*c.BodyFatPercent <= 0 || *c.BodyFatPercent >= 100) {
+		return errors.New("bodyFatPercent must be between 0 and 100")
+	}
+	if c.WaterPercent != nil && (*c.WaterPercent <= 0 || *c.WaterPercent >= 100) {
+		return errors.New("waterPercent must be between 0 and 100")
+	}
+	if c.MuscleMass != nil && (*c.MuscleMass <= 0 || *c.MuscleMass >= weight) {
+		return errors.New("muscleMass must be > 0 and less than the weight")
+	}
+	if c.BoneMass != nil && (*c.BoneMass <= 0 || *c.BoneMass >= weight) {
+		return errors.New("boneMass must be > 0 and less than the weight")
+	}
+	if c.VisceralFat != nil && (*c.VisceralFat < 1 || *c.VisceralFat > 59) {
+		return errors.New("visceralFat must be between 1 and 59")
+	}
+	return nil
+}
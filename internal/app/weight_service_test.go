@@ -11,15 +11,23 @@ import (
 )
 
 type mockWeightRepo struct {
-	addFn    func(ctx context.Context, userID int64, v float64, u string, t time.Time) (int64, error)
+	addFn    func(ctx context.Context, userID int64, v float64, u string, c domain.BodyComposition, t time.Time) (int64, error)
 	deleteFn func(ctx context.Context, userID int64) (bool, error)
-	latestFn func(ctx context.Context, userID int64, day string) (*domain.WeightEntry, error)
-	listFn   func(ctx context.Context, userID int64, limit int) ([]domain.WeightEntry, error)
+	getFn    func(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.WeightEntry, error)
+	updateFn func(ctx context.Context, userID int64, id int64, v float64, u string, c domain.BodyComposition, t time.Time) (bool, error)
+	delIDFn  func(ctx context.Context, userID int64, id int64) (bool, error)
+	latestFn func(ctx context.Context, userID int64, day string, loc *time.Location) (*domain.WeightEntry, error)
+	listFn   func(ctx context.Context, userID int64, limit int, loc *time.Location) ([]domain.WeightEntry, error)
+	queryFn  func(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.WeightEntry, error)
+	rangeFn  func(ctx context.Context, userID int64, from, to string, loc *time.Location) (map[string]domain.WeightEntry, error)
+
+	// gotPolicy records the policy of the last daily weight query.
+	gotPolicy domain.WeightPolicy
 }
 
-func (m *mockWeightRepo) AddWeightEvent(ctx context.Context, userID int64, v float64, u string, t time.Time) (int64, error) {
+func (m *mockWeightRepo) AddWeightEvent(ctx context.Context, userID int64, v float64, u string, c domain.BodyComposition, t time.Time) (int64, error) {
 	if m.addFn != nil {
-		return m.addFn(ctx, userID, v, u, t)
+		return m.addFn(ctx, userID, v, u, c, t)
 	}
 	return 0, nil
 }
@@ -31,16 +39,53 @@ func (m *mockWeightRepo) DeleteLatestWeightEvent(ctx context.Context, userID int
 	return false, nil
 }
 
-func (m *mockWeightRepo) LatestWeightForLocalDay(ctx context.Context, userID int64, day string) (*domain.WeightEntry, error) {
+func (m *mockWeightRepo) GetWeightEvent(ctx context.Context, userID int64, id int64, loc *time.Location) (*domain.WeightEntry, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID, id, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockWeightRepo) UpdateWeightEvent(ctx context.Context, userID int64, id int64, v float64, u string, c domain.BodyComposition, t time.Time) (bool, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, userID, id, v, u, c, t)
+	}
+	return true, nil
+}
+
+func (m *mockWeightRepo) DeleteWeightEvent(ctx context.Context, userID int64, id int64) (bool, error) {
+	if m.delIDFn != nil {
+		return m.delIDFn(ctx, userID, id)
+	}
+	return true, nil
+}
+
+func (m *mockWeightRepo) WeightForLocalDay(ctx context.Context, userID int64, day string, policy domain.WeightPolicy, loc *time.Location) (*domain.WeightEntry, error) {
+	m.gotPolicy = policy
 	if m.latestFn != nil {
-		return m.latestFn(ctx, userID, day)
+		return m.latestFn(ctx, userID, day, loc)
 	}
 	return nil, nil
 }
 
-func (m *mockWeightRepo) ListRecentWeightEvents(ctx context.Context, userID int64, limit int) ([]domain.WeightEntry, error) {
+func (m *mockWeightRepo) ListRecentWeightEvents(ctx context.Context, userID int64, limit int, loc *time.Location) ([]domain.WeightEntry, error) {
 	if m.listFn != nil {
-		return m.listFn(ctx, userID, limit)
+		return m.listFn(ctx, userID, limit, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockWeightRepo) ListWeightEvents(ctx context.Context, userID int64, q domain.EventQuery, loc *time.Location) ([]domain.WeightEntry, error) {
+	if m.queryFn != nil {
+		return m.queryFn(ctx, userID, q, loc)
+	}
+	return nil, nil
+}
+
+func (m *mockWeightRepo) WeightsForLocalDays(ctx context.Context, userID int64, from, to string, policy domain.WeightPolicy, loc *time.Location) (map[string]domain.WeightEntry, error) {
+	m.gotPolicy = policy
+	if m.rangeFn != nil {
+		return m.rangeFn(ctx, userID, from, to, loc)
 	}
 	return nil, nil
 }
@@ -59,7 +104,7 @@ func TestRecordWeight_Validation(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			_, _, err := svc.RecordWeight(context.Background(), 1, tc.value, tc.unit)
+			_, _, err := svc.RecordWeight(context.Background(), 1, tc.value, tc.unit, domain.BodyComposition{}, app.EntryTime{}, false, time.UTC)
 			if err == nil {
 				t.Fatal("expected validation error")
 			}
@@ -70,15 +115,15 @@ func TestRecordWeight_Validation(t *testing.T) {
 func TestRecordWeight_Success(t *testing.T) {
 	entry := &domain.WeightEntry{ID: 1, Value: 80, Unit: "kg"}
 	repo := &mockWeightRepo{
-		addFn: func(_ context.Context, _ int64, _ float64, _ string, _ time.Time) (int64, error) {
+		addFn: func(_ context.Context, _ int64, _ float64, _ string, _ domain.BodyComposition, _ time.Time) (int64, error) {
 			return 1, nil
 		},
-		latestFn: func(_ context.Context, _ int64, _ string) (*domain.WeightEntry, error) {
+		latestFn: func(_ context.Context, _ int64, _ string, _ *time.Location) (*domain.WeightEntry, error) {
 			return entry, nil
 		},
 	}
 	svc := app.NewWeightService(repo)
-	got, today, err := svc.RecordWeight(context.Background(), 1, 80, "kg")
+	got, today, err := svc.RecordWeight(context.Background(), 1, 80, "kg", domain.BodyComposition{}, app.EntryTime{}, false, time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -90,14 +135,82 @@ func TestRecordWeight_Success(t *testing.T) {
 	}
 }
 
+func TestRecordWeight_CompositionValidation(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name string
+		comp domain.BodyComposition
+		ok   bool
+	}{
+		{"full", domain.BodyComposition{BodyFatPercent: f(18), MuscleMass: f(60), WaterPercent: f(55), BoneMass: f(3), VisceralFat: f(7)}, true},
+		{"body fat 100%", domain.BodyComposition{BodyFatPercent: f(100)}, false},
+		{"water 0%", domain.BodyComposition{WaterPercent: f(0)}, false},
+		{"muscle heavier than body", domain.BodyComposition{MuscleMass: f(90)}, false},
+		{"negative bone mass", domain.BodyComposition{BoneMass: f(-1)}, false},
+		{"visceral fat rating too high", domain.BodyComposition{VisceralFat: f(70)}, false},
+	}
+	svc := app.NewWeightService(&mockWeightRepo{})
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := svc.RecordWeight(context.Background(), 1, 80, "kg", tc.comp, app.EntryTime{}, false, time.UTC)
+			if (err == nil) != tc.ok {
+				t.Fatalf("ok=%v, got err=%v", tc.ok, err)
+			}
+		})
+	}
+}
+
+func TestRecordWeight_Backdated(t *testing.T) {
+	var stored time.Time
+	repo := &mockWeightRepo{
+		addFn: func(_ context.Context, _ int64, _ float64, _ string, _ domain.BodyComposition, at time.Time) (int64, error) {
+			stored = at
+			return 7, nil
+		},
+		// A later weigh-in on the same day must not be returned instead.
+		latestFn: func(_ context.Context, _ int64, day string, _ *time.Location) (*domain.WeightEntry, error) {
+			return &domain.WeightEntry{ID: 3, Day: day, Value: 81, Unit: "kg"}, nil
+		},
+	}
+	svc := app.NewWeightService(repo)
+	yesterday := time.Now().In(time.UTC).AddDate(0, 0, -1).Format("2006-01-02")
+	entry, day, err := svc.RecordWeight(context.Background(), 1, 80, "kg", domain.BodyComposition{}, app.EntryTime{Day: yesterday}, false, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if day != yesterday || entry.Day != yesterday {
+		t.Fatalf("expected day %s, got returned=%s entry=%s", yesterday, day, entry.Day)
+	}
+	if entry.ID != 7 || entry.Value != 80 || !entry.CreatedAt.Equal(stored) {
+		t.Fatalf("expected the inserted entry, got %+v", entry)
+	}
+	if got := stored.In(time.UTC).Format("2006-01-02"); got != yesterday {
+		t.Fatalf("expected entry stored on %s, got %s", yesterday, got)
+	}
+}
+
+func TestRecordWeight_FutureRejected(t *testing.T) {
+	repo := &mockWeightRepo{
+		addFn: func(_ context.Context, _ int64, _ float64, _ string, _ domain.BodyComposition, _ time.Time) (int64, error) {
+			t.Fatal("repository should not be called")
+			return 0, nil
+		},
+	}
+	svc := app.NewWeightService(repo)
+	future := time.Now().Add(2 * time.Hour)
+	if _, _, err := svc.RecordWeight(context.Background(), 1, 80, "kg", domain.BodyComposition{}, app.EntryTime{At: &future}, false, time.UTC); err == nil {
+		t.Fatal("expected error for future entry")
+	}
+}
+
 func TestRecordWeight_RepoError(t *testing.T) {
 	repo := &mockWeightRepo{
-		addFn: func(_ context.Context, _ int64, _ float64, _ string, _ time.Time) (int64, error) {
+		addFn: func(_ context.Context, _ int64, _ float64, _ string, _ domain.BodyComposition, _ time.Time) (int64, error) {
 			return 0, errors.New("db down")
 		},
 	}
 	svc := app.NewWeightService(repo)
-	_, _, err := svc.RecordWeight(context.Background(), 1, 80, "kg")
+	_, _, err := svc.RecordWeight(context.Background(), 1, 80, "kg", domain.BodyComposition{}, app.EntryTime{}, false, time.UTC)
 	if err == nil {
 		t.Fatal("expected error from repo")
 	}
@@ -106,7 +219,7 @@ func TestRecordWeight_RepoError(t *testing.T) {
 func TestGetTodayWeight(t *testing.T) {
 	entry := &domain.WeightEntry{ID: 5, Value: 75, Unit: "kg"}
 	repo := &mockWeightRepo{
-		latestFn: func(_ context.Context, _ int64, day string) (*domain.WeightEntry, error) {
+		latestFn: func(_ context.Context, _ int64, day string, _ *time.Location) (*domain.WeightEntry, error) {
 			if day != "2026-01-15" {
 				t.Fatalf("unexpected day: %s", day)
 			}
@@ -114,39 +227,227 @@ func TestGetTodayWeight(t *testing.T) {
 		},
 	}
 	svc := app.NewWeightService(repo)
-	got, err := svc.GetTodayWeight(context.Background(), 1, "2026-01-15")
+	got, err := svc.GetTodayWeight(context.Background(), 1, "2026-01-15", "", time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if got == nil || got.ID != 5 {
 		t.Fatalf("unexpected entry: %v", got)
 	}
+	if repo.gotPolicy != domain.DefaultWeightPolicy {
+		t.Errorf("expected the default policy, got %q", repo.gotPolicy)
+	}
+
+	if _, err := svc.GetTodayWeight(context.Background(), 1, "2026-01-15", domain.WeightPolicyMin, time.UTC); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.gotPolicy != domain.WeightPolicyMin {
+		t.Errorf("expected the min policy, got %q", repo.gotPolicy)
+	}
+	if _, err := svc.GetTodayWeight(context.Background(), 1, "2026-01-15", "median", time.UTC); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
 }
 
 func TestUndoLastWeight(t *testing.T) {
 	repo := &mockWeightRepo{
 		deleteFn: func(_ context.Context, _ int64) (bool, error) { return true, nil },
-		latestFn: func(_ context.Context, _ int64, _ string) (*domain.WeightEntry, error) { return nil, nil },
+		latestFn: func(_ context.Context, _ int64, _ string, _ *time.Location) (*domain.WeightEntry, error) {
+			return nil, nil
+		},
 	}
 	svc := app.NewWeightService(repo)
-	deleted, _, _, err := svc.UndoLast(context.Background(), 1)
+	deleted, _, _, err := svc.UndoLast(context.Background(), 1, domain.WeightPolicyMin, time.UTC)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if !deleted {
 		t.Fatal("expected deleted=true")
 	}
+	if repo.gotPolicy != domain.WeightPolicyMin {
+		t.Errorf("expected today's weight under the min policy, got %q", repo.gotPolicy)
+	}
+
+	repo.latestFn = func(context.Context, int64, string, *time.Location) (*domain.WeightEntry, error) {
+		return nil, errors.New("db down")
+	}
+	if _, _, _, err := svc.UndoLast(context.Background(), 1, "", time.UTC); err == nil {
+		t.Error("expected the error reading today's weight")
+	}
 }
 
 func TestListRecentWeight_Error(t *testing.T) {
 	repo := &mockWeightRepo{
-		listFn: func(_ context.Context, _ int64, _ int) ([]domain.WeightEntry, error) {
+		queryFn: func(_ context.Context, _ int64, _ domain.EventQuery, _ *time.Location) ([]domain.WeightEntry, error) {
 			return nil, errors.New("db down")
 		},
 	}
 	svc := app.NewWeightService(repo)
-	_, err := svc.ListRecent(context.Background(), 1, 10)
-	if err == nil {
-		t.Fatal("expected error")
+	_, _, err := svc.ListRecent(context.Background(), 1, app.ListOptions{Limit: 10}, time.UTC)
+	if err == nil || errors.Is(err, app.ErrInvalidListOptions) {
+		t.Fatalf("expected the repository error, got %v", err)
+	}
+}
+
+func TestListRecentWeight_Pagination(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var all []domain.WeightEntry
+	for i := 5; i >= 1; i-- {
+		all = append(all, domain.WeightEntry{ID: int64(i), Value: 80, Unit: "kg", CreatedAt: base.Add(time.Duration(i) * time.Hour)})
+	}
+	var queries []domain.EventQuery
+	repo := &mockWeightRepo{
+		queryFn: func(_ context.Context, _ int64, q domain.EventQuery, _ *time.Location) ([]domain.WeightEntry, error) {
+			queries = append(queries, q)
+			var out []domain.WeightEntry
+			for _, e := range all {
+				if q.After != nil && !e.CreatedAt.Before(q.After.CreatedAt) {
+					continue
+				}
+				if len(out) < q.Limit {
+					out = append(out, e)
+				}
+			}
+			return out, nil
+		},
+	}
+	svc := app.NewWeightService(repo)
+	ctx := context.Background()
+
+	page, next, err := svc.ListRecent(ctx, 1, app.ListOptions{Limit: 2}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != 5 || next == "" {
+		t.Fatalf("first page: got %d items, next=%q", len(page), next)
+	}
+	if queries[0].Limit != 3 {
+		t.Errorf("expected look-ahead limit 3, got %d", queries[0].Limit)
+	}
+
+	page, next, err = svc.ListRecent(ctx, 1, app.ListOptions{Limit: 2, Cursor: next}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 2 || page[0].ID != 3 || next == "" {
+		t.Fatalf("second page: got %+v, next=%q", page, next)
+	}
+
+	page, next, err = svc.ListRecent(ctx, 1, app.ListOptions{Limit: 2, Cursor: next}, time.UTC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != 1 || next != "" {
+		t.Fatalf("last page: got %+v, next=%q", page, next)
+	}
+}
+
+func TestListRecentWeight_InvalidOptions(t *testing.T) {
+	svc := app.NewWeightService(&mockWeightRepo{})
+	cases := []app.ListOptions{
+		{Cursor: "not-a-cursor!"},
+		{From: "yesterday"},
+		{From: "2026-03-05", To: "2026-03-01"},
+	}
+	for _, opts := range cases {
+		if _, _, err := svc.ListRecent(context.Background(), 1, opts, time.UTC); !errors.Is(err, app.ErrInvalidListOptions) {
+			t.Errorf("expected an invalid options error for %+v, got %v", opts, err)
+		}
+	}
+}
+
+func TestListRecentWeight_TimestampBounds(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var got domain.EventQuery
+	svc := app.NewWeightService(&mockWeightRepo{
+		queryFn: func(_ context.Context, _ int64, q domain.EventQuery, _ *time.Location) ([]domain.WeightEntry, error) {
+			got = q
+			return nil, nil
+		},
+	})
+	// A timestamp as both bounds selects the events stamped exactly then.
+	opts := app.ListOptions{From: at.Format(time.RFC3339), To: at.Format(time.RFC3339)}
+	if _, _, err := svc.ListRecent(context.Background(), 1, opts, time.UTC); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.From.Equal(at) || !got.To.Equal(at) || !got.ToInclusive {
+		t.Errorf("expected the inclusive range [%v, %v], got %+v", at, at, got)
+	}
+
+	opts = app.ListOptions{From: "2026-03-01", To: "2026-03-01"}
+	if _, _, err := svc.ListRecent(context.Background(), 1, opts, time.UTC); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.To.Equal(at.Add(12*time.Hour)) || got.ToInclusive {
+		t.Errorf("expected a day to end at the next midnight, got %+v", got)
+	}
+}
+
+func TestUpdateWeightEntry(t *testing.T) {
+	existing := func() *domain.WeightEntry {
+		return &domain.WeightEntry{ID: 3, Value: 80, Unit: "kg", CreatedAt: time.Now().Add(-48 * time.Hour)}
+	}
+	ptrF := func(v float64) *float64 { return &v }
+	ptrS := func(v string) *string { return &v }
+
+	tests := []struct {
+		name    string
+		current *domain.WeightEntry
+		patch   app.WeightPatch
+		wantErr error
+		wantVal float64
+	}{
+		{"value only", existing(), app.WeightPatch{Value: ptrF(79.2)}, nil, 79.2},
+		{"unit only", existing(), app.WeightPatch{Unit: ptrS("lb")}, nil, 80},
+		{"not found", nil, app.WeightPatch{Value: ptrF(79)}, app.ErrEntryNotFound, 0},
+		{"invalid value", existing(), app.WeightPatch{Value: ptrF(-1)}, errors.New("validation"), 0},
+		{"invalid unit", existing(), app.WeightPatch{Unit: ptrS("st")}, errors.New("validation"), 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			updated := false
+			repo := &mockWeightRepo{
+				getFn: func(_ context.Context, _ int64, _ int64, _ *time.Location) (*domain.WeightEntry, error) {
+					return tc.current, nil
+				},
+				updateFn: func(_ context.Context, _ int64, id int64, v float64, _ string, _ domain.BodyComposition, _ time.Time) (bool, error) {
+					updated = true
+					if id != 3 || v != tc.wantVal {
+						t.Errorf("unexpected update id=%d value=%v", id, v)
+					}
+					return true, nil
+				},
+			}
+			svc := app.NewWeightService(repo)
+			got, err := svc.UpdateEntry(context.Background(), 1, 3, tc.patch, time.UTC)
+			if tc.wantErr != nil {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if errors.Is(tc.wantErr, app.ErrEntryNotFound) && !errors.Is(err, app.ErrEntryNotFound) {
+					t.Fatalf("expected ErrEntryNotFound, got %v", err)
+				}
+				if updated {
+					t.Fatal("repository should not be updated")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Value != tc.wantVal || got.Day == "" {
+				t.Fatalf("unexpected entry: %+v", got)
+			}
+		})
+	}
+}
+
+func TestDeleteWeightEntry_NotFound(t *testing.T) {
+	repo := &mockWeightRepo{
+		delIDFn: func(_ context.Context, _ int64, _ int64) (bool, error) { return false, nil },
+	}
+	svc := app.NewWeightService(repo)
+	if err := svc.DeleteEntry(context.Background(), 1, 99); !errors.Is(err, app.ErrEntryNotFound) {
+		t.Fatalf("expected ErrEntryNotFound, got %v", err)
 	}
 }
@@ -3,6 +3,7 @@ package domain
 
 import (
 	"context"
+	"sync"
 	"time"
 )
 
@@ -11,7 +12,55 @@ type User struct {
 	ID           int64
 	Username     string
 	PasswordHash string
-	CreatedAt    time.Time
+	// Timezone is the IANA zone name used to decide which local day an entry
+	// belongs to. Empty means the server's local zone.
+	Timezone string
+	// WaterGoalLiters is the daily water intake goal. Zero means
+	// DefaultWaterGoalLiters.
+	WaterGoalLiters float64
+	// DailyWeightPolicy selects the weight shown for a day with several
+	// weigh-ins. Empty means DefaultWeightPolicy.
+	DailyWeightPolicy WeightPolicy
+	CreatedAt         time.Time
+}
+
+// locations caches the time zones loaded by User.Location by name.
+var locations sync.Map
+
+// Location returns the user's time zone, or the server's local zone when none
+// is set. A stored name that cannot be loaded falls back to UTC. Each zone is
+// loaded once and then shared between calls.
+func (u *User) Location() *time.Location {
+	if u == nil || u.Timezone == "" {
+		return time.Local
+	}
+	if loc, ok := locations.Load(u.Timezone); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	locations.Store(u.Timezone, loc)
+	return loc
+}
+
+// WaterGoal returns the user's daily water goal in liters, falling back to
+// DefaultWaterGoalLiters when none is set.
+func (u *User) WaterGoal() float64 {
+	if u == nil || u.WaterGoalLiters <= 0 {
+		return DefaultWaterGoalLiters
+	}
+	return u.WaterGoalLiters
+}
+
+// WeightPolicy returns the user's daily weight policy, falling back to
+// DefaultWeightPolicy when none is set.
+func (u *User) WeightPolicy() WeightPolicy {
+	if u == nil || u.DailyWeightPolicy == "" {
+		return DefaultWeightPolicy
+	}
+	return u.DailyWeightPolicy
 }
 
 // Session represents an active user session.
@@ -24,12 +73,26 @@ type Session struct {
 	CreatedAt time.Time
 }
 
+// ProfileUpdate holds the preferences to change on a user. Nil fields are
+// left unchanged.
+type ProfileUpdate struct {
+	Timezone *string `json:"timezone"`
+	// WaterGoalLiters of zero resets the goal to DefaultWaterGoalLiters.
+	WaterGoalLiters *float64 `json:"waterGoalLiters"`
+	// WeightPolicy of "" resets the policy to DefaultWeightPolicy.
+	WeightPolicy *WeightPolicy `json:"weightPolicy"`
+}
+
 // UserRepository defines the port for user persistence operations.
 type UserRepository interface {
 	GetByUsername(ctx context.Context, username string) (*User, error)
 	GetByID(ctx context.Context, id int64) (*User, error)
 	Create(ctx context.Context, username, passwordHash string) (*User, error)
 	Count(ctx context.Context) (int, error)
+	// UpdateProfile applies the set fields of p to the user's preferences in
+	// a single update and returns the updated user, or nil if there is no
+	// user with the ID.
+	UpdateProfile(ctx context.Context, id int64, p ProfileUpdate) (*User, error)
 }
 
 // SessionRepository defines the port for session persistence operations.
@@ -0,0 +1,38 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+func TestUserLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		user *domain.User
+		want string
+	}{
+		{"nil user", nil, time.Local.String()},
+		{"unset", &domain.User{}, time.Local.String()},
+		{"valid zone", &domain.User{Timezone: "America/New_York"}, "America/New_York"},
+		{"unknown zone", &domain.User{Timezone: "Mars/Olympus"}, "UTC"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.user.Location().String(); got != tc.want {
+				t.Errorf("Location() = %q; want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestUserLocation_Cached(t *testing.T) {
+	first := (&domain.User{Timezone: "Europe/Berlin"}).Location()
+	if again := (&domain.User{Timezone: "Europe/Berlin"}).Location(); again != first {
+		t.Error("expected the loaded zone to be reused")
+	}
+	if bad := (&domain.User{Timezone: "Mars/Olympus"}).Location(); bad != time.UTC {
+		t.Errorf("expected UTC for an unknown zone on every call, got %v", bad)
+	}
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// BloodPressureReading represents a single blood pressure measurement in mmHg.
+type BloodPressureReading struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"userId"`
+	Day       string    `json:"day"`
+	Systolic  int       `json:"systolic"`
+	Diastolic int       `json:"diastolic"`
+	Pulse     *int      `json:"pulse"`
+	Arm       string    `json:"arm,omitempty"`
+	Position  string    `json:"position,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Category is derived from Systolic and Diastolic; it is not stored.
+	Category BloodPressureCategory `json:"category"`
+}
+
+// BloodPressureAverage summarises the readings taken on one local day.
+type BloodPressureAverage struct {
+	Systolic  float64  `json:"systolic"`
+	Diastolic float64  `json:"diastolic"`
+	Pulse     *float64 `json:"pulse"`
+	Readings  int      `json:"readings"`
+}
+
+// BloodPressureCategory is an American Heart Association blood pressure category.
+type BloodPressureCategory string
+
+// Blood pressure categories, from lowest to highest.
+const (
+	BPNormal   BloodPressureCategory = "normal"
+	BPElevated BloodPressureCategory = "elevated"
+	BPStage1   BloodPressureCategory = "hypertension_stage_1"
+	BPStage2   BloodPressureCategory = "hypertension_stage_2"
+	BPCrisis   BloodPressureCategory = "hypertensive_crisis"
+)
+
+// ClassifyBloodPressure returns the AHA category for a reading in mmHg. When
+// the systolic and diastolic values fall into different categories the higher
+// one applies.
+func ClassifyBloodPressure(systolic, diastolic float64) BloodPressureCategory {
+	switch {
+	case systolic > 180 || diastolic > 120:
+		return BPCrisis
+	case systolic >= 140 || diastolic >= 90:
+		return BPStage2
+	case systolic >= 130 || diastolic >= 80:
+		return BPStage1
+	case systolic >= 120:
+		return BPElevated
+	default:
+		return BPNormal
+	}
+}
+
+// BloodPressureRepository is the port for blood pressure persistence. Local
+// days are interpreted in the supplied location.
+type BloodPressureRepository interface {
+	// AddBloodPressureReading stores r for the user and returns its ID. The ID,
+	// UserID, Day and Category fields of r are ignored.
+	AddBloodPressureReading(ctx context.Context, userID int64, r BloodPressureReading) (int64, error)
+	// GetBloodPressureReading returns the user's reading with the given ID, or
+	// nil if it does not exist or belongs to another user.
+	GetBloodPressureReading(ctx context.Context, userID int64, id int64, loc *time.Location) (*BloodPressureReading, error)
+	// UpdateBloodPressureReading replaces the stored fields of the user's
+	// reading with those of r, reporting whether it was found.
+	UpdateBloodPressureReading(ctx context.Context, userID int64, id int64, r BloodPressureReading) (bool, error)
+	// DeleteBloodPressureReading removes the user's reading, reporting whether
+	// it was found.
+	DeleteBloodPressureReading(ctx context.Context, userID int64, id int64) (bool, error)
+	// ListBloodPressureReadings returns the user's readings matching q, newest first.
+	ListBloodPressureReadings(ctx context.Context, userID int64, q EventQuery, loc *time.Location) ([]BloodPressureReading, error)
+	// BloodPressureAveragesForLocalDays returns the mean reading per local day
+	// for the inclusive range [fromDay, toDay], keyed by day. Days without
+	// readings are omitted.
+	BloodPressureAveragesForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]BloodPressureAverage, error)
+}
@@ -0,0 +1,30 @@
+package domain_test
+
+import (
+	"testing"
+
+	"vitals/internal/domain"
+)
+
+func TestClassifyBloodPressure(t *testing.T) {
+	tests := []struct {
+		sys, dia float64
+		want     domain.BloodPressureCategory
+	}{
+		{115, 75, domain.BPNormal},
+		{120, 79, domain.BPElevated},
+		{129, 70, domain.BPElevated},
+		{130, 70, domain.BPStage1},
+		{118, 82, domain.BPStage1},
+		{140, 85, domain.BPStage2},
+		{125, 90, domain.BPStage2},
+		{181, 100, domain.BPCrisis},
+		{150, 121, domain.BPCrisis},
+		{180, 120, domain.BPStage2},
+	}
+	for _, tc := range tests {
+		if got := domain.ClassifyBloodPressure(tc.sys, tc.dia); got != tc.want {
+			t.Errorf("ClassifyBloodPressure(%v, %v) = %q, want %q", tc.sys, tc.dia, got, tc.want)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package domain
 
-const kgToLb = 2.2046226218
+const (
+	kgToLb    = 2.2046226218
+	cmPerInch = 2.54
+)
 
 // ConvertWeight converts a weight value between "kg" and "lb".
 // Returns v unchanged if from == to or if the units are unrecognised.
@@ -16,3 +19,18 @@ func ConvertWeight(v float64, from, to string) float64 {
 	}
 	return v
 }
+
+// ConvertLength converts a length value between "cm" and "in".
+// Returns v unchanged if from == to or if the units are unrecognised.
+func ConvertLength(v float64, from, to string) float64 {
+	if from == to {
+		return v
+	}
+	if from == "cm" && to == "in" {
+		return v / cmPerInch
+	}
+	if from == "in" && to == "cm" {
+		return v * cmPerInch
+	}
+	return v
+}
@@ -35,3 +35,48 @@ func TestConvertWeight(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{"cm to in", 2.54, "cm", "in", 1},
+		{"in to cm", 32, "in", "cm", 81.28},
+		{"same unit", 80, "cm", "cm", 80},
+		{"unknown units", 50, "ft", "cm", 50},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := domain.ConvertLength(tc.value, tc.from, tc.to)
+			if !almostEqual(got, tc.want, 1e-9) {
+				t.Errorf("ConvertLength(%v, %q, %q) = %v, want %v", tc.value, tc.from, tc.to, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBodyComposition(t *testing.T) {
+	fat, bone := 20.0, 3.0
+	c := domain.BodyComposition{BodyFatPercent: &fat, BoneMass: &bone}
+
+	if got := c.FatMass(80); got == nil || *got != 16 {
+		t.Errorf("FatMass(80) = %v, want 16", got)
+	}
+	if got := c.LeanMass(80); got == nil || *got != 64 {
+		t.Errorf("LeanMass(80) = %v, want 64", got)
+	}
+	if got := (domain.BodyComposition{}).LeanMass(80); got != nil {
+		t.Errorf("expected nil lean mass without body fat, got %v", *got)
+	}
+
+	lb := c.Convert("kg", "lb")
+	if !almostEqual(*lb.BoneMass, 6.6138678654, 1e-6) {
+		t.Errorf("expected bone mass in lb, got %v", *lb.BoneMass)
+	}
+	if *lb.BodyFatPercent != 20 || bone != 3 {
+		t.Error("Convert must only change masses and must not modify the receiver")
+	}
+}
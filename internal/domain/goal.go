@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// WeightGoal is a user's target weight, optionally to be reached by a date.
+type WeightGoal struct {
+	UserID      int64   `json:"userId"`
+	TargetValue float64 `json:"targetValue"`
+	Unit        string  `json:"unit"`
+	// TargetDate is a local day ("2006-01-02"), or empty if the goal has no
+	// deadline.
+	TargetDate string `json:"targetDate,omitempty"`
+	// StartValue is the latest weigh-in, in Unit, when the goal was set, or
+	// nil if there was none. It tells whether the goal is to lose or gain.
+	StartValue *float64  `json:"startValue,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Weight goal directions.
+const (
+	GoalLose     = "lose"
+	GoalGain     = "gain"
+	GoalMaintain = "maintain"
+)
+
+// Direction returns whether reaching the target from start, in the goal's
+// unit, means losing or gaining weight, or maintaining it if start is within
+// tolerance of the target.
+func (g WeightGoal) Direction(start, tolerance float64) string {
+	switch {
+	case math.Abs(start-g.TargetValue) <= tolerance:
+		return GoalMaintain
+	case start > g.TargetValue:
+		return GoalLose
+	default:
+		return GoalGain
+	}
+}
+
+// Target returns the target weight converted to unit.
+func (g WeightGoal) Target(unit string) float64 {
+	return ConvertWeight(g.TargetValue, g.Unit, unit)
+}
+
+// WeightGoalRepository is the port for weight goal persistence. A user has at
+// most one weight goal.
+type WeightGoalRepository interface {
+	// GetWeightGoal returns the user's goal, or nil if none is set.
+	GetWeightGoal(ctx context.Context, userID int64) (*WeightGoal, error)
+	// SetWeightGoal stores g as the user's goal, replacing any existing one.
+	// The UserID field of g is ignored.
+	SetWeightGoal(ctx context.Context, userID int64, g WeightGoal) error
+	// DeleteWeightGoal removes the user's goal, reporting whether one was set.
+	DeleteWeightGoal(ctx context.Context, userID int64) (bool, error)
+}
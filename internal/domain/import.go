@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+// ImportRepository is the port for storing events imported in bulk. The ID,
+// UserID and Day fields of the given events are ignored.
+type ImportRepository interface {
+	// ImportEvents adds the weight and water events to the user's history
+	// atomically: either all of them are stored or none is.
+	ImportEvents(ctx context.Context, userID int64, weights []WeightEntry, water []WaterEvent) error
+	// RestoreAccount atomically replaces all of the user's weight and water
+	// events with the given ones and applies p to the user's preferences. It
+	// reports whether the preferences were applied, which they are not if
+	// there is no stored user with the ID.
+	RestoreAccount(ctx context.Context, userID int64, weights []WeightEntry, water []WaterEvent, p ProfileUpdate) (bool, error)
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// MeasurementSites lists the body sites that can be measured, in display order.
+var MeasurementSites = []string{"waist", "hips", "chest", "neck", "arm", "thigh"}
+
+// IsMeasurementSite reports whether site is one of MeasurementSites.
+func IsMeasurementSite(site string) bool {
+	for _, s := range MeasurementSites {
+		if s == site {
+			return true
+		}
+	}
+	return false
+}
+
+// Measurement represents a single tape measurement of a body site.
+type Measurement struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"userId"`
+	Day       string    `json:"day"`
+	Site      string    `json:"site"`
+	Value     float64   `json:"value"`
+	Unit      string    `json:"unit"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// MeasurementRepository is the port for body measurement persistence. Local
+// days are interpreted in the supplied location.
+type MeasurementRepository interface {
+	AddMeasurement(ctx context.Context, userID int64, site string, value float64, unit string, createdAt time.Time) (int64, error)
+	// GetMeasurement returns the user's measurement with the given ID, or nil
+	// if it does not exist or belongs to another user.
+	GetMeasurement(ctx context.Context, userID int64, id int64, loc *time.Location) (*Measurement, error)
+	// UpdateMeasurement replaces the site, value, unit and timestamp of the
+	// user's measurement, reporting whether it was found.
+	UpdateMeasurement(ctx context.Context, userID int64, id int64, site string, value float64, unit string, createdAt time.Time) (bool, error)
+	// DeleteMeasurement removes the user's measurement, reporting whether it
+	// was found.
+	DeleteMeasurement(ctx context.Context, userID int64, id int64) (bool, error)
+	// ListMeasurements returns the user's measurements matching q, newest
+	// first, restricted to site unless it is empty.
+	ListMeasurements(ctx context.Context, userID int64, site string, q EventQuery, loc *time.Location) ([]Measurement, error)
+	// LatestMeasurementsForLocalDays returns the latest measurement per site
+	// and local day for the inclusive range [fromDay, toDay], keyed by day and
+	// then site. Days without measurements are omitted.
+	LatestMeasurementsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]map[string]Measurement, error)
+}
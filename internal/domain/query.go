@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// MaxDayRange is the longest inclusive range of local days a repository must
+// accept in a single per-day query such as WaterTotalsForLocalDays: about
+// twenty years of chart data plus a year for widening it to whole periods.
+const MaxDayRange = 21 * 366
+
+// EventQuery selects a page of events ordered newest first by their event
+// time, which is CreatedAt unless the event type documents otherwise.
+type EventQuery struct {
+	// From and To bound the event time to [From, To), or [From, To] if
+	// ToInclusive is set. Zero values are unbounded.
+	From        time.Time
+	To          time.Time
+	ToInclusive bool
+	// After continues a previous listing strictly after the given position.
+	After *EventCursor
+	// Limit caps the number of events returned.
+	Limit int
+}
+
+// EventCursor identifies a position in a newest-first event listing. Events
+// are ordered by event time, then ID, both descending; CreatedAt holds the
+// event time.
+type EventCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// SleepSession represents one period of sleep. A session belongs to the local
+// day on which it ended, so a night spanning midnight counts towards the
+// morning the user woke up.
+type SleepSession struct {
+	ID            int64     `json:"id"`
+	UserID        int64     `json:"userId"`
+	Day           string    `json:"day"`
+	Bedtime       time.Time `json:"bedtime"`
+	WakeTime      time.Time `json:"wakeTime"`
+	Quality       *int      `json:"quality"`
+	Interruptions int       `json:"interruptions"`
+	// DurationMinutes is derived from Bedtime and WakeTime; it is not stored.
+	DurationMinutes float64 `json:"durationMinutes"`
+}
+
+// Duration returns the time between going to bed and waking up.
+func (s SleepSession) Duration() time.Duration {
+	return s.WakeTime.Sub(s.Bedtime)
+}
+
+// WakeDay returns the local day in loc to which the session is attributed.
+func (s SleepSession) WakeDay(loc *time.Location) string {
+	return s.WakeTime.In(loc).Format("2006-01-02")
+}
+
+// SleepRepository is the port for sleep persistence. Sessions are ordered and
+// filtered by wake time, and local days are interpreted in the supplied
+// location.
+type SleepRepository interface {
+	// AddSleepSession stores s for the user and returns its ID. The ID, UserID,
+	// Day and DurationMinutes fields of s are ignored.
+	AddSleepSession(ctx context.Context, userID int64, s SleepSession) (int64, error)
+	// GetSleepSession returns the user's session with the given ID, or nil if
+	// it does not exist or belongs to another user.
+	GetSleepSession(ctx context.Context, userID int64, id int64, loc *time.Location) (*SleepSession, error)
+	// UpdateSleepSession replaces the stored fields of the user's session with
+	// those of s, reporting whether it was found.
+	UpdateSleepSession(ctx context.Context, userID int64, id int64, s SleepSession) (bool, error)
+	// DeleteSleepSession removes the user's session, reporting whether it was
+	// found.
+	DeleteSleepSession(ctx context.Context, userID int64, id int64) (bool, error)
+	// ListSleepSessions returns the user's sessions whose wake time matches q,
+	// newest first.
+	ListSleepSessions(ctx context.Context, userID int64, q EventQuery, loc *time.Location) ([]SleepSession, error)
+	// SleepMinutesForLocalDays returns the total minutes slept per wake day for
+	// the inclusive range [fromDay, toDay], keyed by day. Days without sessions
+	// are omitted.
+	SleepMinutesForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error)
+}
@@ -5,6 +5,9 @@ import (
 	"time"
 )
 
+// DefaultWaterGoalLiters is the daily water goal of users who have not set one.
+const DefaultWaterGoalLiters = 2.0
+
 // WaterEvent represents a single water intake/decrement event.
 type WaterEvent struct {
 	ID          int64     `json:"id"`
@@ -13,10 +16,32 @@ type WaterEvent struct {
 	CreatedAt   time.Time `json:"createdAt"`
 }
 
-// WaterRepository is the port for water persistence.
+// WeekdayHourTotals holds water intake in liters indexed by local day of the
+// week, as time.Weekday, and hour of the day.
+type WeekdayHourTotals [7][24]float64
+
+// WaterRepository is the port for water persistence. Local days are interpreted
+// in the supplied location.
 type WaterRepository interface {
 	AddWaterEvent(ctx context.Context, userID int64, deltaLiters float64, createdAt time.Time) (int64, error)
-	DeleteWaterEvent(ctx context.Context, userID int64, id int64) error
+	// DeleteWaterEvent removes the user's event, reporting whether it was found.
+	DeleteWaterEvent(ctx context.Context, userID int64, id int64) (bool, error)
+	// GetWaterEvent returns the user's event with the given ID, or nil if it
+	// does not exist or belongs to another user.
+	GetWaterEvent(ctx context.Context, userID int64, id int64) (*WaterEvent, error)
+	// UpdateWaterEvent replaces the delta and timestamp of the user's event,
+	// reporting whether it was found.
+	UpdateWaterEvent(ctx context.Context, userID int64, id int64, deltaLiters float64, createdAt time.Time) (bool, error)
+	// ListRecentWaterEvents returns up to limit of the user's most recently
+	// logged events, latest logged first, whatever their timestamps.
 	ListRecentWaterEvents(ctx context.Context, userID int64, limit int) ([]WaterEvent, error)
-	WaterTotalForLocalDay(ctx context.Context, userID int64, localDay string) (float64, error)
+	// ListWaterEvents returns the user's events matching q, newest first.
+	ListWaterEvents(ctx context.Context, userID int64, q EventQuery) ([]WaterEvent, error)
+	WaterTotalForLocalDay(ctx context.Context, userID int64, localDay string, loc *time.Location) (float64, error)
+	// WaterTotalsForLocalDays returns the total intake per local day for the
+	// inclusive range [fromDay, toDay]. Days without events are omitted.
+	WaterTotalsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (map[string]float64, error)
+	// WaterTotalsByWeekdayHour returns the total intake per local day of the
+	// week and hour of the day over the inclusive range [fromDay, toDay].
+	WaterTotalsByWeekdayHour(ctx context.Context, userID int64, fromDay, toDay string, loc *time.Location) (WeekdayHourTotals, error)
 }
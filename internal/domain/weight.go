@@ -5,20 +5,167 @@ import (
 	"time"
 )
 
-// WeightEntry represents a single weight measurement.
+// WeightEntry represents a single weight measurement, optionally with the
+// body composition reported by a smart scale.
 type WeightEntry struct {
-	ID        int64     `json:"id"`
-	UserID    int64     `json:"userId"`
-	Day       string    `json:"day"`
-	Value     float64   `json:"value"`
-	Unit      string    `json:"unit"`
+	ID     int64   `json:"id"`
+	UserID int64   `json:"userId"`
+	Day    string  `json:"day"`
+	Value  float64 `json:"value"`
+	Unit   string  `json:"unit"`
+	BodyComposition
 	CreatedAt time.Time `json:"createdAt"`
 }
 
-// WeightRepository is the port for weight persistence.
+// BodyComposition holds optional smart-scale metrics recorded with a weigh-in.
+// Masses are in the unit of the weight they accompany.
+type BodyComposition struct {
+	BodyFatPercent *float64 `json:"bodyFatPercent,omitempty"`
+	MuscleMass     *float64 `json:"muscleMass,omitempty"`
+	WaterPercent   *float64 `json:"waterPercent,omitempty"`
+	BoneMass       *float64 `json:"boneMass,omitempty"`
+	// VisceralFat is the scale's unitless visceral fat rating.
+	VisceralFat *float64 `json:"visceralFat,omitempty"`
+}
+
+// IsZero reports whether no composition metric is set.
+func (c BodyComposition) IsZero() bool {
+	return c.BodyFatPercent == nil && c.MuscleMass == nil && c.WaterPercent == nil &&
+		c.BoneMass == nil && c.VisceralFat == nil
+}
+
+// Convert returns a copy of c with its masses converted between weight units
+// as by ConvertWeight. Percentages and ratings are unchanged.
+func (c BodyComposition) Convert(from, to string) BodyComposition {
+	conv := func(v *float64) *float64 {
+		if v == nil {
+			return nil
+		}
+		out := ConvertWeight(*v, from, to)
+		return &out
+	}
+	c.MuscleMass = conv(c.MuscleMass)
+	c.BoneMass = conv(c.BoneMass)
+	return c
+}
+
+// FatMass returns the fat mass of a body weighing weight, in the same unit,
+// or nil if the body fat percentage is unknown.
+func (c BodyComposition) FatMass(weight float64) *float64 {
+	if c.BodyFatPercent == nil {
+		return nil
+	}
+	fat := weight * *c.BodyFatPercent / 100
+	return &fat
+}
+
+// LeanMass returns the fat-free mass of a body weighing weight, in the same
+// unit, or nil if the body fat percentage is unknown.
+func (c BodyComposition) LeanMass(weight float64) *float64 {
+	fat := c.FatMass(weight)
+	if fat == nil {
+		return nil
+	}
+	lean := weight - *fat
+	return &lean
+}
+
+// WeightPolicy selects the weight that represents a day with several
+// weigh-ins.
+type WeightPolicy string
+
+// Daily weight policies.
+const (
+	WeightPolicyLast  WeightPolicy = "last"
+	WeightPolicyFirst WeightPolicy = "first"
+	WeightPolicyMin   WeightPolicy = "min"
+	// WeightPolicyMean averages the day's weigh-ins in the unit of the latest
+	// one, whose ID, composition and time the resulting entry carries.
+	WeightPolicyMean WeightPolicy = "mean"
+)
+
+// DefaultWeightPolicy is the policy used unless a user or request selects
+// another.
+const DefaultWeightPolicy = WeightPolicyLast
+
+// Valid reports whether p is one of the daily weight policies.
+func (p WeightPolicy) Valid() bool {
+	switch p {
+	case WeightPolicyLast, WeightPolicyFirst, WeightPolicyMin, WeightPolicyMean:
+		return true
+	}
+	return false
+}
+
+// Reduce returns the entry representing entries, all from the same day,
+// under p. Ties between weigh-ins at the same time are broken by ID.
+func (p WeightPolicy) Reduce(entries []WeightEntry) WeightEntry {
+	later := func(a, b WeightEntry) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	}
+	latest := entries[0]
+	for _, e := range entries[1:] {
+		if later(e, latest) {
+			latest = e
+		}
+	}
+
+	switch p {
+	case WeightPolicyFirst:
+		first := entries[0]
+		for _, e := range entries[1:] {
+			if later(first, e) {
+				first = e
+			}
+		}
+		return first
+	case WeightPolicyMin:
+		lightest := latest
+		for _, e := range entries {
+			kg, minKg := ConvertWeight(e.Value, e.Unit, "kg"), ConvertWeight(lightest.Value, lightest.Unit, "kg")
+			if kg < minKg || (kg == minKg && later(e, lightest)) {
+				lightest = e
+			}
+		}
+		return lightest
+	case WeightPolicyMean:
+		var sum float64
+		for _, e := range entries {
+			sum += ConvertWeight(e.Value, e.Unit, latest.Unit)
+		}
+		latest.Value = sum / float64(len(entries))
+		return latest
+	default:
+		return latest
+	}
+}
+
+// WeightRepository is the port for weight persistence. Local days are interpreted
+// in the supplied location.
 type WeightRepository interface {
-	AddWeightEvent(ctx context.Context, userID int64, value float64, unit string, createdAt time.Time) (int64, error)
+	AddWeightEvent(ctx context.Context, userID int64, value float64, unit string, comp BodyComposition, createdAt time.Time) (int64, error)
+	// DeleteLatestWeightEvent removes the user's most recently logged event,
+	// whatever its timestamp, reporting whether there was one.
 	DeleteLatestWeightEvent(ctx context.Context, userID int64) (bool, error)
-	LatestWeightForLocalDay(ctx context.Context, userID int64, localDay string) (*WeightEntry, error)
-	ListRecentWeightEvents(ctx context.Context, userID int64, limit int) ([]WeightEntry, error)
+	// GetWeightEvent returns the user's event with the given ID, or nil if it
+	// does not exist or belongs to another user.
+	GetWeightEvent(ctx context.Context, userID int64, id int64, loc *time.Location) (*WeightEntry, error)
+	// UpdateWeightEvent replaces the value, unit, composition and timestamp of
+	// the user's event, reporting whether it was found.
+	UpdateWeightEvent(ctx context.Context, userID int64, id int64, value float64, unit string, comp BodyComposition, createdAt time.Time) (bool, error)
+	// DeleteWeightEvent removes the user's event, reporting whether it was found.
+	DeleteWeightEvent(ctx context.Context, userID int64, id int64) (bool, error)
+	// WeightForLocalDay returns the entry representing the local day under
+	// policy, or nil if the day has no entries.
+	WeightForLocalDay(ctx context.Context, userID int64, localDay string, policy WeightPolicy, loc *time.Location) (*WeightEntry, error)
+	ListRecentWeightEvents(ctx context.Context, userID int64, limit int, loc *time.Location) ([]WeightEntry, error)
+	// ListWeightEvents returns the user's events matching q, newest first.
+	ListWeightEvents(ctx context.Context, userID int64, q EventQuery, loc *time.Location) ([]WeightEntry, error)
+	// WeightsForLocalDays returns the entry representing each local day under
+	// policy for the inclusive range [fromDay, toDay], keyed by day. Days
+	// without entries are omitted.
+	WeightsForLocalDays(ctx context.Context, userID int64, fromDay, toDay string, policy WeightPolicy, loc *time.Location) (map[string]WeightEntry, error)
 }
@@ -0,0 +1,48 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"vitals/internal/domain"
+)
+
+func TestWeightPolicyReduce(t *testing.T) {
+	morning := time.Date(2026, 3, 1, 7, 0, 0, 0, time.UTC)
+	entries := []domain.WeightEntry{
+		{ID: 2, Value: 81, Unit: "kg", CreatedAt: morning.Add(12 * time.Hour)},
+		{ID: 1, Value: 80, Unit: "kg", CreatedAt: morning},
+		{ID: 3, Value: 176, Unit: "lb", CreatedAt: morning.Add(6 * time.Hour)},
+	}
+
+	tests := []struct {
+		policy domain.WeightPolicy
+		wantID int64
+		want   float64
+	}{
+		{domain.WeightPolicyLast, 2, 81},
+		{domain.WeightPolicyFirst, 1, 80},
+		{domain.WeightPolicyMin, 3, 176},
+		// 176 lb is about 79.83 kg.
+		{domain.WeightPolicyMean, 2, (81 + 80 + 79.8321) / 3},
+	}
+	for _, tc := range tests {
+		got := tc.policy.Reduce(entries)
+		if got.ID != tc.wantID || !almostEqual(got.Value, tc.want, 0.001) {
+			t.Errorf("%s: expected entry %d with %v, got %d with %v", tc.policy, tc.wantID, tc.want, got.ID, got.Value)
+		}
+	}
+}
+
+func TestWeightPolicyValid(t *testing.T) {
+	for _, p := range []domain.WeightPolicy{"last", "first", "min", "mean"} {
+		if !p.Valid() {
+			t.Errorf("expected %q to be valid", p)
+		}
+	}
+	for _, p := range []domain.WeightPolicy{"", "max", "Last"} {
+		if p.Valid() {
+			t.Errorf("expected %q to be invalid", p)
+		}
+	}
+}
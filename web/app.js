@@ -204,7 +204,19 @@ function toast(msg){
   toastTimer = setTimeout(()=>{statusEl.textContent='';}, 2000);
 }
 
+// Adopt the browser's time zone the first time so days roll over locally.
+async function syncTimezone(){
+  const tz = Intl.DateTimeFormat().resolvedOptions().timeZone;
+  const profile = await fetch('/api/profile').then(safeJson);
+  if(!tz || !profile || profile.timezone) return;
+  await fetch('/api/profile', {
+    method:'PUT',
+    headers:{'Content-Type':'application/json'},
+    body: JSON.stringify({timezone: tz})
+  });
+}
+
 setUnit(unit);
 initTheme();
 setAddDelta(addDelta);
-refresh();
+syncTimezone().finally(refresh);